@@ -1,7 +1,7 @@
 package core
 
 import (
-	"image/color"
+	"encoding/json"
 
 	"github.com/yohamta/donburi"
 )
@@ -21,6 +21,13 @@ type BuffType string
 type DebuffType string
 type PartParameter string
 type CustomizeCategory string
+type VictoryRuleType string
+type GameEndReason string
+type LegType string
+type SetSkillType string
+type BrokenPartPolicy string
+type BuffStackingRule string
+type BuffTargetSide string
 
 const (
 	CustomizeCategoryMedal CustomizeCategory = "Medal"
@@ -33,9 +40,14 @@ const (
 const (
 	Team1    TeamID = 0
 	Team2    TeamID = 1
+	Team3    TeamID = 2
+	Team4    TeamID = 3
 	TeamNone TeamID = -1
 )
 
+// MaxTeams は1回の戦闘に参加できるチーム数の上限です（バトルロイヤルでは参加機体数の上限にもなります）。
+const MaxTeams = 4
+
 const (
 	StateGaugeProgress      GameState = "GaugeProgress"
 	StatePlayerActionSelect GameState = "PlayerActionSelect"
@@ -44,6 +56,7 @@ const (
 	StatePostAction         GameState = "PostAction"
 	StateMessage            GameState = "Message"
 	StateGameOver           GameState = "GameOver"
+	StateChargeMenu         GameState = "ChargeMenu" // チャージ中の行動のキャンセル・ターゲット変更メニューを表示中（ゲージは停止）
 )
 
 const (
@@ -82,18 +95,109 @@ const (
 	TraitShoot    Trait = "撃つ"
 	TraitSupport  Trait = "支援"
 	TraitObstruct Trait = "妨害"
+	TraitRepair   Trait = "修復" // 味方のパーツの装甲を回復する
 	TraitNone     Trait = "NONE"
 )
 
+const (
+	WeaponTypeScan WeaponType = "スキャン"
+)
+
+// DamageModifierType はダメージ計算の修正パイプラインに並べる修正の種類です。
+type DamageModifierType string
+
+const (
+	DamageModifierCritIgnoreEvasion DamageModifierType = "crit_ignore_evasion" // クリティカル時に回避度を無効にする
+	DamageModifierCritIgnoreDefense DamageModifierType = "crit_ignore_defense" // クリティカル時に防御度を無効にする
+	DamageModifierIgnoreDefense     DamageModifierType = "ignore_defense"      // 防御度の一定割合を無視する
+	DamageModifierPierce            DamageModifierType = "pierce"              // 防御パーツの装甲を超えた分を、狙ったパーツに通す
+)
+
+// DamageModifierTypes は指定できるダメージ修正の種類の一覧です。
+var DamageModifierTypes = []DamageModifierType{DamageModifierCritIgnoreEvasion, DamageModifierCritIgnoreDefense, DamageModifierIgnoreDefense, DamageModifierPierce}
+
+// AttackPattern は1回の攻撃行動でどのようにヒットが発生するかを表します。
+type AttackPattern string
+
+const (
+	AttackPatternSingle   AttackPattern = "single"    // 1体の1パーツに1回ヒット
+	AttackPatternMultiHit AttackPattern = "multi_hit" // 同じ敵に複数回ヒットし、それぞれ命中判定を行う
+	AttackPatternSpread   AttackPattern = "spread"    // 1体の複数パーツにダメージを分散する
+	AttackPatternArea     AttackPattern = "area"      // ターゲットの周囲の敵も巻き込む
+)
+
+// AIDifficulty はAIの難易度です。難易度は性格とは独立に、性格の戦略が選んだ行動を補正します。
+type AIDifficulty string
+
+const (
+	AIDifficultyEasy   AIDifficulty = "easy"   // 一定の確率で最適でないパーツ・ターゲットを選び、防御をしない
+	AIDifficultyNormal AIDifficulty = "normal" // 性格の戦略どおりに行動する
+	AIDifficultyHard   AIDifficulty = "hard"   // 性格が選んだ行動を期待値で確かめ、敵リーダーへの集中を優先する
+)
+
+// AIDifficulties は選択できる難易度の一覧です。
+var AIDifficulties = []AIDifficulty{AIDifficultyEasy, AIDifficultyNormal, AIDifficultyHard}
+
+// TeamTactic はチームのAIに割り当てる戦術です。戦術に従ってチーム全体への指示（TeamIntentKind）が決まります。
+type TeamTactic string
+
+const (
+	TeamTacticNone          TeamTactic = "none"           // チームとしての指示を出さない
+	TeamTacticFocusFire     TeamTactic = "focus_fire"     // 頭部の装甲が最も少ない敵の頭部に集中攻撃する
+	TeamTacticProtectLeader TeamTactic = "protect_leader" // 自チームのリーダーを攻撃した敵を優先して狙う
+	TeamTacticBreakSupport  TeamTactic = "break_support"  // 敵の介入パーツを優先して破壊する
+	TeamTacticAdaptive      TeamTactic = "adaptive"       // 戦況に応じて上の指示を使い分ける
+)
+
+// TeamTactics は選択できる戦術の一覧です。
+var TeamTactics = []TeamTactic{TeamTacticNone, TeamTacticFocusFire, TeamTacticProtectLeader, TeamTacticBreakSupport, TeamTacticAdaptive}
+
+// TeamIntentKind はチームのAIが各機体に出す指示の種類です。
+type TeamIntentKind string
+
+const (
+	TeamIntentFocusTarget   TeamIntentKind = "focus_target"   // 指定した敵のパーツに集中攻撃する
+	TeamIntentProtectLeader TeamIntentKind = "protect_leader" // リーダーを攻撃した敵を狙う
+	TeamIntentBreakSupport  TeamIntentKind = "break_support"  // 敵の介入パーツを破壊する
+)
+
 const (
 	PolicyPreselected        TargetingPolicyType = "Preselected"
 	PolicyClosestAtExecution TargetingPolicyType = "ClosestAtExecution"
 )
 
+// BuffType はチーム全体にかかるバフ・デバフの種類です。乗数が 1.0 を超えればバフ、下回ればデバフとして働きます。
+const (
+	BuffTypeAccuracy      BuffType = "Accuracy"
+	BuffTypeDefense       BuffType = "Defense"
+	BuffTypeEvasion       BuffType = "Evasion"
+	BuffTypePower         BuffType = "Power"
+	BuffTypeChargeSpeed   BuffType = "ChargeSpeed"   // チャージ時間を乗数で割ります
+	BuffTypeCooldownSpeed BuffType = "CooldownSpeed" // クールダウン時間を乗数で割ります
+)
+
+// BuffTypes は指定できるチームバフ・デバフの種類の一覧です。
+var BuffTypes = []BuffType{BuffTypeAccuracy, BuffTypeDefense, BuffTypeEvasion, BuffTypePower, BuffTypeChargeSpeed, BuffTypeCooldownSpeed}
+
+// BuffStackingRule は同じ種類のチームバフが複数かかった場合の重ね方です。
+const (
+	BuffStackingMax     BuffStackingRule = "max"     // バフ・デバフそれぞれで最も効果の大きいものだけを適用
+	BuffStackingSum     BuffStackingRule = "sum"     // 1.0 からの増減をすべて合計して適用
+	BuffStackingReplace BuffStackingRule = "replace" // 新しくかけたもので既存のものを置き換える
+)
+
+// BuffStackingRules は指定できる重ね方の一覧です。
+var BuffStackingRules = []BuffStackingRule{BuffStackingMax, BuffStackingSum, BuffStackingReplace}
+
+// BuffTargetSide はチームバフをかける対象のチームです。
 const (
-	BuffTypeAccuracy BuffType = "Accuracy"
+	BuffTargetAlly  BuffTargetSide = "ally"  // 行動した機体のチーム
+	BuffTargetEnemy BuffTargetSide = "enemy" // ターゲットの機体のチーム
 )
 
+// BuffTargetSides は指定できる対象のチームの一覧です。
+var BuffTargetSides = []BuffTargetSide{BuffTargetAlly, BuffTargetEnemy}
+
 const (
 	DebuffTypeEvasion        DebuffType = "Evasion"
 	DebuffTypeDefense        DebuffType = "Defense"
@@ -111,7 +215,60 @@ const (
 	Defense    PartParameter = "Defense"
 )
 
-const PlayersPerTeam = 3
+// LegType は脚部パーツの移動タイプです。バトルフィールド上での移動の仕方に影響します。
+const (
+	LegTypeBipedal     LegType = "二脚"
+	LegTypeMultiLegged LegType = "多脚"
+	LegTypeWheeled     LegType = "車両"
+	LegTypeTank        LegType = "戦車"
+	LegTypeFlight      LegType = "飛行"
+	LegTypeHover       LegType = "浮遊"
+)
+
+// SetSkillType はパーツセットを揃えたときに攻撃に付与される固有スキルの種類です。
+const (
+	SetSkillNone    SetSkillType = ""
+	SetSkillThunder SetSkillType = "thunder" // 命中時にチャージを停止させる
+	SetSkillMelt    SetSkillType = "melt"    // 命中時に継続ダメージを与える
+	SetSkillVirus   SetSkillType = "virus"   // 命中時にターゲットをランダム化させる
+)
+
+// BrokenPartPolicy はチャージ中のパーツが破壊された場合の扱いです。
+const (
+	BrokenPartPolicyCancel   BrokenPartPolicy = "cancel"   // 行動を取り消し、クールダウンなしで待機状態に戻る
+	BrokenPartPolicyReselect BrokenPartPolicy = "reselect" // 残っているパーツから行動を選び直して実行する
+)
+
+// VictoryRuleType は戦闘で採用する勝利条件の種類です。
+const (
+	VictoryRuleLeaderKO     VictoryRuleType = "leader_ko"
+	VictoryRuleAnnihilation VictoryRuleType = "annihilation"
+	VictoryRuleTimeLimit    VictoryRuleType = "time_limit"
+	VictoryRulePartBreaks   VictoryRuleType = "part_breaks"
+	VictoryRuleBestOfRounds VictoryRuleType = "best_of_rounds"
+)
+
+// GameEndReason は試合（またはラウンド）終了の理由コードです。
+// UI側で "game_end_<reason>" のメッセージIDに変換してローカライズされます。
+const (
+	GameEndReasonNone         GameEndReason = ""
+	GameEndReasonLeaderKO     GameEndReason = "leader_ko"
+	GameEndReasonLeaderAbsent GameEndReason = "leader_absent"
+	GameEndReasonAnnihilation GameEndReason = "annihilation"
+	GameEndReasonTimeUp       GameEndReason = "time_up"
+	GameEndReasonTimeUpDraw   GameEndReason = "time_up_draw"
+	GameEndReasonPartBreaks   GameEndReason = "part_breaks"
+	GameEndReasonDraw         GameEndReason = "draw"
+	GameEndReasonRoundWon     GameEndReason = "round_won"
+	GameEndReasonRoundDraw    GameEndReason = "round_draw"
+	GameEndReasonMatchWon     GameEndReason = "match_won"
+)
+
+// チームあたりの機体数の上限・下限です。1対1から5対5、および1対3のような非対称戦をサポートします。
+const (
+	MinPlayersPerTeam = 1
+	MaxPlayersPerTeam = 5
+)
 
 // UI Constants
 const (
@@ -123,10 +280,127 @@ const (
 // --- Data Structures ---
 
 // GameEndResult はゲーム終了チェックの結果を保持します。
+// メッセージ文字列そのものは持たず、理由コードとパラメータからUI側で生成します。
 type GameEndResult struct {
+	IsGameOver  bool
+	IsRoundOver bool // ラウンド制で、試合は続行するがラウンドが終了した場合にtrue
+	Winner      TeamID
+	Reason      GameEndReason
+	Params      map[string]interface{} // メッセージのプレースホルダに埋め込む値
+}
+
+// TournamentRating はAIトーナメントの参加者1人の成績とレーティングです。
+type TournamentRating struct {
+	Entrant string
+	Rating  float64
+	Games   int
+	Wins    int
+	Draws   int
+	Losses  int
+}
+
+// TournamentHeadToHead は、Entrant から見た Opponent との対戦成績です。
+type TournamentHeadToHead struct {
+	Entrant  string
+	Opponent string
+	Wins     int
+	Draws    int
+	Losses   int
+}
+
+// TournamentResult はAIトーナメントの結果です。Ratings はレーティングの高い順に並びます。
+type TournamentResult struct {
+	Seed       int64
+	Ratings    []TournamentRating
+	HeadToHead []TournamentHeadToHead
+}
+
+// AgentMessageType はエージェント環境が外部のボットに送るメッセージの種類です。
+type AgentMessageType string
+
+const (
+	AgentMessageObservation AgentMessageType = "observation" // 行動の選択を求める観測
+	AgentMessageResult      AgentMessageType = "result"      // エピソードの結果
+	AgentMessageError       AgentMessageType = "error"       // 受け取った行動が不正だった場合の通知
+)
+
+// AgentMessage はエージェント環境が1行のJSONとして送るメッセージです。
+// Type に応じて Observation・Result・Error のいずれかが設定されます。
+type AgentMessage struct {
+	Type        AgentMessageType
+	Episode     int
+	Observation *AgentObservation
+	Result      *AgentEpisodeResult
+	Error       string
+}
+
+// AgentObservation は、エージェントが操作する機体が行動を選ぶ時点の戦況です。
+type AgentObservation struct {
+	Tick         int    // 戦闘開始からの経過ティック
+	ActingUnit   string // 行動を選ぶ機体のID
+	Units        []AgentUnit
+	LegalActions []AgentLegalAction
+}
+
+// AgentUnit は観測に含まれる1機の状態です。Team は 0 から始まるチーム番号です。
+type AgentUnit struct {
+	ID        string
+	Name      string
+	Team      TeamID
+	IsLeader  bool
+	State     StateType
+	Gauge     float64 // チャージ・クールダウンの進み具合（0〜100）
+	Scanned   bool    // スキャンのマークが付いているか
+	Parts     []AgentPart
+	Effects   []AgentEffect
+	TeamBuffs map[BuffType]float64 // この機体にかかっているチームバフ・デバフの乗数。1.0 のものは含みません
+}
+
+// AgentPart は観測に含まれる1パーツの状態です。
+type AgentPart struct {
+	Slot     PartSlotKey
+	PartID   string
+	PartName string
+	Category PartCategory
+	Trait    Trait
+	Power    int
+	Accuracy int
+	Charge   int
+	Cooldown int
+	Armor    int
+	MaxArmor int
+	IsBroken bool
+}
+
+// AgentEffect は機体にかかっている状態異常・デバフです。
+type AgentEffect struct {
+	Type           string
+	Value          float64 // 乗数や1回あたりのダメージなど、効果の量
+	RemainingTurns int
+}
+
+// AgentLegalAction はエージェントが選べる1つの行動です。Index を AgentAction で返して選びます。
+// TargetUnit が空の行動は、ターゲットが実行時に決まるか、ターゲットを持ちません。
+type AgentLegalAction struct {
+	Index          int
+	Slot           PartSlotKey
+	PartName       string
+	Category       PartCategory
+	Trait          Trait
+	TargetUnit     string
+	TargetPartSlot PartSlotKey
+}
+
+// AgentAction は外部のボットが観測への応答として送る行動です。
+type AgentAction struct {
+	Action int // 選んだ AgentLegalAction の Index
+}
+
+// AgentEpisodeResult はエピソードの結果です。上限ティック数までに決着がつかなかった場合、IsGameOver は false になります。
+type AgentEpisodeResult struct {
 	IsGameOver bool
 	Winner     TeamID
-	Message    string
+	Ticks      int
 }
 
 // AvailablePart now holds PartDefinition for AI/UI to see base stats.
@@ -142,7 +416,32 @@ type MessageTemplate struct {
 }
 
 type GameData struct {
+	Medarots     []MedarotData
+	StageID      string                // 次の戦闘で使用するステージ
+	AIDifficulty AIDifficulty          // 次の戦闘のAIの難易度
+	AutoBattle   bool                  // 次の戦闘をオートバトルで開始するか
+	TeamTactics  map[TeamID]TeamTactic // 次の戦闘のチームごとのAIの戦術
+}
+
+// TeamSetup は戦闘に参加する1チーム分の編成です。Medarots の並び順がそのまま表示順（DrawIndex）になります。
+type TeamSetup struct {
+	Team     TeamID
 	Medarots []MedarotData
+	Tactic   TeamTactic // チームのAIの戦術。空の場合は設定の既定の戦術を使います
+}
+
+// BattleSetup は1回の戦闘のチーム編成を宣言します。
+// 戦闘シーンはこの宣言に基づいてエンティティを生成し、GameData全体には依存しません。
+type BattleSetup struct {
+	Teams      []TeamSetup
+	PlayerTeam TeamID
+	FreeForAll bool   // バトルロイヤル（全機体がそれぞれ独立したチーム）として編成されているか
+	StageID    string // 戦闘を行うステージ。空の場合は地形補正なし
+
+	// AIDifficulty は戦闘全体のAIの難易度です。機体ごとの MedarotData.AIDifficulty が優先されます。
+	AIDifficulty AIDifficulty
+	// AutoBattle が true の場合、プレイヤーの機体もAIが操作した状態で戦闘を開始します。
+	AutoBattle bool
 }
 
 type MedarotData struct {
@@ -156,24 +455,121 @@ type MedarotData struct {
 	LeftArmID  string
 	LegsID     string
 	DrawIndex  int
+
+	// AIDifficulty はこの機体がAIの場合の難易度です。空の場合は戦闘全体の難易度を使います。
+	AIDifficulty AIDifficulty
+	// AutoBattlePersonality はプレイヤーの機体がオートバトルで使う性格です。空の場合はメダルの性格を使います。
+	AutoBattlePersonality string
+	// BehaviorTree はこの機体がAIの場合に行動を決めるビヘイビアツリーのIDです。空の場合はメダルの性格で行動します。
+	BehaviorTree string
 }
 
 type PartDefinition struct {
-	ID         string
-	PartName   string
-	Type       PartType
-	Category   PartCategory
-	Trait      Trait
-	MaxArmor   int
-	Power      int
-	Accuracy   int
-	Charge     int
-	Cooldown   int
-	Propulsion int
-	Mobility   int
-	Defense    int
-	Stability  int
-	WeaponType WeaponType
+	ID           string
+	PartName     string
+	Type         PartType
+	Category     PartCategory
+	Trait        Trait
+	MaxArmor     int
+	Power        int
+	Accuracy     int
+	Charge       int
+	Cooldown     int
+	Propulsion   int
+	Mobility     int
+	Defense      int
+	Stability    int
+	WeaponType   WeaponType
+	LegType      LegType // 脚部パーツのみ有効
+	SetID        string  // 所属するパーツセット（キット）のID。空の場合はセットに属さない
+	Weight       int     // パーツの重量
+	LoadCapacity int     // 積載量（脚部パーツのみ有効）
+}
+
+// SetBonusDefinition は、頭部・右腕・左腕・脚部の4パーツを同じセットで揃えたときのボーナスです。
+type SetBonusDefinition struct {
+	SetID             string
+	Name              string
+	Description       string
+	StatBonuses       map[PartParameter]float64 // 威力・命中は攻撃パーツに、機動・推進・安定・防御は脚部に加算
+	CriticalRateBonus float64                   // クリティカル率への加算（%）
+	UniqueSkill       SetSkillType              // 攻撃命中時に発動する固有スキル
+}
+
+// PersonalityDefinition はメダルの性格（AIの行動方針）の定義です。
+// 戦略やルールは名前で指定し、名前の解決と検証は戦闘システム側で行います。
+type PersonalityDefinition struct {
+	ID            string // 性格名（medals.csv の personality_jp に対応）
+	Description   string
+	Targeting     StrategyRef  // ターゲット選択戦略
+	PartSelection string       // パーツ選択戦略
+	PartToDamage  string       // 実行時に攻撃する敵パーツを決めるルール
+	Planner       *StrategyRef // パーツとターゲットをまとめて決める行動計画。省略した場合は戦略を組み合わせて決める
+	Weights       PersonalityWeights
+	Support       json.RawMessage // 介入パーツを使う戦況のしきい値。省略した項目は既定値を使う
+}
+
+// BehaviorNodeType はビヘイビアツリーのノードの種類です。
+type BehaviorNodeType string
+
+const (
+	BehaviorNodeSelector  BehaviorNodeType = "selector"  // 子を順に評価し、最初に成功した子で成功する
+	BehaviorNodeSequence  BehaviorNodeType = "sequence"  // 子を順に評価し、すべて成功すれば成功する
+	BehaviorNodeCondition BehaviorNodeType = "condition" // 戦況の条件を判定する
+	BehaviorNodeAction    BehaviorNodeType = "action"    // 行動を決める
+)
+
+// BehaviorTreeDefinition はAIの行動を決めるビヘイビアツリーの定義です。
+// 条件と行動は名前で指定し、名前の解決と検証は戦闘システム側で行います。
+type BehaviorTreeDefinition struct {
+	ID          string // medarots.csv の behavior_tree 列で指定するID
+	Description string
+	Root        BehaviorNodeDefinition
+}
+
+// BehaviorNodeDefinition はビヘイビアツリーの1つのノードの定義です。
+type BehaviorNodeDefinition struct {
+	Type     BehaviorNodeType
+	Name     string          // 条件・行動の名前（condition・action のみ）
+	Params   json.RawMessage // 条件・行動に渡すパラメータ。省略可
+	Not      bool            // 条件の判定を反転する（condition のみ）
+	Children []BehaviorNodeDefinition
+}
+
+// StrategyRef は名前で指定する戦略と、その戦略に渡すパラメータです。
+type StrategyRef struct {
+	Name   string
+	Params json.RawMessage // 戦略ごとのパラメータ。省略可
+}
+
+// PersonalityWeights は性格ごとの行動の傾向の重みです。
+type PersonalityWeights struct {
+	SupportUsage float64 // 介入パーツを使う意味がある場合に、選んだパーツの代わりに介入パーツを使う確率
+}
+
+// StageDefinition は戦闘ステージ（地形）の定義です。倍率が省略された場合は 1.0 として扱われます。
+type StageDefinition struct {
+	ID                   string
+	Name                 string
+	Background           string // 背景画像のパス。空の場合は既定の背景を使用
+	BackgroundTint       string // 背景に掛ける色（16進数 RRGGBB）。空の場合は補正なし
+	MobilityMultiplier   float64
+	PropulsionMultiplier float64
+	EvasionMultiplier    float64
+	LegTypeModifiers     map[LegType]StageLegModifier
+	WeaponModifiers      map[WeaponType]StageWeaponModifier
+}
+
+// StageLegModifier は特定の脚部タイプに対するステージの補正です。
+type StageLegModifier struct {
+	MobilityMultiplier   float64
+	PropulsionMultiplier float64
+}
+
+// StageWeaponModifier は特定の武器種に対するステージの補正です。
+type StageWeaponModifier struct {
+	PowerMultiplier    float64
+	AccuracyMultiplier float64
 }
 
 type PartInstanceData struct {
@@ -195,6 +591,14 @@ type GameStateData struct {
 	CurrentState GameState
 }
 
+// VictoryStateData は勝利条件の判定に必要な戦闘の進行状況を保持します。
+type VictoryStateData struct {
+	ElapsedTicks    int            // ゲージ進行状態で経過したティック数
+	PartBreakCounts map[TeamID]int // チームごとの敵パーツ破壊数
+	RoundWins       map[TeamID]int // ラウンド制でのチームごとの勝利数
+	CurrentRound    int
+}
+
 type ChargeStopEffectData struct {
 	DurationTurns int
 }
@@ -224,6 +628,20 @@ type Settings struct {
 	DrawIndex int
 }
 
+// Vector2 はバトルフィールド上の座標です（単位はフィールド単位）。
+type Vector2 struct {
+	X float64
+	Y float64
+}
+
+// Position は機体のバトルフィールド上の現在位置と、移動の基準となるホーム・前線の位置を保持します。
+type Position struct {
+	Current Vector2
+	Home    Vector2
+	FrontX  float64 // チャージ完了時に向かう実行ラインのX座標
+	LegType LegType
+}
+
 type PartsComponentData struct {
 	Map map[PartSlotKey]*PartInstanceData
 }
@@ -236,6 +654,8 @@ type Gauge struct {
 	ProgressCounter float64
 	TotalDuration   float64
 	CurrentGauge    float64
+	ReadyAt         float64 // チャージが完了した時刻（端数を含む経過ティック）。同時に準備完了した機体の行動順に使用します
+	TieBreaker      float64 // 行動順が他の条件で決まらない場合に使うシード付き乱数（コイントス）
 }
 
 type ActionIntent struct {
@@ -247,7 +667,11 @@ type Log struct {
 	LastActionLog string
 }
 
-type PlayerControl struct{}
+// PlayerControl はプレイヤーが操作する機体を表します。
+// オートバトル中はAIコンポーネントが追加され、AIが行動を選びます。
+type PlayerControl struct {
+	AutoBattlePersonality string // オートバトルで使う性格。空の場合はメダルの性格を使います
+}
 
 // ActiveStatusEffectData は、エンティティに現在適用されている効果のデータとその残り期間を追跡します。
 type ActiveStatusEffectData struct {
@@ -272,12 +696,22 @@ type DebuffEffect struct {
 	Multiplier float64
 }
 
+// TeamBuffEffect は支援・妨害などの行動がチーム全体にかけるバフ・デバフの定義です。
+// 乗数は 1.0 + PowerScale * 威力 / 100 で求めます（PowerScale が負ならデバフ）。
+type TeamBuffEffect struct {
+	Type          BuffType
+	Target        BuffTargetSide
+	PowerScale    float64
+	DurationTurns int // 効果が続く行動回数（戦闘中の全機体の行動数で数えます）。0 の場合は発生源のパーツが壊れるまで続きます
+}
+
 type ActionFormula struct {
 	ID                 string
 	SuccessRateBonuses []BonusTerm
 	PowerBonuses       []BonusTerm
 	CriticalRateBonus  float64
 	UserDebuffs        []DebuffEffect
+	TeamBuffs          []TeamBuffEffect
 }
 
 type ActionFormulaConfig struct {
@@ -285,6 +719,7 @@ type ActionFormulaConfig struct {
 	PowerBonuses       []BonusTerm
 	CriticalRateBonus  float64
 	UserDebuffs        []DebuffEffect
+	TeamBuffs          []TeamBuffEffect
 }
 
 // --- ViewModels ---
@@ -306,6 +741,21 @@ type ActionModalViewModel struct {
 	Buttons           []ActionModalButtonViewModel
 }
 
+// ChargeMenuTargetViewModel は、チャージ中の行動のターゲット変更先の候補1つ分のデータを保持します。
+type ChargeMenuTargetViewModel struct {
+	Name           string
+	TargetEntityID donburi.Entity
+	TargetPartSlot PartSlotKey
+}
+
+// ChargeMenuViewModel は、チャージ中の行動のキャンセル・ターゲット変更メニューの表示に必要なデータを保持します。
+type ChargeMenuViewModel struct {
+	ActingMedarotName string
+	ActingEntityID    donburi.Entity
+	PartName          string
+	Targets           []ChargeMenuTargetViewModel // ターゲットを変更できない行動の場合は空
+}
+
 // InfoPanelViewModel は、単一の情報パネルUIが必要とするすべてのデータを保持します。
 type InfoPanelViewModel struct {
 	ID        string         // 名前表示用としてstringに戻す
@@ -313,9 +763,16 @@ type InfoPanelViewModel struct {
 	Name      string
 	Team      TeamID
 	DrawIndex int
+	TeamSize  int
+	TeamIndex int
+	TeamCount int
 	StateStr  string
 	IsLeader  bool
 	Parts     map[PartSlotKey]PartViewModel
+
+	IsScanned        bool   // プレイヤーのチームがスキャン済みか
+	ChargingPartName string // スキャン済みの場合に表示する、チャージ中のパーツ名
+	ArmorHidden      bool   // 情報制限モードで、装甲値を伏せて表示するか
 }
 
 // PartViewModel は、単一のパーツUIが必要とするデータを保持します。
@@ -330,6 +787,8 @@ type PartViewModel struct {
 // BattlefieldViewModel は、バトルフィールド全体の描画に必要なデータを保持します。
 type BattlefieldViewModel struct {
 	Icons     []*IconViewModel
+	TeamSizes map[TeamID]int // チームごとの機体数（ホームマーカーや縦方向の間隔の計算に使用）
+	StageID   string         // 背景の切り替えに使用
 	DebugMode bool
 }
 
@@ -338,8 +797,12 @@ type IconViewModel struct {
 	EntryID            donburi.Entity // 元のdonburi.Entryを特定するためのID (uint32 から donburi.Entity に変更)
 	Team               TeamID
 	DrawIndex          int
+	TeamSize           int     // 所属チームの機体数
+	TeamIndex          int     // 戦闘に参加しているチームの中での並び順（レーンの決定に使用）
+	TeamCount          int     // 戦闘に参加しているチーム数
 	NormalizedProgress float64 // 0.0 to 1.0
-	Color              color.Color
+	Position           Vector2 // バトルフィールド上の現在位置（フィールド単位）
+	Home               Vector2 // ホーム位置（フィールド単位）
 	IsLeader           bool
 	State              StateType
 	GaugeProgress      float64 // 0.0 to 1.0
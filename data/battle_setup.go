@@ -0,0 +1,151 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+
+	"medarot-ebiten/core"
+)
+
+// NewBattleSetupFromGameData は、ロード済みのメダロット構成からチームごとの編成を組み立てます。
+// 各チームの機体は DrawIndex 順に並べられ、表示順は 0 から詰め直されます。
+func NewBattleSetupFromGameData(gameData *core.GameData, playerTeam core.TeamID) (*core.BattleSetup, error) {
+	teamMap := make(map[core.TeamID][]core.MedarotData)
+	for _, medarot := range gameData.Medarots {
+		teamMap[medarot.Team] = append(teamMap[medarot.Team], medarot)
+	}
+
+	setup := &core.BattleSetup{PlayerTeam: playerTeam, StageID: gameData.StageID, AIDifficulty: gameData.AIDifficulty, AutoBattle: gameData.AutoBattle}
+	for team, medarots := range teamMap {
+		sort.SliceStable(medarots, func(i, j int) bool { return medarots[i].DrawIndex < medarots[j].DrawIndex })
+		setup.Teams = append(setup.Teams, core.TeamSetup{Team: team, Medarots: medarots, Tactic: gameData.TeamTactics[team]})
+	}
+	sort.Slice(setup.Teams, func(i, j int) bool { return setup.Teams[i].Team < setup.Teams[j].Team })
+	NormalizeBattleSetup(setup)
+
+	if err := ValidateBattleSetup(setup); err != nil {
+		return nil, err
+	}
+	return setup, nil
+}
+
+// NewFreeForAllSetup は、チーム編成をバトルロイヤル（全機体がそれぞれ独立したチーム）に組み替えます。
+// 参加機体は各チームから順番に1機ずつ選ばれ（リーダーが優先されます）、上限の MaxTeams 機に達した時点で打ち切られます。
+// プレイヤーは元のプレイヤーチームから最初に選ばれた機体を操作します。
+func NewFreeForAllSetup(setup *core.BattleSetup) (*core.BattleSetup, error) {
+	ffa := &core.BattleSetup{PlayerTeam: core.TeamNone, FreeForAll: true, StageID: setup.StageID, AIDifficulty: setup.AIDifficulty, AutoBattle: setup.AutoBattle}
+
+	for round := 0; len(ffa.Teams) < core.MaxTeams; round++ {
+		picked := false
+		for _, teamSetup := range setup.Teams {
+			candidates := leaderFirst(teamSetup.Medarots)
+			if round >= len(candidates) || len(ffa.Teams) >= core.MaxTeams {
+				continue
+			}
+			picked = true
+			medarot := candidates[round]
+			medarot.IsLeader = true
+			newTeam := core.TeamID(len(ffa.Teams))
+			if teamSetup.Team == setup.PlayerTeam && ffa.PlayerTeam == core.TeamNone {
+				ffa.PlayerTeam = newTeam
+			}
+			ffa.Teams = append(ffa.Teams, core.TeamSetup{Team: newTeam, Medarots: []core.MedarotData{medarot}, Tactic: teamSetup.Tactic})
+		}
+		if !picked {
+			break
+		}
+	}
+	NormalizeBattleSetup(ffa)
+
+	if err := ValidateBattleSetup(ffa); err != nil {
+		return nil, err
+	}
+	return ffa, nil
+}
+
+// leaderFirst はリーダーを先頭に、残りを元の順序のまま並べた機体リストを返します。
+func leaderFirst(medarots []core.MedarotData) []core.MedarotData {
+	ordered := make([]core.MedarotData, 0, len(medarots))
+	for _, medarot := range medarots {
+		if medarot.IsLeader {
+			ordered = append(ordered, medarot)
+		}
+	}
+	for _, medarot := range medarots {
+		if !medarot.IsLeader {
+			ordered = append(ordered, medarot)
+		}
+	}
+	return ordered
+}
+
+// NormalizeBattleSetup は各チームの機体の DrawIndex と Team を編成の並び順に合わせて設定し直します。
+func NormalizeBattleSetup(setup *core.BattleSetup) {
+	for i := range setup.Teams {
+		teamSetup := &setup.Teams[i]
+		for j := range teamSetup.Medarots {
+			teamSetup.Medarots[j].Team = teamSetup.Team
+			teamSetup.Medarots[j].DrawIndex = j
+		}
+	}
+}
+
+// ValidateBattleSetup は編成が戦闘可能な形になっているかを検証します。
+// 2〜MaxTeamsチームが存在し、各チームの機体数が規定範囲内で、リーダーがちょうど1機であることを要求します。
+func ValidateBattleSetup(setup *core.BattleSetup) error {
+	if len(setup.Teams) < 2 || len(setup.Teams) > core.MaxTeams {
+		return fmt.Errorf("戦闘に参加できるチーム数は2〜%dです (現在: %dチーム)", core.MaxTeams, len(setup.Teams))
+	}
+
+	playerTeamFound := false
+	for _, teamSetup := range setup.Teams {
+		if teamSetup.Team == setup.PlayerTeam {
+			playerTeamFound = true
+		}
+		size := len(teamSetup.Medarots)
+		if size < core.MinPlayersPerTeam || size > core.MaxPlayersPerTeam {
+			return fmt.Errorf("チーム%dの機体数 %d が範囲外です (%d〜%d)", teamSetup.Team, size, core.MinPlayersPerTeam, core.MaxPlayersPerTeam)
+		}
+		leaderCount := 0
+		for _, medarot := range teamSetup.Medarots {
+			if medarot.IsLeader {
+				leaderCount++
+			}
+		}
+		if leaderCount != 1 {
+			return fmt.Errorf("チーム%dのリーダー数が %d です。リーダーはちょうど1機である必要があります", teamSetup.Team, leaderCount)
+		}
+	}
+	if !playerTeamFound {
+		return fmt.Errorf("プレイヤーチーム %d が編成に含まれていません", setup.PlayerTeam)
+	}
+	return nil
+}
+
+// ValidateBattleSetupLoads は、積載量の上限が有効な場合に、上限を超える構成の機体がいないかを検証します。
+// 上限が無効な場合、過積載はペナルティのみで許可されます。
+func ValidateBattleSetupLoads(setup *core.BattleSetup, gdm *GameDataManager, config *Config) error {
+	if !config.Load.HardCapEnabled {
+		return nil
+	}
+	for _, teamSetup := range setup.Teams {
+		for i := range teamSetup.Medarots {
+			medarot := &teamSetup.Medarots[i]
+			weight, capacity := gdm.CalculateLoad(medarot)
+			if ratio := LoadRatio(weight, capacity); ratio > config.Load.HardCapRatio {
+				return fmt.Errorf("%s の積載率 %.0f%% が上限 %.0f%% を超えています (重量 %d / 積載量 %d)",
+					medarot.Name, ratio*100, config.Load.HardCapRatio*100, weight, capacity)
+			}
+		}
+	}
+	return nil
+}
+
+// AllMedarots は編成に含まれる全機体を、チーム順・表示順に並べて返します。
+func AllMedarots(setup *core.BattleSetup) []core.MedarotData {
+	var medarots []core.MedarotData
+	for _, teamSetup := range setup.Teams {
+		medarots = append(medarots, teamSetup.Medarots...)
+	}
+	return medarots
+}
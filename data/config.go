@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"image/color"
 	"log"
+
+	"medarot-ebiten/core"
 )
 
 // Configは、ゲーム全体のコンフィグレーションを保持します。
@@ -64,6 +66,141 @@ type Config struct {
 		MaxChance  float64 `json:"MaxChance"`
 	} `json:"Defense"`
 
+	// Victory は戦闘の勝利条件の設定です。
+	Victory struct {
+		Rule             core.VictoryRuleType `json:"Rule"`
+		RoundRule        core.VictoryRuleType `json:"RoundRule"` // ラウンド制で各ラウンドに用いるルール
+		RoundsToWin      int                  `json:"RoundsToWin"`
+		TimeLimitSeconds float64              `json:"TimeLimitSeconds"`
+		PartBreaksToWin  int                  `json:"PartBreaksToWin"`
+	} `json:"Victory"`
+
+	// Spatial はバトルフィールド上の位置・距離に関する設定です。
+	// ホームと実行ラインの位置は UI.Battlefield の比率をフィールド幅に掛けて求めます。
+	Spatial struct {
+		FieldWidth               float64                            `json:"FieldWidth"`
+		FieldHeight              float64                            `json:"FieldHeight"`
+		MeleeRange               float64                            `json:"MeleeRange"`               // 格闘攻撃が届く最大距離。実行ラインまで前進した機体から、ホームにいる敵に届く距離（約55）を基準にします
+		RangedOptimalDistance    float64                            `json:"RangedOptimalDistance"`    // 射撃の命中率が下がり始める距離
+		RangedAccuracyFalloff    float64                            `json:"RangedAccuracyFalloff"`    // 最適距離を超えた1単位あたりの命中率低下
+		RangedMaxAccuracyPenalty float64                            `json:"RangedMaxAccuracyPenalty"` // 距離による命中率低下の上限
+		LegMovement              map[core.LegType]LegMovementConfig `json:"LegMovement"`
+	} `json:"Spatial"`
+
+	// Load はパーツ重量と脚部の積載量に関する設定です。
+	// 積載率（総重量 / 積載量）が 1.0 を超えた分を過積載として、各ペナルティ係数を掛けて適用します。
+	Load struct {
+		ChargePenaltyFactor   float64 `json:"ChargePenaltyFactor"`   // ゲージ時間の増加率
+		CooldownPenaltyFactor float64 `json:"CooldownPenaltyFactor"` // クールダウン時間の追加増加率
+		EvasionPenaltyFactor  float64 `json:"EvasionPenaltyFactor"`  // 回避度の低下率
+		HardCapEnabled        bool    `json:"HardCapEnabled"`        // 有効な場合、HardCapRatio を超える構成は戦闘に出せない
+		HardCapRatio          float64 `json:"HardCapRatio"`
+	} `json:"Load"`
+
+	// ActionInterruption はチャージ中にパーツやターゲットを失った場合の扱いに関する設定です。
+	ActionInterruption struct {
+		BrokenPartPolicy core.BrokenPartPolicy `json:"BrokenPartPolicy"`
+		RetargetRanged   bool                  `json:"RetargetRanged"` // 射撃のターゲットが機能停止した場合、最寄りの敵を狙い直す
+	} `json:"ActionInterruption"`
+
+	// TeamBuffs はチーム全体にかかるバフ・デバフの設定です。
+	TeamBuffs struct {
+		Stacking      map[core.BuffType]core.BuffStackingRule `json:"Stacking"`      // 種類ごとの重ね方。未設定の種類は max
+		MinMultiplier float64                                 `json:"MinMultiplier"` // デバフを重ねた場合の乗数の下限
+	} `json:"TeamBuffs"`
+
+	// ChargeControl はプレイヤーがチャージ中の行動をキャンセル・ターゲット変更する際のコストです。
+	ChargeControl struct {
+		CancelCooldownRatio  float64 `json:"CancelCooldownRatio"`  // キャンセル時、それまでのチャージ時間に対するクールダウン時間の比率
+		RetargetProgressLoss float64 `json:"RetargetProgressLoss"` // ターゲット変更時に失うチャージ進行度の割合
+	} `json:"ChargeControl"`
+
+	// Scan はスキャン（武器タイプ「スキャン」の介入行動）によるマークと情報制限モードの設定です。
+	Scan struct {
+		EvasionMultiplier float64 `json:"EvasionMultiplier"` // マークされた機体の回避度に掛ける倍率
+		DurationTurns     int     `json:"DurationTurns"`     // マークが続く行動回数。0 は無期限
+		FogOfInformation  bool    `json:"FogOfInformation"`  // 有効な場合、スキャンするまで敵の装甲値を伏せて表示する
+	} `json:"Scan"`
+
+	// AttackPatterns は攻撃パターン（連続攻撃・拡散・範囲）の設定です。
+	// パーツIDごとの設定は武器タイプごとの設定より優先されます。どちらにもない場合は単発攻撃になります。
+	AttackPatterns struct {
+		WeaponTypes map[core.WeaponType]AttackPatternConfig `json:"WeaponTypes"`
+		Parts       map[string]AttackPatternConfig          `json:"Parts"`
+	} `json:"AttackPatterns"`
+
+	// DamageModifiers は武器タイプごとのダメージ修正パイプラインの設定です。修正は並べた順に適用されます。
+	// 武器タイプの設定がない場合は Default を使います。
+	DamageModifiers struct {
+		Default     []DamageModifierConfig                     `json:"Default"`
+		WeaponTypes map[core.WeaponType][]DamageModifierConfig `json:"WeaponTypes"`
+	} `json:"DamageModifiers"`
+
+	// Combos は味方同士の連携攻撃（コンボ）の設定です。
+	// 味方が命中させたのと同じ機体・同じパーツを WindowTicks 以内に別の味方が攻撃し、
+	// 2つの行動の特性の組み合わせが Rules にあればコンボが発生します。
+	Combos struct {
+		WindowTicks int               `json:"WindowTicks"`
+		Rules       []ComboRuleConfig `json:"Rules"`
+	} `json:"Combos"`
+
+	// Lookahead は先読み（モンテカルロ木探索）AIの探索の設定です。
+	// 1回の行動選択につき、Iterations 回のシミュレーションか TimeBudgetMillis ミリ秒のどちらかに達するまで探索します。
+	// 各シミュレーションは HorizonTicks ティック先まで進め、行動する機体自身の選択を MaxDepth 手先まで木として展開します。
+	Lookahead struct {
+		Iterations       int     `json:"Iterations"`
+		TimeBudgetMillis int     `json:"TimeBudgetMillis"`
+		HorizonTicks     int     `json:"HorizonTicks"`
+		MaxDepth         int     `json:"MaxDepth"`
+		Exploration      float64 `json:"Exploration"` // UCB1の探索項の係数
+	} `json:"Lookahead"`
+
+	// AIDifficulty はAIの難易度ごとの設定です。Default は起動時に選択されている難易度です。
+	// Levels にない難易度は補正なし（normal と同じ）として扱います。
+	AIDifficulty struct {
+		Default core.AIDifficulty                        `json:"Default"`
+		Levels  map[core.AIDifficulty]AIDifficultyConfig `json:"Levels"`
+	} `json:"AIDifficulty"`
+
+	// TeamBrain はチームのAIの戦術の設定です。DefaultTactic は戦術が指定されていないチームに使います。
+	// 戦術が adaptive の場合、リーダーの頭部の装甲の割合が LeaderDangerRatio 以下になるとリーダーの保護を優先します。
+	TeamBrain struct {
+		DefaultTactic     core.TeamTactic `json:"DefaultTactic"`
+		LeaderDangerRatio float64         `json:"LeaderDangerRatio"`
+	} `json:"TeamBrain"`
+
+	// AutoBattle はプレイヤーの機体をAIが操作するオートバトルの設定です。
+	// SpeedMultipliers は切り替えられる戦闘の速度の倍率で、先頭が通常の速度です。
+	// オートバトル中のメッセージは MessageAdvanceTicks ティック表示した後に自動で送られます。
+	AutoBattle struct {
+		SpeedMultipliers    []int `json:"SpeedMultipliers"`
+		MessageAdvanceTicks int   `json:"MessageAdvanceTicks"`
+	} `json:"AutoBattle"`
+
+	// Tournament は、AIの性格と難易度の組み合わせを総当たりで戦わせるトーナメント（-tournament オプション）の設定です。
+	// 同じ Seed からは同じ結果が得られます。Entrants が空の場合は、定義されているすべての性格が normal で参加します。
+	Tournament struct {
+		Seed            int64                     `json:"Seed"`
+		GamesPerPairing int                       `json:"GamesPerPairing"` // 1組あたりの試合数。左右の陣営を入れ替えながら戦います
+		MaxTicks        int                       `json:"MaxTicks"`        // 1試合の上限ティック数。決着がつかなければ引き分け
+		InitialRating   float64                   `json:"InitialRating"`
+		KFactor         float64                   `json:"KFactor"`
+		OutputDir       string                    `json:"OutputDir"`
+		Entrants        []TournamentEntrantConfig `json:"Entrants"`
+	} `json:"Tournament"`
+
+	// Agent は、外部のボットが1体ずつ行動を選ぶエージェント環境（-agent オプション）の設定です。
+	// Address が空の場合は標準入出力で、そうでなければそのアドレスでTCP接続を1つ待ち受けて通信します。
+	Agent struct {
+		Seed     int64  `json:"Seed"`
+		Episodes int    `json:"Episodes"`
+		MaxTicks int    `json:"MaxTicks"` // 1エピソードの上限ティック数。決着がつかなければ引き分け
+		Address  string `json:"Address"`
+	} `json:"Agent"`
+
+	// DefaultStageID は起動時に選択されているステージのIDです。
+	DefaultStageID string `json:"DefaultStageID"`
+
 	// UI設定はUIConfig構造体にマッピングされます。
 	UI UIConfig `json:"UI"`
 
@@ -75,16 +212,65 @@ type Config struct {
 	// Formulas   map[core.Trait]core.ActionFormulaConfig
 }
 
+// AttackPatternConfig は攻撃パターンとそのパラメータを定義します。
+type AttackPatternConfig struct {
+	Pattern     core.AttackPattern `json:"Pattern"`
+	Hits        int                `json:"Hits"`        // multi_hit: ヒット数、spread: ダメージを分け合うパーツ数、area: 巻き込む機体の最大数（ターゲットを含む）
+	DamageScale float64            `json:"DamageScale"` // multi_hit: 1ヒットごとの倍率、spread: 分散前の合計ダメージの倍率、area: 巻き込んだ機体へのダメージ倍率
+	Radius      float64            `json:"Radius"`      // area: ターゲットからこの距離以内の敵を巻き込む
+}
+
+// TournamentEntrantConfig はAIトーナメントの参加者です。Name が空の場合は性格と難易度から名前を付けます。
+type TournamentEntrantConfig struct {
+	Name        string            `json:"Name"`
+	Personality string            `json:"Personality"`
+	Difficulty  core.AIDifficulty `json:"Difficulty"` // 空の場合は normal
+}
+
+// AIDifficultyConfig は1つの難易度でAIの行動に加える補正です。
+type AIDifficultyConfig struct {
+	MistakeRate        float64 `json:"MistakeRate"`        // 性格が選んだ行動の代わりに、ランダムなパーツ・ターゲットを選ぶ確率
+	IgnoreDefense      bool    `json:"IgnoreDefense"`      // 攻撃を受けたときにパーツで防御しない
+	ExpectedValueCheck bool    `json:"ExpectedValueCheck"` // 性格が選んだ行動を期待値で評価し、より良い行動があれば差し替える
+	SwitchThreshold    float64 `json:"SwitchThreshold"`    // 期待値がこの倍率以上になる行動があれば差し替える
+	LeaderFocusBonus   float64 `json:"LeaderFocusBonus"`   // 期待値の評価で、敵リーダーを狙う行動に掛ける倍率
+}
+
+// DamageModifierConfig はダメージ修正パイプラインの1段分の設定です。
+type DamageModifierConfig struct {
+	Type  core.DamageModifierType `json:"Type"`
+	Ratio float64                 `json:"Ratio"` // ignore_defense: 無視する防御度の割合、pierce: 装甲を超えた分のうち通す割合
+}
+
+// ComboRuleConfig は、先に命中した行動の特性と後から攻撃する行動の特性の組み合わせごとのコンボの効果です。
+type ComboRuleConfig struct {
+	FirstTrait       core.Trait `json:"FirstTrait"`
+	SecondTrait      core.Trait `json:"SecondTrait"`
+	DamageMultiplier float64    `json:"DamageMultiplier"` // 後の攻撃のダメージ倍率。0 の場合は 1.0 として扱う
+	GuaranteedHit    bool       `json:"GuaranteedHit"`    // 後の攻撃を必ず命中させるか
+	MessageID        string     `json:"MessageID"`        // コンボ発生時のメッセージ。未設定の場合は combo_triggered
+}
+
+// LegMovementConfig は脚部タイプごとの移動の仕方を定義します。
+type LegMovementConfig struct {
+	AdvanceRatio float64 `json:"AdvanceRatio"` // ホームから実行ラインまでのうち、チャージ中に前進する割合
+	SwayRatio    float64 `json:"SwayRatio"`    // 前進中に上下へ膨らむ幅（フィールド高さに対する割合）
+}
+
 // AssetPaths は各種アセットへのパスを保持します。
 type AssetPaths struct {
-	GameSettings string
-	Messages     string
-	MedalsCSV    string
-	PartsCSV     string
-	MedarotsCSV  string
-	FormulasJSON string
-	Font         string
-	Image        string
+	GameSettings      string
+	Messages          string
+	MedalsCSV         string
+	PartsCSV          string
+	MedarotsCSV       string
+	FormulasJSON      string
+	StagesJSON        string
+	SetBonusesJSON    string
+	PersonalitiesJSON string
+	BehaviorTreesJSON string
+	Font              string
+	Image             string
 }
 
 // GameConfig はゲームプレイ固有の設定を保持します。
@@ -145,6 +331,8 @@ type ParsedColors struct {
 	Gray       color.Color
 	Team1      color.Color
 	Team2      color.Color
+	Team3      color.Color
+	Team4      color.Color
 	Leader     color.Color
 	Broken     color.Color
 	HP         color.Color
@@ -167,6 +355,8 @@ func (p *ParsedColors) UnmarshalJSON(data []byte) error {
 		Gray       string `json:"Gray"`
 		Team1      string `json:"Team1"`
 		Team2      string `json:"Team2"`
+		Team3      string `json:"Team3"`
+		Team4      string `json:"Team4"`
 		Leader     string `json:"Leader"`
 		Broken     string `json:"Broken"`
 		HP         string `json:"HP"`
@@ -188,6 +378,8 @@ func (p *ParsedColors) UnmarshalJSON(data []byte) error {
 	p.Gray = parseHexColor(raw.Gray)
 	p.Team1 = parseHexColor(raw.Team1)
 	p.Team2 = parseHexColor(raw.Team2)
+	p.Team3 = parseHexColor(raw.Team3)
+	p.Team4 = parseHexColor(raw.Team4)
 	p.Leader = parseHexColor(raw.Leader)
 	p.Broken = parseHexColor(raw.Broken)
 	p.HP = parseHexColor(raw.HP)
@@ -198,6 +390,21 @@ func (p *ParsedColors) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// TeamColor は指定されたチームの表示色を返します。
+// 定義済みのチーム色を超えるチームIDの場合は、定義済みの色を順に使い回します。
+func (p *ParsedColors) TeamColor(team core.TeamID) color.Color {
+	teamColors := []color.Color{p.Team1, p.Team2, p.Team3, p.Team4}
+	if team < 0 {
+		return p.Gray
+	}
+	return teamColors[int(team)%len(teamColors)]
+}
+
+// ParseHexColor は16進数の色文字列（RRGGBB）を color.Color に変換します。ステージの背景色補正などで使用します。
+func ParseHexColor(s string) color.Color {
+	return parseHexColor(s)
+}
+
 // parseHexColor は16進数文字列からcolor.Colorをパースします。
 // UnmarshalJSONから利用されるため、このファイルに配置します。
 func parseHexColor(s string) color.Color {
@@ -30,14 +30,18 @@ func LoadInitialGameData() *InitialGameData {
 	// 1. アセットパスの定義
 	// この定義が前回の回答で欠落していました。
 	assetPaths := AssetPaths{
-		GameSettings: "assets/configs/game_settings.json",
-		Messages:     "assets/texts/messages.json",
-		MedalsCSV:    "assets/databases/medals.csv",
-		PartsCSV:     "assets/databases/parts.csv",
-		MedarotsCSV:  "assets/databases/medarots.csv",
-		FormulasJSON: "assets/configs/formulas.json",
-		Font:         "assets/fonts/MPLUS1p-Regular.ttf",
-		Image:        "assets/images/Gemini_Generated_Image_hojkprhojkprhojk.png",
+		GameSettings:      "assets/configs/game_settings.json",
+		Messages:          "assets/texts/messages.json",
+		MedalsCSV:         "assets/databases/medals.csv",
+		PartsCSV:          "assets/databases/parts.csv",
+		MedarotsCSV:       "assets/databases/medarots.csv",
+		FormulasJSON:      "assets/configs/formulas.json",
+		StagesJSON:        "assets/configs/stages.json",
+		SetBonusesJSON:    "assets/configs/set_bonuses.json",
+		PersonalitiesJSON: "assets/configs/personalities.json",
+		BehaviorTreesJSON: "assets/configs/behavior_trees.json",
+		Font:              "assets/fonts/MPLUS1p-Regular.ttf",
+		Image:             "assets/images/Gemini_Generated_Image_hojkprhojkprhojk.png",
 	}
 
 	// 2. game_settings.jsonの読み込み
@@ -50,6 +54,9 @@ func LoadInitialGameData() *InitialGameData {
 	if err := json.Unmarshal(jsonFile, &cfg); err != nil {
 		log.Fatalf("game_settings.json のアンマーシャルエラー: %v", err)
 	}
+	if err := ValidateConfig(&cfg); err != nil {
+		log.Fatalf("game_settings.json の設定が不正です: %v", err)
+	}
 	cfg.AssetPaths = assetPaths
 
 	// 3. リソースローダーの初期化
@@ -92,7 +99,10 @@ func LoadInitialGameData() *InitialGameData {
 		log.Fatalf("メダロットロードアウトの読み込みに失敗しました: %v", err)
 	}
 	gameData := &core.GameData{
-		Medarots: medarotLoadouts,
+		Medarots:     medarotLoadouts,
+		StageID:      cfg.DefaultStageID,
+		AIDifficulty: cfg.AIDifficulty.Default,
+		TeamTactics:  make(map[core.TeamID]core.TeamTactic),
 	}
 
 	// 9. すべての初期化済みデータを構造体にまとめて返す
@@ -105,4 +115,4 @@ func LoadInitialGameData() *InitialGameData {
 		MessageWindowFont: messageWindowFont,
 		Loader:            loader,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,85 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"medarot-ebiten/core"
+)
+
+// ValidateConfig は game_settings.json の設定のうち、名前で指定する項目が定義済みの値かを検証します。
+// 不正な項目はまとめてエラーとして返します。
+func ValidateConfig(cfg *Config) error {
+	var errs []error
+
+	if err := validateAIDifficulty(cfg.AIDifficulty.Default); err != nil {
+		errs = append(errs, fmt.Errorf("AIDifficulty.Default: %w", err))
+	}
+	for difficulty := range cfg.AIDifficulty.Levels {
+		if err := validateAIDifficulty(difficulty); err != nil {
+			errs = append(errs, fmt.Errorf("AIDifficulty.Levels: %w", err))
+		}
+	}
+	for i, entrant := range cfg.Tournament.Entrants {
+		if err := validateAIDifficulty(entrant.Difficulty); err != nil {
+			errs = append(errs, fmt.Errorf("Tournament.Entrants[%d].Difficulty: %w", i, err))
+		}
+	}
+
+	for buffType, rule := range cfg.TeamBuffs.Stacking {
+		if !slices.Contains(core.BuffTypes, buffType) {
+			errs = append(errs, fmt.Errorf("TeamBuffs.Stacking: バフの種類 '%s' は存在しません (%v)", buffType, core.BuffTypes))
+		}
+		if !slices.Contains(core.BuffStackingRules, rule) {
+			errs = append(errs, fmt.Errorf("TeamBuffs.Stacking.%s: 重ね方 '%s' は存在しません (%v)", buffType, rule, core.BuffStackingRules))
+		}
+	}
+
+	errs = append(errs, validateDamageModifiers("DamageModifiers.Default", cfg.DamageModifiers.Default)...)
+	for weaponType, modifiers := range cfg.DamageModifiers.WeaponTypes {
+		errs = append(errs, validateDamageModifiers(fmt.Sprintf("DamageModifiers.WeaponTypes.%s", weaponType), modifiers)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateDamageModifiers はダメージ修正パイプラインの各段の種類と割合を検証します。
+func validateDamageModifiers(path string, modifiers []DamageModifierConfig) []error {
+	var errs []error
+	for i, modifier := range modifiers {
+		if !slices.Contains(core.DamageModifierTypes, modifier.Type) {
+			errs = append(errs, fmt.Errorf("%s[%d]: ダメージ修正の種類 '%s' は存在しません (%v)", path, i, modifier.Type, core.DamageModifierTypes))
+		}
+		if modifier.Ratio < 0 || modifier.Ratio > 1 {
+			errs = append(errs, fmt.Errorf("%s[%d]: Ratio %.2f は 0〜1 の範囲で指定してください", path, i, modifier.Ratio))
+		}
+	}
+	return errs
+}
+
+// ValidateFormulas は formulas.json の計算式のうち、名前で指定する項目が定義済みの値かを検証します。
+// 不正な項目はまとめてエラーとして返します。
+func ValidateFormulas(formulas map[core.Trait]core.ActionFormulaConfig) error {
+	var errs []error
+	for trait, formula := range formulas {
+		for i, effect := range formula.TeamBuffs {
+			if !slices.Contains(core.BuffTypes, effect.Type) {
+				errs = append(errs, fmt.Errorf("%s.TeamBuffs[%d]: バフの種類 '%s' は存在しません (%v)", trait, i, effect.Type, core.BuffTypes))
+			}
+			// 対象の指定がない効果は味方にかかります。
+			if effect.Target != "" && !slices.Contains(core.BuffTargetSides, effect.Target) {
+				errs = append(errs, fmt.Errorf("%s.TeamBuffs[%d]: 対象 '%s' は存在しません (%v)", trait, i, effect.Target, core.BuffTargetSides))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateAIDifficulty は難易度が core.AIDifficulties のいずれかかを検証します。空の場合は normal として扱うため許可します。
+func validateAIDifficulty(difficulty core.AIDifficulty) error {
+	if difficulty == "" || slices.Contains(core.AIDifficulties, difficulty) {
+		return nil
+	}
+	return fmt.Errorf("難易度 '%s' は存在しません (%v)", difficulty, core.AIDifficulties)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 
 	"medarot-ebiten/core"
@@ -21,7 +22,7 @@ func SaveMedarotLoadouts(filePath string, medarots []core.MedarotData) error {
 	defer writer.Flush()
 
 	// ヘッダー行を書き込む
-	header := []string{"id", "name", "team", "is_leader", "draw_index", "medal_id", "head_id", "r_arm_id", "l_arm_id", "legs_id"}
+	header := []string{"id", "name", "team", "is_leader", "draw_index", "medal_id", "head_id", "r_arm_id", "l_arm_id", "legs_id", "ai_difficulty", "auto_battle_personality", "behavior_tree"}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("ヘッダーの書き込みに失敗しました: %w", err)
 	}
@@ -39,6 +40,9 @@ func SaveMedarotLoadouts(filePath string, medarots []core.MedarotData) error {
 			medarot.RightArmID,
 			medarot.LeftArmID,
 			medarot.LegsID,
+			string(medarot.AIDifficulty),
+			medarot.AutoBattlePersonality,
+			medarot.BehaviorTree,
 		}
 		if err := writer.Write(record); err != nil {
 			return fmt.Errorf("%s のレコード書き込みに失敗しました: %w", medarot.Name, err)
@@ -46,3 +50,67 @@ func SaveMedarotLoadouts(filePath string, medarots []core.MedarotData) error {
 	}
 	return nil
 }
+
+// SaveTournamentResults は、AIトーナメントの結果を dir にCSVファイルとして保存します。
+// ratings.csv にはレーティング順の成績を、head_to_head.csv には参加者ごとの対戦成績を書き込みます。
+func SaveTournamentResults(dir string, result *core.TournamentResult) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("出力先ディレクトリの作成に失敗しました: %w", err)
+	}
+
+	ratings := [][]string{{"rank", "entrant", "rating", "games", "wins", "draws", "losses", "win_rate", "seed"}}
+	for i, rating := range result.Ratings {
+		ratings = append(ratings, []string{
+			strconv.Itoa(i + 1),
+			rating.Entrant,
+			strconv.FormatFloat(rating.Rating, 'f', 1, 64),
+			strconv.Itoa(rating.Games),
+			strconv.Itoa(rating.Wins),
+			strconv.Itoa(rating.Draws),
+			strconv.Itoa(rating.Losses),
+			formatWinRate(rating.Wins, rating.Draws, rating.Games),
+			strconv.FormatInt(result.Seed, 10),
+		})
+	}
+	if err := writeCSVFile(filepath.Join(dir, "ratings.csv"), ratings); err != nil {
+		return err
+	}
+
+	headToHead := [][]string{{"entrant", "opponent", "games", "wins", "draws", "losses", "win_rate"}}
+	for _, record := range result.HeadToHead {
+		games := record.Wins + record.Draws + record.Losses
+		headToHead = append(headToHead, []string{
+			record.Entrant,
+			record.Opponent,
+			strconv.Itoa(games),
+			strconv.Itoa(record.Wins),
+			strconv.Itoa(record.Draws),
+			strconv.Itoa(record.Losses),
+			formatWinRate(record.Wins, record.Draws, games),
+		})
+	}
+	return writeCSVFile(filepath.Join(dir, "head_to_head.csv"), headToHead)
+}
+
+// formatWinRate は引き分けを0.5勝として数えた勝率を文字列で返します。
+func formatWinRate(wins, draws, games int) string {
+	if games == 0 {
+		return "0.000"
+	}
+	return strconv.FormatFloat((float64(wins)+float64(draws)*0.5)/float64(games), 'f', 3, 64)
+}
+
+// writeCSVFile は records をCSVファイルに書き込みます。
+func writeCSVFile(filePath string, records [][]string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("ファイルの作成に失敗しました: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("%s の書き込みに失敗しました: %w", filePath, err)
+	}
+	return nil
+}
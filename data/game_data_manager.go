@@ -6,6 +6,7 @@ import (
 	"medarot-ebiten/core"
 
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	resource "github.com/quasilyte/ebitengine-resource"
 )
 
 // GameDataManager はパーツやメダルなどのすべての静적ゲームデータ定義とメッセージを保持します。
@@ -15,6 +16,14 @@ type GameDataManager struct {
 	Messages         *MessageManager                   // メッセージマネージャー
 	Font             text.Face                         // UIで使用するフォント
 	Formulas         map[core.Trait]core.ActionFormula // 追加: アクション計算式
+	stageDefinitions map[string]*core.StageDefinition
+	stageOrder       []string                          // 定義ファイルでの並び順（選択UI用）
+	stageBackgrounds map[string]resource.ImageID       // ステージIDから背景画像リソースIDへの対応
+	setBonuses       map[string]*core.SetBonusDefinition
+	personalities    map[string]*core.PersonalityDefinition
+	personalityOrder []string // 定義ファイルでの並び順
+	behaviorTrees    map[string]*core.BehaviorTreeDefinition
+	behaviorTreeIDs  []string // 定義ファイルでの並び順
 	// 他のゲームデータ定義もここに追加できます
 }
 
@@ -27,6 +36,11 @@ func NewGameDataManager(font text.Face, messageManager *MessageManager) (*GameDa
 		Messages:         messageManager,                          // 渡されたメッセージマネージャーを使用
 		Font:             font,                                    // UIで使用するフォント
 		Formulas:         make(map[core.Trait]core.ActionFormula), // 初期化
+		stageDefinitions: make(map[string]*core.StageDefinition),
+		stageBackgrounds: make(map[string]resource.ImageID),
+		setBonuses:       make(map[string]*core.SetBonusDefinition),
+		personalities:    make(map[string]*core.PersonalityDefinition),
+		behaviorTrees:    make(map[string]*core.BehaviorTreeDefinition),
 	}
 	return gdm, nil
 }
@@ -86,4 +100,124 @@ func (gdm *GameDataManager) GetAllMedalDefinitions() []*core.Medal {
 	}
 	// UIで一貫した順序が必要な場合は、ここでソートを追加
 	return defs
-}
\ No newline at end of file
+}
+
+// AddStageDefinition はステージ定義をマネージャーに追加します。
+func (gdm *GameDataManager) AddStageDefinition(sd *core.StageDefinition) error {
+	if sd == nil {
+		return fmt.Errorf("nilのStageDefinitionを追加できません")
+	}
+	if _, exists := gdm.stageDefinitions[sd.ID]; exists {
+		return fmt.Errorf("ID %s のStageDefinitionは既に存在します", sd.ID)
+	}
+	gdm.stageDefinitions[sd.ID] = sd
+	gdm.stageOrder = append(gdm.stageOrder, sd.ID)
+	return nil
+}
+
+// GetStageDefinition はIDによってステージ定義を取得します。
+func (gdm *GameDataManager) GetStageDefinition(id string) (*core.StageDefinition, bool) {
+	sd, found := gdm.stageDefinitions[id]
+	return sd, found
+}
+
+// GetAllStageDefinitions はすべてのステージ定義を定義ファイルの順に返します。
+func (gdm *GameDataManager) GetAllStageDefinitions() []*core.StageDefinition {
+	defs := make([]*core.StageDefinition, 0, len(gdm.stageOrder))
+	for _, id := range gdm.stageOrder {
+		defs = append(defs, gdm.stageDefinitions[id])
+	}
+	return defs
+}
+
+// GetStageBackgroundID はステージ固有の背景画像のリソースIDを返します。
+// ステージが独自の背景を持たない場合は false を返します。
+func (gdm *GameDataManager) GetStageBackgroundID(stageID string) (resource.ImageID, bool) {
+	id, found := gdm.stageBackgrounds[stageID]
+	return id, found
+}
+
+// AddPersonalityDefinition は性格の定義をマネージャーに追加します。
+func (gdm *GameDataManager) AddPersonalityDefinition(pd *core.PersonalityDefinition) error {
+	if pd == nil {
+		return fmt.Errorf("nilのPersonalityDefinitionを追加できません")
+	}
+	if pd.ID == "" {
+		return fmt.Errorf("IDが空のPersonalityDefinitionは追加できません")
+	}
+	if _, exists := gdm.personalities[pd.ID]; exists {
+		return fmt.Errorf("ID %s のPersonalityDefinitionは既に存在します", pd.ID)
+	}
+	gdm.personalities[pd.ID] = pd
+	gdm.personalityOrder = append(gdm.personalityOrder, pd.ID)
+	return nil
+}
+
+// GetAllPersonalityDefinitions はすべての性格の定義を定義ファイルの順に返します。
+func (gdm *GameDataManager) GetAllPersonalityDefinitions() []*core.PersonalityDefinition {
+	defs := make([]*core.PersonalityDefinition, 0, len(gdm.personalityOrder))
+	for _, id := range gdm.personalityOrder {
+		defs = append(defs, gdm.personalities[id])
+	}
+	return defs
+}
+
+// AddBehaviorTreeDefinition はビヘイビアツリーの定義をマネージャーに追加します。
+func (gdm *GameDataManager) AddBehaviorTreeDefinition(bt *core.BehaviorTreeDefinition) error {
+	if bt == nil {
+		return fmt.Errorf("nilのBehaviorTreeDefinitionを追加できません")
+	}
+	if bt.ID == "" {
+		return fmt.Errorf("IDが空のBehaviorTreeDefinitionは追加できません")
+	}
+	if _, exists := gdm.behaviorTrees[bt.ID]; exists {
+		return fmt.Errorf("ID %s のBehaviorTreeDefinitionは既に存在します", bt.ID)
+	}
+	gdm.behaviorTrees[bt.ID] = bt
+	gdm.behaviorTreeIDs = append(gdm.behaviorTreeIDs, bt.ID)
+	return nil
+}
+
+// GetAllBehaviorTreeDefinitions はすべてのビヘイビアツリーの定義を定義ファイルの順に返します。
+func (gdm *GameDataManager) GetAllBehaviorTreeDefinitions() []*core.BehaviorTreeDefinition {
+	defs := make([]*core.BehaviorTreeDefinition, 0, len(gdm.behaviorTreeIDs))
+	for _, id := range gdm.behaviorTreeIDs {
+		defs = append(defs, gdm.behaviorTrees[id])
+	}
+	return defs
+}
+
+// AddSetBonusDefinition はパーツセットのボーナス定義をマネージャーに追加します。
+func (gdm *GameDataManager) AddSetBonusDefinition(sb *core.SetBonusDefinition) error {
+	if sb == nil {
+		return fmt.Errorf("nilのSetBonusDefinitionを追加できません")
+	}
+	if _, exists := gdm.setBonuses[sb.SetID]; exists {
+		return fmt.Errorf("ID %s のSetBonusDefinitionは既に存在します", sb.SetID)
+	}
+	gdm.setBonuses[sb.SetID] = sb
+	return nil
+}
+
+// GetSetBonusDefinition はセットIDによってボーナス定義を取得します。
+func (gdm *GameDataManager) GetSetBonusDefinition(setID string) (*core.SetBonusDefinition, bool) {
+	sb, found := gdm.setBonuses[setID]
+	return sb, found
+}
+
+// FindCompletedSetBonus は、機体の4パーツがすべて同じセットで揃っている場合にそのセットのボーナスを返します。
+func (gdm *GameDataManager) FindCompletedSetBonus(medarot *core.MedarotData) (*core.SetBonusDefinition, bool) {
+	setID := ""
+	for i, partID := range []string{medarot.HeadID, medarot.RightArmID, medarot.LeftArmID, medarot.LegsID} {
+		partDef, found := gdm.GetPartDefinition(partID)
+		if !found || partDef.SetID == "" {
+			return nil, false
+		}
+		if i == 0 {
+			setID = partDef.SetID
+		} else if partDef.SetID != setID {
+			return nil, false
+		}
+	}
+	return gdm.GetSetBonusDefinition(setID)
+}
@@ -0,0 +1,38 @@
+package data
+
+import (
+	"medarot-ebiten/core"
+)
+
+// CalculateLoad は機体構成の4パーツの総重量と、脚部パーツの積載量を返します。
+func (gdm *GameDataManager) CalculateLoad(medarot *core.MedarotData) (weight, capacity int) {
+	for _, partID := range []string{medarot.HeadID, medarot.RightArmID, medarot.LeftArmID, medarot.LegsID} {
+		if partDef, found := gdm.GetPartDefinition(partID); found {
+			weight += partDef.Weight
+		}
+	}
+	if legsDef, found := gdm.GetPartDefinition(medarot.LegsID); found {
+		capacity = legsDef.LoadCapacity
+	}
+	return weight, capacity
+}
+
+// LoadRatio は総重量と積載量から積載率を求めます。
+// 積載量が設定されていない脚部は、重量があれば積載率 1.0 として扱い、ペナルティを発生させません。
+func LoadRatio(weight, capacity int) float64 {
+	if capacity <= 0 {
+		if weight > 0 {
+			return 1.0
+		}
+		return 0
+	}
+	return float64(weight) / float64(capacity)
+}
+
+// OverloadRatio は積載率のうち 1.0 を超えた分（過積載の割合）を返します。
+func OverloadRatio(loadRatio float64) float64 {
+	if loadRatio <= 1.0 {
+		return 0
+	}
+	return loadRatio - 1.0
+}
@@ -15,6 +15,8 @@ const (
 const (
 	_ resource.ImageID = iota
 	ImageBattleBackground
+	// ImageStageBackgroundBase 以降のIDは、ステージ定義の読み込み時に各ステージの背景画像へ割り当てられます。
+	ImageStageBackgroundBase
 )
 
 const (
@@ -24,4 +26,8 @@ const (
 	RawMedarotsCSV
 	RawFormulasJSON
 	RawMessagesJSON
+	RawStagesJSON
+	RawSetBonusesJSON
+	RawPersonalitiesJSON
+	RawBehaviorTreesJSON
 )
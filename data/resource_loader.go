@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"os"
 
 	"medarot-ebiten/core"
@@ -41,11 +43,15 @@ func NewLoader(audioContext *audio.Context, assetPaths *AssetPaths) *resource.Lo
 
 	// Register raw resources (our CSV files).
 	rawResources := map[resource.RawID]resource.RawInfo{
-		RawMedalsCSV:    {Path: assetPaths.MedalsCSV},
-		RawPartsCSV:     {Path: assetPaths.PartsCSV},
-		RawMedarotsCSV:  {Path: assetPaths.MedarotsCSV},
-		RawFormulasJSON: {Path: assetPaths.FormulasJSON},
-		RawMessagesJSON: {Path: assetPaths.Messages}, // 追加
+		RawMedalsCSV:         {Path: assetPaths.MedalsCSV},
+		RawPartsCSV:          {Path: assetPaths.PartsCSV},
+		RawMedarotsCSV:       {Path: assetPaths.MedarotsCSV},
+		RawFormulasJSON:      {Path: assetPaths.FormulasJSON},
+		RawMessagesJSON:      {Path: assetPaths.Messages}, // 追加
+		RawStagesJSON:        {Path: assetPaths.StagesJSON},
+		RawSetBonusesJSON:    {Path: assetPaths.SetBonusesJSON},
+		RawPersonalitiesJSON: {Path: assetPaths.PersonalitiesJSON},
+		RawBehaviorTreesJSON: {Path: assetPaths.BehaviorTreesJSON},
 	}
 	loader.RawRegistry.Assign(rawResources)
 
@@ -97,6 +103,9 @@ func LoadFormulas(loader *resource.Loader) (map[core.Trait]core.ActionFormula, e
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal formulas data: %w", err)
 	}
+	if err := ValidateFormulas(formulasConfig); err != nil {
+		return nil, fmt.Errorf("invalid formulas data: %w", err)
+	}
 
 	formulas := make(map[core.Trait]core.ActionFormula)
 	for trait, formulaCfg := range formulasConfig {
@@ -106,11 +115,109 @@ func LoadFormulas(loader *resource.Loader) (map[core.Trait]core.ActionFormula, e
 			PowerBonuses:       formulaCfg.PowerBonuses,
 			CriticalRateBonus:  formulaCfg.CriticalRateBonus,
 			UserDebuffs:        formulaCfg.UserDebuffs,
+			TeamBuffs:          formulaCfg.TeamBuffs,
 		}
 	}
 	return formulas, nil
 }
 
+// LoadStages は、ステージ定義をJSONリソースから読み込みます。
+// 背景画像を持つステージには ImageStageBackgroundBase 以降の画像リソースIDを割り当てて登録します。
+func LoadStages(loader *resource.Loader, gdm *GameDataManager) error {
+	res := loader.LoadRaw(RawStagesJSON)
+	var stages []core.StageDefinition
+	if err := json.Unmarshal(res.Data, &stages); err != nil {
+		return fmt.Errorf("failed to unmarshal stages data: %w", err)
+	}
+
+	for i := range stages {
+		stage := &stages[i]
+		normalizeStageDefinition(stage)
+		if err := gdm.AddStageDefinition(stage); err != nil {
+			log.Printf("error adding stage definition %s: %v", stage.ID, err)
+			continue
+		}
+		if stage.Background != "" {
+			imageID := ImageStageBackgroundBase + resource.ImageID(i)
+			loader.ImageRegistry.Set(imageID, resource.ImageInfo{Path: stage.Background})
+			gdm.stageBackgrounds[stage.ID] = imageID
+		}
+	}
+	return nil
+}
+
+// normalizeStageDefinition は省略された（0の）倍率を 1.0 に置き換えます。
+func normalizeStageDefinition(stage *core.StageDefinition) {
+	orOne := func(v float64) float64 {
+		if v == 0 {
+			return 1.0
+		}
+		return v
+	}
+	stage.MobilityMultiplier = orOne(stage.MobilityMultiplier)
+	stage.PropulsionMultiplier = orOne(stage.PropulsionMultiplier)
+	stage.EvasionMultiplier = orOne(stage.EvasionMultiplier)
+	for legType, modifier := range stage.LegTypeModifiers {
+		stage.LegTypeModifiers[legType] = core.StageLegModifier{
+			MobilityMultiplier:   orOne(modifier.MobilityMultiplier),
+			PropulsionMultiplier: orOne(modifier.PropulsionMultiplier),
+		}
+	}
+	for weaponType, modifier := range stage.WeaponModifiers {
+		stage.WeaponModifiers[weaponType] = core.StageWeaponModifier{
+			PowerMultiplier:    orOne(modifier.PowerMultiplier),
+			AccuracyMultiplier: orOne(modifier.AccuracyMultiplier),
+		}
+	}
+}
+
+// LoadSetBonuses は、パーツセットのボーナス定義をJSONリソースから読み込みます。
+func LoadSetBonuses(loader *resource.Loader, gdm *GameDataManager) error {
+	res := loader.LoadRaw(RawSetBonusesJSON)
+	var setBonuses []core.SetBonusDefinition
+	if err := json.Unmarshal(res.Data, &setBonuses); err != nil {
+		return fmt.Errorf("failed to unmarshal set bonuses data: %w", err)
+	}
+	for i := range setBonuses {
+		if err := gdm.AddSetBonusDefinition(&setBonuses[i]); err != nil {
+			log.Printf("error adding set bonus definition %s: %v", setBonuses[i].SetID, err)
+		}
+	}
+	return nil
+}
+
+// LoadPersonalities は、メダルの性格の定義をJSONリソースから読み込みます。
+// 戦略やルールの名前はここでは検証せず、戦闘システムが性格を組み立てる際に検証します。
+func LoadPersonalities(loader *resource.Loader, gdm *GameDataManager) error {
+	res := loader.LoadRaw(RawPersonalitiesJSON)
+	var personalities []core.PersonalityDefinition
+	if err := json.Unmarshal(res.Data, &personalities); err != nil {
+		return fmt.Errorf("failed to unmarshal personalities data: %w", err)
+	}
+	for i := range personalities {
+		if err := gdm.AddPersonalityDefinition(&personalities[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadBehaviorTrees は、AIのビヘイビアツリーの定義をJSONリソースから読み込みます。
+// 条件や行動の名前はここでは検証せず、戦闘システムがツリーを組み立てる際に検証します。
+func LoadBehaviorTrees(loader *resource.Loader, gdm *GameDataManager) error {
+	res := loader.LoadRaw(RawBehaviorTreesJSON)
+	var trees []core.BehaviorTreeDefinition
+	if err := json.Unmarshal(res.Data, &trees); err != nil {
+		return fmt.Errorf("failed to unmarshal behavior trees data: %w", err)
+	}
+	for i := range trees {
+		if err := gdm.AddBehaviorTreeDefinition(&trees[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // LoadAllStaticGameData は、引数で受け取ったローダーを使用して全ての静的ゲームデータを読み込みます。
 func LoadAllStaticGameData(loader *resource.Loader, gdm *GameDataManager) error {
 	if err := LoadMedals(loader, gdm); err != nil {
@@ -119,6 +226,18 @@ func LoadAllStaticGameData(loader *resource.Loader, gdm *GameDataManager) error
 	if err := LoadParts(loader, gdm); err != nil {
 		return fmt.Errorf("failed to load parts.csv: %w", err)
 	}
+	if err := LoadStages(loader, gdm); err != nil {
+		return fmt.Errorf("failed to load stages.json: %w", err)
+	}
+	if err := LoadSetBonuses(loader, gdm); err != nil {
+		return fmt.Errorf("failed to load set_bonuses.json: %w", err)
+	}
+	if err := LoadPersonalities(loader, gdm); err != nil {
+		return fmt.Errorf("failed to load personalities.json: %w", err)
+	}
+	if err := LoadBehaviorTrees(loader, gdm); err != nil {
+		return fmt.Errorf("failed to load behavior_trees.json: %w", err)
+	}
 	return nil
 }
 
@@ -137,11 +256,11 @@ func LoadMedals(loader *resource.Loader, gdm *GameDataManager) error {
 			break
 		}
 		if err != nil {
-			fmt.Printf("error reading record from medals data: %v\n", err)
+			log.Printf("error reading record from medals data: %v", err)
 			continue
 		}
 		if len(record) < 7 {
-			fmt.Printf("skipping malformed record in medals data (not enough columns): %v\n", record)
+			log.Printf("skipping malformed record in medals data (not enough columns): %v", record)
 			continue
 		}
 		medal := core.Medal{
@@ -151,7 +270,7 @@ func LoadMedals(loader *resource.Loader, gdm *GameDataManager) error {
 			SkillLevel:  parseInt(record[6], 1),
 		}
 		if err := gdm.AddMedalDefinition(&medal); err != nil {
-			fmt.Printf("error adding medal definition %s: %v\n", medal.ID, err)
+			log.Printf("error adding medal definition %s: %v", medal.ID, err)
 		}
 	}
 	return nil
@@ -169,7 +288,7 @@ func LoadParts(loader *resource.Loader, gdm *GameDataManager) error {
 			break
 		}
 		if err != nil || len(record) < 15 {
-			fmt.Printf("skipping malformed record in parts data: %v (error: %v)\n", record, err)
+			log.Printf("skipping malformed record in parts data: %v (error: %v)", record, err)
 			continue
 		}
 		maxArmor := parseInt(record[6], 1)
@@ -190,8 +309,22 @@ func LoadParts(loader *resource.Loader, gdm *GameDataManager) error {
 			Stability:  parseInt(record[14], 0),
 			WeaponType: core.WeaponType(record[5]), // WeaponType型にキャスト
 		}
+		if partDef.Type == core.PartTypeLegs {
+			// leg_type 列がない、または NONE の場合は二脚として扱います。
+			partDef.LegType = core.LegTypeBipedal
+			if len(record) > 15 && record[15] != "" && record[15] != "NONE" {
+				partDef.LegType = core.LegType(record[15])
+			}
+		}
+		if len(record) > 16 && record[16] != "NONE" {
+			partDef.SetID = record[16]
+		}
+		if len(record) > 18 {
+			partDef.Weight = parseInt(record[17], 0)
+			partDef.LoadCapacity = parseInt(record[18], 0)
+		}
 		if err := gdm.AddPartDefinition(partDef); err != nil {
-			fmt.Printf("error adding part definition %s: %v\n", partDef.ID, err)
+			log.Printf("error adding part definition %s: %v", partDef.ID, err)
 		}
 	}
 	return nil
@@ -207,17 +340,18 @@ func LoadMedarotLoadouts(loader *resource.Loader) ([]core.MedarotData, error) {
 	}
 
 	var medarots []core.MedarotData
+	var errs []error
 	for {
 		record, err := reader.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			fmt.Printf("error reading record from medarots data: %v\n", err)
+			log.Printf("error reading record from medarots data: %v", err)
 			continue
 		}
 		if len(record) < 10 {
-			fmt.Printf("skipping malformed record in medarots data (not enough columns): %v\n", record)
+			log.Printf("skipping malformed record in medarots data (not enough columns): %v", record)
 			continue
 		}
 		medarot := core.MedarotData{
@@ -232,8 +366,26 @@ func LoadMedarotLoadouts(loader *resource.Loader) ([]core.MedarotData, error) {
 			LeftArmID:  record[8],
 			LegsID:     record[9],
 		}
+		// 11列目は任意で、AIの場合の難易度です。
+		if len(record) > 10 {
+			medarot.AIDifficulty = core.AIDifficulty(record[10])
+			if err := validateAIDifficulty(medarot.AIDifficulty); err != nil {
+				errs = append(errs, fmt.Errorf("medarots data %s: %w", medarot.ID, err))
+			}
+		}
+		// 12列目は任意で、プレイヤーの機体がオートバトルで使う性格です。
+		if len(record) > 11 {
+			medarot.AutoBattlePersonality = record[11]
+		}
+		// 13列目は任意で、AIの機体が行動を決めるビヘイビアツリーです。
+		if len(record) > 12 {
+			medarot.BehaviorTree = record[12]
+		}
 		medarots = append(medarots, medarot)
 	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
 	return medarots, nil
 }
 
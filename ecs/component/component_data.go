@@ -22,6 +22,10 @@ type PlayerActionQueueComponentData struct {
 
 type ActionQueueComponentData struct {
 	Queue []*donburi.Entry
+	// チームのイニシアチブの判定に使う、チームごとの最後の行動の通し番号です。
+	// 最後の行動が古い（または未行動の）チームほど先に行動します。
+	TeamLastActionSeq map[core.TeamID]int
+	ActionSeq         int // 戦闘中に実行された行動の通し番号
 }
 
 type Target struct {
@@ -34,6 +38,9 @@ type AI struct {
 	PersonalityID     string
 	TargetHistory     TargetHistoryData
 	LastActionHistory LastActionHistoryData
+
+	Difficulty     core.AIDifficulty // 性格とは独立に行動を補正する難易度
+	BehaviorTreeID string            // 行動を決めるビヘイビアツリー。空の場合は性格で行動します
 }
 
 type TargetHistoryData struct {
@@ -50,9 +57,80 @@ type TeamBuffs struct {
 }
 
 type BuffSource struct {
-	SourceEntry *donburi.Entry
-	SourcePart  core.PartSlotKey
-	Value       float64
+	SourceEntry  *donburi.Entry
+	SourcePart   core.PartSlotKey
+	Value        float64
+	ExpiresAtSeq int // この行動の通し番号（ActionQueueComponentData.ActionSeq）に達すると失効します。0 は無期限
+}
+
+// ScanMark はスキャンによって機体に付けられたマークです。
+// マークされた機体は回避度が下がり、次に命中した攻撃を防御できません。
+type ScanMark struct {
+	ScannedBy      core.TeamID // スキャンしたチーム。このチームには機体の詳細な情報が表示されます
+	ExpiresAtSeq   int         // この行動の通し番号に達すると失効します。0 は無期限
+	DefenseBlocked bool        // 次の攻撃に対して防御できない状態か
+}
+
+// TeamBrain は、チームごとのAIの戦術と、チームの各機体に出している現在の指示を保持します。ワールド状態エンティティに1つだけ存在します。
+type TeamBrain struct {
+	Tactics map[core.TeamID]core.TeamTactic
+	Intents map[core.TeamID]TeamIntent
+}
+
+// TeamIntent はチームのAIが各機体に出す指示です。機体の戦略はこれを参照して行動を選べます。
+type TeamIntent struct {
+	Kind           core.TeamIntentKind
+	Target         *donburi.Entry
+	TargetPartSlot core.PartSlotKey // 空の場合は狙うパーツを指定しない
+}
+
+// ComboTracker は、コンボの判定に使う最近の命中の記録です。ワールド状態エンティティに1つだけ存在します。
+type ComboTracker struct {
+	RecentHits []ComboHit
+}
+
+// ComboHit はコンボの判定に使う命中1回分の記録です。
+type ComboHit struct {
+	Team           core.TeamID
+	Attacker       *donburi.Entry
+	Target         *donburi.Entry
+	TargetPartSlot core.PartSlotKey
+	Trait          core.Trait
+	Tick           int // 命中した時点の VictoryStateData.ElapsedTicks
+}
+
+// AppliedTeamBuff は行動によって付与されたチームバフ・デバフ1つ分の情報です。
+type AppliedTeamBuff struct {
+	Team       core.TeamID
+	Type       core.BuffType
+	Multiplier float64
+}
+
+// HitRecord は攻撃1ヒット分の結果を保持します。
+// 連続攻撃・拡散・範囲攻撃では、1回の行動で複数のヒットが発生します。
+type HitRecord struct {
+	TargetEntry        *donburi.Entry
+	TargetPartSlot     core.PartSlotKey // 狙ったパーツのスロット
+	DefenderName       string
+	ActionDidHit       bool
+	IsCritical         bool
+	ActionIsDefended   bool
+	IsDefenseBlocked   bool
+	DefendingPartType  string
+	TargetPartType     string           // 実際にヒットしたパーツの部位
+	ActualHitPartSlot  core.PartSlotKey // 実際にヒットしたパーツのスロット
+	OriginalDamage     int
+	DamageDealt        int
+	DamageToApply      int
+	TargetPartInstance *core.PartInstanceData
+	IsTargetPartBroken bool
+
+	// 貫通（防御パーツの装甲を超えたダメージが狙ったパーツに通った場合）の情報
+	PierceDamage       int
+	PiercePartSlot     core.PartSlotKey
+	PiercePartType     string
+	PiercePartInstance *core.PartInstanceData
+	IsPiercePartBroken bool
 }
 
 // ActionResult はアクション実行の詳細な結果を保持します。
@@ -63,12 +141,20 @@ type ActionResult struct {
 	TargetPartSlot core.PartSlotKey // ターゲットのパーツスロット
 
 	// アクションの結果に関する情報
-	ActionDidHit      bool             // 命中したかどうか
-	IsCritical        bool             // クリティカルだったか
-	OriginalDamage    int              // 元のダメージ量
-	DamageDealt       int              // 実際に与えたダメージ
-	ActionIsDefended  bool             // 攻撃が防御されたか
-	ActualHitPartSlot core.PartSlotKey // 実際にヒットしたパーツのスロット
+	ActionDidHit      bool              // 命中したかどうか
+	IsOutOfRange      bool              // 格闘攻撃が距離不足で届かなかったか
+	IsCancelled       bool              // チャージ中のパーツが破壊され、行動が取り消されたか
+	ReselectedFrom    string            // パーツの破壊により行動を選び直した場合の、元のパーツ名
+	IsRetargeted      bool              // ターゲットが機能停止したため、別の敵を狙い直したか
+	AppliedTeamBuffs  []AppliedTeamBuff // 付与したチームバフ・デバフ
+	IsScanApplied     bool              // スキャンでターゲットにマークを付けたか
+	IsDefenseBlocked  bool              // スキャンのマークにより防御できなかったか
+	RepairedArmor     int               // 修復で回復した装甲の量
+	IsCritical        bool              // クリティカルだったか
+	OriginalDamage    int               // 元のダメージ量
+	DamageDealt       int               // 実際に与えたダメージ
+	ActionIsDefended  bool              // 攻撃が防御されたか
+	ActualHitPartSlot core.PartSlotKey  // 実際にヒットしたパーツのスロット
 
 	// メッセージ表示のための情報
 	AttackerName      string
@@ -80,6 +166,16 @@ type ActionResult struct {
 	TargetPartType    string // e.g., "頭部", "脚部"
 	DefendingPartType string // e.g., "頭部", "脚部"
 
+	// 味方との連携攻撃（コンボ）の情報
+	IsCombo          bool
+	ComboPartnerName string // 先に命中させた味方の名前
+	ComboMessageID   string
+
+	// 連続攻撃・拡散・範囲攻撃の情報
+	// Hits には攻撃で発生したすべてのヒットを記録し、代表となるヒットの内容を上の各フィールドにも写します。
+	AttackPattern core.AttackPattern
+	Hits          []HitRecord
+
 	// PostActionEffectSystem で処理される情報
 	AppliedEffects     []interface{}          // アクションによって適用されるステータス効果のデータ
 	DamageToApply      int                    // 実際に適用するダメージ量
@@ -20,6 +20,13 @@ var (
 	ActionIntentComponent = donburi.NewComponentType[core.ActionIntent]()
 	TargetComponent       = donburi.NewComponentType[Target]()
 
+	// --- Set Bonus Component ---
+	// パーツセットを揃えた機体にのみ付与されます。
+	SetBonusComponent = donburi.NewComponentType[core.SetBonusDefinition]()
+
+	// --- Spatial Components ---
+	PositionComponent = donburi.NewComponentType[core.Position]()
+
 	// --- State Components ---
 	StateComponent = donburi.NewComponentType[core.State]()
 
@@ -32,6 +39,15 @@ var (
 	// --- Status Effect Component ---
 	ActiveEffectsComponent = donburi.NewComponentType[core.ActiveEffects]()
 
+	// --- Scan Component ---
+	ScanMarkComponent = donburi.NewComponentType[ScanMark]()
+
+	// --- Combo Tracker Component ---
+	ComboTrackerComponent = donburi.NewComponentType[ComboTracker]()
+
+	// --- Team Brain Component ---
+	TeamBrainComponent = donburi.NewComponentType[TeamBrain]()
+
 	// --- Debug Components ---
 	DebugModeComponent = donburi.NewComponentType[struct{}]()
 
@@ -44,6 +60,12 @@ var (
 	// --- Battle Action Queue Component ---
 	ActionQueueComponentType = donburi.NewComponentType[ActionQueueComponentData]()
 
+	// --- Victory State Component ---
+	VictoryStateComponent = donburi.NewComponentType[core.VictoryStateData]()
+
+	// --- Stage Component ---
+	StageComponent = donburi.NewComponentType[core.StageDefinition]()
+
 	// --- Last Action Result Component ---
 	LastActionResultComponent = donburi.NewComponentType[ActionResult]()
 )
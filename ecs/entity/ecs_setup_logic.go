@@ -2,6 +2,7 @@ package entity
 
 import (
 	"log"
+	"sort"
 
 	"medarot-ebiten/core"
 	"medarot-ebiten/data"
@@ -13,7 +14,8 @@ import (
 )
 
 // InitializeBattleWorld は戦闘ワールドのECSエンティティを初期化します。
-func InitializeBattleWorld(world donburi.World, res *data.SharedResources, playerTeam core.TeamID) {
+// 参加する機体は BattleSetup で宣言されたチーム編成から生成されます。
+func InitializeBattleWorld(world donburi.World, res *data.SharedResources, setup *core.BattleSetup) {
 	EnsureActionQueueEntity(world) // entity. を削除
 
 	// Ensure GameStateComponent entity exists
@@ -28,17 +30,55 @@ func InitializeBattleWorld(world donburi.World, res *data.SharedResources, playe
 	lastActionResultEntry := world.Entry(world.Create(component.LastActionResultComponent, component.WorldStateTag))
 	component.LastActionResultComponent.SetValue(lastActionResultEntry, component.ActionResult{})
 
+	// 勝利条件判定用の進行状況
+	victoryStateEntry := world.Entry(world.Create(component.VictoryStateComponent, component.WorldStateTag))
+	component.VictoryStateComponent.SetValue(victoryStateEntry, core.VictoryStateData{
+		PartBreakCounts: make(map[core.TeamID]int),
+		RoundWins:       make(map[core.TeamID]int),
+		CurrentRound:    1,
+	})
+
 	teamBuffsEntry := world.Entry(world.Create(component.TeamBuffsComponent))
 	component.TeamBuffsComponent.SetValue(teamBuffsEntry, component.TeamBuffs{
 		Buffs: make(map[core.TeamID]map[core.BuffType][]*component.BuffSource),
 	})
 
-	CreateMedarotEntities(world, res.GameData, playerTeam, res.GameDataManager)
+	comboTrackerEntry := world.Entry(world.Create(component.ComboTrackerComponent, component.WorldStateTag))
+	component.ComboTrackerComponent.SetValue(comboTrackerEntry, component.ComboTracker{})
+
+	// チームのAIの戦術。編成で指定されていないチームは設定の既定の戦術を使います。
+	teamBrain := component.TeamBrain{
+		Tactics: make(map[core.TeamID]core.TeamTactic),
+		Intents: make(map[core.TeamID]component.TeamIntent),
+	}
+	for _, teamSetup := range setup.Teams {
+		tactic := teamSetup.Tactic
+		if tactic == "" {
+			tactic = res.Config.TeamBrain.DefaultTactic
+		}
+		teamBrain.Tactics[teamSetup.Team] = tactic
+	}
+	teamBrainEntry := world.Entry(world.Create(component.TeamBrainComponent, component.WorldStateTag))
+	component.TeamBrainComponent.SetValue(teamBrainEntry, teamBrain)
+
+	if setup.StageID != "" {
+		if stage, found := res.GameDataManager.GetStageDefinition(setup.StageID); found {
+			SetStage(world, *stage)
+			log.Printf("ステージ: %s", stage.Name)
+		} else {
+			log.Printf("警告: ステージ %s が見つかりません。地形補正なしで戦闘を開始します。", setup.StageID)
+		}
+	}
+
+	CreateMedarotEntities(world, setup, res.GameDataManager)
+	PlaceMedarotsAtHome(world, &res.Config)
 }
 
-// CreateMedarotEntities はゲームデータからECSのエンティティを生成します。
-func CreateMedarotEntities(world donburi.World, gameData *core.GameData, playerTeam core.TeamID, gameDataManager *data.GameDataManager) {
-	for _, loadout := range gameData.Medarots {
+// CreateMedarotEntities はチーム編成からECSのエンティティを生成します。
+func CreateMedarotEntities(world donburi.World, setup *core.BattleSetup, gameDataManager *data.GameDataManager) {
+	playerTeam := setup.PlayerTeam
+	medarots := data.AllMedarots(setup)
+	for _, loadout := range medarots {
 		entry := world.Entry(world.Create(
 			component.SettingsComponent,
 			component.PartsComponent,
@@ -48,6 +88,7 @@ func CreateMedarotEntities(world donburi.World, gameData *core.GameData, playerT
 			component.LogComponent,
 			component.ActionIntentComponent,
 			component.TargetComponent,
+			component.PositionComponent,
 		))
 		component.SettingsComponent.SetValue(entry, core.Settings{
 			ID:        loadout.ID,
@@ -92,20 +133,90 @@ func CreateMedarotEntities(world donburi.World, gameData *core.GameData, playerT
 		component.ActionIntentComponent.SetValue(entry, core.ActionIntent{})
 		component.TargetComponent.SetValue(entry, component.Target{})
 
+		legType := core.LegTypeBipedal
+		if legsInst := partsInstanceMap[core.PartSlotLegs]; legsInst != nil {
+			if legsDef, found := gameDataManager.GetPartDefinition(legsInst.DefinitionID); found && legsDef.LegType != "" {
+				legType = legsDef.LegType
+			}
+		}
+		component.PositionComponent.SetValue(entry, core.Position{LegType: legType})
+
+		if setBonus, completed := gameDataManager.FindCompletedSetBonus(&loadout); completed {
+			donburi.Add(entry, component.SetBonusComponent, setBonus)
+			log.Printf("%s は %s を揃えています。", loadout.Name, setBonus.Name)
+		}
+
 		if loadout.Team != playerTeam { // AIのみ
+			// 機体ごとの難易度が優先され、なければ戦闘全体の難易度を使います。
+			difficulty := loadout.AIDifficulty
+			if difficulty == "" {
+				difficulty = setup.AIDifficulty
+			}
+			if difficulty == "" {
+				difficulty = core.AIDifficultyNormal
+			}
 
 			donburi.Add(entry, component.AIComponent, &component.AI{
 				PersonalityID:     medalDef.Personality,
 				TargetHistory:     component.TargetHistoryData{},
 				LastActionHistory: component.LastActionHistoryData{},
+				Difficulty:        difficulty,
+				BehaviorTreeID:    loadout.BehaviorTree,
 			})
 		}
 
 		if loadout.Team == playerTeam {
-			donburi.Add(entry, component.PlayerControlComponent, &core.PlayerControl{})
+			donburi.Add(entry, component.PlayerControlComponent, &core.PlayerControl{
+				AutoBattlePersonality: loadout.AutoBattlePersonality,
+			})
 		}
 	}
-	log.Printf("%d体のメダロットエンティティを生成しました。", len(gameData.Medarots))
+	log.Printf("%d体のメダロットエンティティを生成しました。", len(medarots))
+}
+
+// PlaceMedarotsAtHome は各機体のホーム位置と実行ラインを決定し、ホームに配置します。
+// チームはID順に並べ、偶数番目を左、奇数番目を右に置きます。3チーム以上では上下にレーンを分け、
+// レーン内ではチームの機体数で等間隔に並べます（バトルフィールドの描画と同じ配置です）。
+func PlaceMedarotsAtHome(world donburi.World, config *data.Config) {
+	teamSizes := make(map[core.TeamID]int)
+	query.NewQuery(filter.Contains(component.SettingsComponent, component.PositionComponent)).Each(world, func(entry *donburi.Entry) {
+		teamSizes[component.SettingsComponent.Get(entry).Team]++
+	})
+	teams := make([]core.TeamID, 0, len(teamSizes))
+	for team := range teamSizes {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i] < teams[j] })
+	teamIndices := make(map[core.TeamID]int, len(teams))
+	for i, team := range teams {
+		teamIndices[team] = i
+	}
+
+	fieldWidth := config.Spatial.FieldWidth
+	laneCount := (len(teams) + 1) / 2
+	if laneCount < 1 {
+		laneCount = 1
+	}
+	laneHeight := config.Spatial.FieldHeight / float64(laneCount)
+
+	query.NewQuery(filter.Contains(component.SettingsComponent, component.PositionComponent)).Each(world, func(entry *donburi.Entry) {
+		settings := component.SettingsComponent.Get(entry)
+		teamIndex := teamIndices[settings.Team]
+
+		homeX := fieldWidth * float64(config.UI.Battlefield.Team1HomeX)
+		frontX := fieldWidth * float64(config.UI.Battlefield.Team1ExecutionLineX)
+		if teamIndex%2 == 1 {
+			homeX = fieldWidth * float64(config.UI.Battlefield.Team2HomeX)
+			frontX = fieldWidth * float64(config.UI.Battlefield.Team2ExecutionLineX)
+		}
+		laneTop := laneHeight * float64(teamIndex/2)
+		homeY := laneTop + laneHeight/float64(teamSizes[settings.Team]+1)*float64(settings.DrawIndex+1)
+
+		position := component.PositionComponent.Get(entry)
+		position.Home = core.Vector2{X: homeX, Y: homeY}
+		position.FrontX = frontX
+		position.Current = position.Home
+	})
 }
 
 func FindLeader(world donburi.World, teamID core.TeamID) *donburi.Entry {
@@ -118,3 +229,56 @@ func FindLeader(world donburi.World, teamID core.TeamID) *donburi.Entry {
 	})
 	return leaderEntry
 }
+
+// ResetBattleForNextRound はラウンド制の戦闘で次のラウンドを開始するため、
+// 全機体とワールド状態を戦闘開始時の状態に戻します。ラウンド勝利数は保持されます。
+func ResetBattleForNextRound(world donburi.World, gameDataManager *data.GameDataManager) {
+	query.NewQuery(filter.Contains(component.SettingsComponent)).Each(world, func(entry *donburi.Entry) {
+		for _, partInst := range component.PartsComponent.Get(entry).Map {
+			if partDef, found := gameDataManager.GetPartDefinition(partInst.DefinitionID); found {
+				partInst.CurrentArmor = partDef.MaxArmor
+			}
+			partInst.IsBroken = false
+		}
+		component.StateComponent.SetValue(entry, core.State{CurrentState: core.StateIdle})
+		component.GaugeComponent.SetValue(entry, core.Gauge{})
+		component.ActionIntentComponent.SetValue(entry, core.ActionIntent{})
+		component.TargetComponent.SetValue(entry, component.Target{})
+		if entry.HasComponent(component.PositionComponent) {
+			position := component.PositionComponent.Get(entry)
+			position.Current = position.Home
+		}
+		if entry.HasComponent(component.ActiveEffectsComponent) {
+			component.ActiveEffectsComponent.Get(entry).Effects = make([]*core.ActiveStatusEffectData, 0)
+		}
+		if entry.HasComponent(component.ScanMarkComponent) {
+			entry.RemoveComponent(component.ScanMarkComponent)
+		}
+		if entry.HasComponent(component.AIComponent) {
+			ai := component.AIComponent.Get(entry)
+			ai.TargetHistory = component.TargetHistoryData{}
+			ai.LastActionHistory = component.LastActionHistoryData{}
+		}
+	})
+
+	actionQueue := GetActionQueueComponent(world)
+	actionQueue.Queue = make([]*donburi.Entry, 0)
+	actionQueue.TeamLastActionSeq = make(map[core.TeamID]int)
+	actionQueue.ActionSeq = 0
+	GetPlayerActionQueueComponent(world).Queue = make([]*donburi.Entry, 0)
+	if teamBuffsEntry, ok := query.NewQuery(filter.Contains(component.TeamBuffsComponent)).First(world); ok {
+		component.TeamBuffsComponent.Get(teamBuffsEntry).Buffs = make(map[core.TeamID]map[core.BuffType][]*component.BuffSource)
+	}
+
+	if comboTracker := GetComboTracker(world); comboTracker != nil {
+		comboTracker.RecentHits = nil
+	}
+	if teamBrain := GetTeamBrain(world); teamBrain != nil {
+		teamBrain.Intents = make(map[core.TeamID]component.TeamIntent)
+	}
+
+	victoryState := GetVictoryStateComponent(world)
+	victoryState.ElapsedTicks = 0
+	victoryState.PartBreakCounts = make(map[core.TeamID]int)
+	victoryState.CurrentRound++
+}
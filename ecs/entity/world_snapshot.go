@@ -0,0 +1,279 @@
+package entity
+
+import (
+	"sort"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/ecs/component"
+)
+
+// cloneableComponents は CloneWorld が複製するコンポーネントの一覧です。
+// ここにないコンポーネント（UI専用の状態など）は複製されません。
+var cloneableComponents = []donburi.IComponentType{
+	component.SettingsComponent,
+	component.PartsComponent,
+	component.MedalComponent,
+	component.GaugeComponent,
+	component.LogComponent,
+	component.PlayerControlComponent,
+	component.ActionIntentComponent,
+	component.TargetComponent,
+	component.SetBonusComponent,
+	component.PositionComponent,
+	component.StateComponent,
+	component.AIComponent,
+	component.TeamBuffsComponent,
+	component.ActiveEffectsComponent,
+	component.ScanMarkComponent,
+	component.ComboTrackerComponent,
+	component.TeamBrainComponent,
+	component.DebugModeComponent,
+	component.GameStateComponent,
+	component.PlayerActionQueueComponent,
+	component.ActionQueueComponentType,
+	component.VictoryStateComponent,
+	component.StageComponent,
+	component.LastActionResultComponent,
+	component.WorldStateTag,
+}
+
+// worldCloner は複製元のエンティティ・パーツインスタンスと複製先との対応を保持します。
+type worldCloner struct {
+	entries map[donburi.Entity]*donburi.Entry
+	parts   map[*core.PartInstanceData]*core.PartInstanceData
+}
+
+// CloneWorld は戦闘ワールドを複製し、複製したワールドと、複製元のエンティティから複製先のエンティティへの対応を返します。
+// コンポーネントが保持するエントリ・エンティティ・パーツインスタンスへの参照は、複製先のものに付け替えます。
+// ステータス効果のデータとステージ・セットボーナスの定義は変更されないため、複製元と共有します。
+// 複製元のエンティティをIDの順に作り直すため、同じワールドを何度複製しても同じエンティティIDになります。
+func CloneWorld(src donburi.World) (donburi.World, map[donburi.Entity]donburi.Entity) {
+	dst := donburi.NewWorld()
+	c := &worldCloner{
+		entries: make(map[donburi.Entity]*donburi.Entry),
+		parts:   make(map[*core.PartInstanceData]*core.PartInstanceData),
+	}
+
+	filters := make([]filter.LayoutFilter, 0, len(cloneableComponents))
+	for _, ct := range cloneableComponents {
+		filters = append(filters, filter.Contains(ct))
+	}
+	srcEntries := make([]*donburi.Entry, 0)
+	query.NewQuery(filter.Or(filters...)).Each(src, func(entry *donburi.Entry) {
+		srcEntries = append(srcEntries, entry)
+	})
+	sort.Slice(srcEntries, func(i, j int) bool {
+		return srcEntries[i].Entity().Id() < srcEntries[j].Entity().Id()
+	})
+
+	// 1. エンティティを作成し、他のコンポーネントから参照されるパーツインスタンスを先に複製します。
+	for _, srcEntry := range srcEntries {
+		components := make([]donburi.IComponentType, 0)
+		for _, ct := range cloneableComponents {
+			if srcEntry.HasComponent(ct) {
+				components = append(components, ct)
+			}
+		}
+		dstEntry := dst.Entry(dst.Create(components...))
+		c.entries[srcEntry.Entity()] = dstEntry
+
+		cloneComponent(srcEntry, dstEntry, component.PartsComponent, func(parts core.PartsComponentData) core.PartsComponentData {
+			cloned := core.PartsComponentData{Map: make(map[core.PartSlotKey]*core.PartInstanceData, len(parts.Map))}
+			for slot, partInst := range parts.Map {
+				if partInst == nil {
+					cloned.Map[slot] = nil
+					continue
+				}
+				copied := *partInst
+				cloned.Map[slot] = &copied
+				c.parts[partInst] = &copied
+			}
+			return cloned
+		})
+	}
+
+	// 2. 残りのコンポーネントを複製し、参照を付け替えます。
+	for _, srcEntry := range srcEntries {
+		dstEntry := c.entries[srcEntry.Entity()]
+
+		cloneComponent(srcEntry, dstEntry, component.SettingsComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.MedalComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.GaugeComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.LogComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.SetBonusComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.PositionComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.StateComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.ScanMarkComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.GameStateComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.StageComponent, nil)
+		cloneComponent(srcEntry, dstEntry, component.PlayerControlComponent, nil)
+
+		cloneComponent(srcEntry, dstEntry, component.ActionIntentComponent, func(intent core.ActionIntent) core.ActionIntent {
+			intent.PendingEffects = append([]interface{}(nil), intent.PendingEffects...)
+			return intent
+		})
+		cloneComponent(srcEntry, dstEntry, component.TargetComponent, func(target component.Target) component.Target {
+			target.TargetEntity = c.entity(target.TargetEntity)
+			return target
+		})
+		cloneComponent(srcEntry, dstEntry, component.AIComponent, func(ai component.AI) component.AI {
+			ai.TargetHistory.LastAttacker = c.entry(ai.TargetHistory.LastAttacker)
+			ai.LastActionHistory.LastHitTarget = c.entry(ai.LastActionHistory.LastHitTarget)
+			return ai
+		})
+		cloneComponent(srcEntry, dstEntry, component.TeamBuffsComponent, func(teamBuffs component.TeamBuffs) component.TeamBuffs {
+			cloned := component.TeamBuffs{Buffs: make(map[core.TeamID]map[core.BuffType][]*component.BuffSource, len(teamBuffs.Buffs))}
+			for team, buffsByType := range teamBuffs.Buffs {
+				cloned.Buffs[team] = make(map[core.BuffType][]*component.BuffSource, len(buffsByType))
+				for buffType, sources := range buffsByType {
+					clonedSources := make([]*component.BuffSource, 0, len(sources))
+					for _, source := range sources {
+						copied := *source
+						copied.SourceEntry = c.entry(source.SourceEntry)
+						clonedSources = append(clonedSources, &copied)
+					}
+					cloned.Buffs[team][buffType] = clonedSources
+				}
+			}
+			return cloned
+		})
+		cloneComponent(srcEntry, dstEntry, component.ActiveEffectsComponent, func(activeEffects core.ActiveEffects) core.ActiveEffects {
+			cloned := core.ActiveEffects{Effects: make([]*core.ActiveStatusEffectData, 0, len(activeEffects.Effects))}
+			for _, effect := range activeEffects.Effects {
+				copied := *effect
+				cloned.Effects = append(cloned.Effects, &copied)
+			}
+			return cloned
+		})
+		cloneComponent(srcEntry, dstEntry, component.ComboTrackerComponent, func(tracker component.ComboTracker) component.ComboTracker {
+			cloned := component.ComboTracker{RecentHits: make([]component.ComboHit, 0, len(tracker.RecentHits))}
+			for _, hit := range tracker.RecentHits {
+				hit.Attacker = c.entry(hit.Attacker)
+				hit.Target = c.entry(hit.Target)
+				cloned.RecentHits = append(cloned.RecentHits, hit)
+			}
+			return cloned
+		})
+		cloneComponent(srcEntry, dstEntry, component.TeamBrainComponent, func(brain component.TeamBrain) component.TeamBrain {
+			cloned := component.TeamBrain{
+				Tactics: make(map[core.TeamID]core.TeamTactic, len(brain.Tactics)),
+				Intents: make(map[core.TeamID]component.TeamIntent, len(brain.Intents)),
+			}
+			for team, tactic := range brain.Tactics {
+				cloned.Tactics[team] = tactic
+			}
+			for team, intent := range brain.Intents {
+				intent.Target = c.entry(intent.Target)
+				cloned.Intents[team] = intent
+			}
+			return cloned
+		})
+		cloneComponent(srcEntry, dstEntry, component.PlayerActionQueueComponent, func(queue component.PlayerActionQueueComponentData) component.PlayerActionQueueComponentData {
+			return component.PlayerActionQueueComponentData{Queue: c.entryList(queue.Queue)}
+		})
+		cloneComponent(srcEntry, dstEntry, component.ActionQueueComponentType, func(queue component.ActionQueueComponentData) component.ActionQueueComponentData {
+			queue.Queue = c.entryList(queue.Queue)
+			teamLastActionSeq := make(map[core.TeamID]int, len(queue.TeamLastActionSeq))
+			for team, seq := range queue.TeamLastActionSeq {
+				teamLastActionSeq[team] = seq
+			}
+			queue.TeamLastActionSeq = teamLastActionSeq
+			return queue
+		})
+		cloneComponent(srcEntry, dstEntry, component.VictoryStateComponent, func(victoryState core.VictoryStateData) core.VictoryStateData {
+			victoryState.PartBreakCounts = cloneTeamCounts(victoryState.PartBreakCounts)
+			victoryState.RoundWins = cloneTeamCounts(victoryState.RoundWins)
+			return victoryState
+		})
+		cloneComponent(srcEntry, dstEntry, component.LastActionResultComponent, c.actionResult)
+	}
+
+	entities := make(map[donburi.Entity]donburi.Entity, len(c.entries))
+	for srcEntity, dstEntry := range c.entries {
+		entities[srcEntity] = dstEntry.Entity()
+	}
+	return dst, entities
+}
+
+// cloneComponent は、複製元のエントリがコンポーネントを持っていれば、その値を copyFn で複製して複製先に設定します。
+// copyFn が nil の場合は値をそのままコピーします。
+func cloneComponent[T any](srcEntry, dstEntry *donburi.Entry, ct *donburi.ComponentType[T], copyFn func(T) T) {
+	if !srcEntry.HasComponent(ct) {
+		return
+	}
+	value := *ct.Get(srcEntry)
+	if copyFn != nil {
+		value = copyFn(value)
+	}
+	ct.SetValue(dstEntry, value)
+}
+
+// entry は複製元のエントリに対応する複製先のエントリを返します。対応がない場合は nil を返します。
+func (c *worldCloner) entry(srcEntry *donburi.Entry) *donburi.Entry {
+	if srcEntry == nil {
+		return nil
+	}
+	return c.entries[srcEntry.Entity()]
+}
+
+// entity は複製元のエンティティに対応する複製先のエンティティを返します。対応がない場合は donburi.Null を返します。
+func (c *worldCloner) entity(srcEntity donburi.Entity) donburi.Entity {
+	if dstEntry, ok := c.entries[srcEntity]; ok {
+		return dstEntry.Entity()
+	}
+	return donburi.Null
+}
+
+// entryList はエントリのスライスを複製先のエントリに付け替えて返します。
+func (c *worldCloner) entryList(srcEntries []*donburi.Entry) []*donburi.Entry {
+	dstEntries := make([]*donburi.Entry, 0, len(srcEntries))
+	for _, srcEntry := range srcEntries {
+		if dstEntry := c.entry(srcEntry); dstEntry != nil {
+			dstEntries = append(dstEntries, dstEntry)
+		}
+	}
+	return dstEntries
+}
+
+// part は複製元のパーツインスタンスに対応する複製先のパーツインスタンスを返します。
+func (c *worldCloner) part(srcPart *core.PartInstanceData) *core.PartInstanceData {
+	if srcPart == nil {
+		return nil
+	}
+	return c.parts[srcPart]
+}
+
+// actionResult は行動の結果を複製し、参照を複製先のものに付け替えます。
+func (c *worldCloner) actionResult(result component.ActionResult) component.ActionResult {
+	result.ActingEntry = c.entry(result.ActingEntry)
+	result.TargetEntry = c.entry(result.TargetEntry)
+	result.TargetPartInstance = c.part(result.TargetPartInstance)
+	result.AppliedTeamBuffs = append([]component.AppliedTeamBuff(nil), result.AppliedTeamBuffs...)
+	result.AppliedEffects = append([]interface{}(nil), result.AppliedEffects...)
+
+	hits := make([]component.HitRecord, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		hit.TargetEntry = c.entry(hit.TargetEntry)
+		hit.TargetPartInstance = c.part(hit.TargetPartInstance)
+		hit.PiercePartInstance = c.part(hit.PiercePartInstance)
+		hits = append(hits, hit)
+	}
+	result.Hits = hits
+	return result
+}
+
+// cloneTeamCounts はチームごとの数値のマップを複製します。
+func cloneTeamCounts(counts map[core.TeamID]int) map[core.TeamID]int {
+	if counts == nil {
+		return nil
+	}
+	cloned := make(map[core.TeamID]int, len(counts))
+	for team, count := range counts {
+		cloned[team] = count
+	}
+	return cloned
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/yohamta/donburi/filter"
 	"github.com/yohamta/donburi/query"
 
+	"medarot-ebiten/core"
 	"medarot-ebiten/ecs/component"
 )
 
@@ -38,7 +39,8 @@ func EnsureActionQueueEntity(world donburi.World) *donburi.Entry {
 	log.Println("ActionQueueComponent と worldStateTag を持つ ActionQueueEntity を作成します。")
 	newEntry := world.Entry(world.Create(component.ActionQueueComponentType, component.WorldStateTag))
 	component.ActionQueueComponentType.SetValue(newEntry, component.ActionQueueComponentData{
-		Queue: make([]*donburi.Entry, 0),
+		Queue:             make([]*donburi.Entry, 0),
+		TeamLastActionSeq: make(map[core.TeamID]int),
 	})
 	return newEntry
 }
@@ -69,3 +71,49 @@ func EnsurePlayerActionQueueEntity(world donburi.World) *donburi.Entry {
 	})
 	return newEntry
 }
+
+// GetVictoryStateComponent はワールド状態エンティティから VictoryStateData を取得します。
+func GetVictoryStateComponent(world donburi.World) *core.VictoryStateData {
+	entry, ok := query.NewQuery(filter.Contains(component.VictoryStateComponent)).First(world)
+	if !ok {
+		log.Panicln("VictoryStateComponent がワールドに見つかりません。ワールド状態エンティティで初期化する必要があります。")
+		return nil
+	}
+	return component.VictoryStateComponent.Get(entry)
+}
+
+// GetStage は現在の戦闘ステージの定義を返します。ステージが設定されていない場合は nil を返します。
+func GetStage(world donburi.World) *core.StageDefinition {
+	entry, ok := query.NewQuery(filter.Contains(component.StageComponent)).First(world)
+	if !ok {
+		return nil
+	}
+	return component.StageComponent.Get(entry)
+}
+
+// SetStage は戦闘ステージを設定します。ステージ用のワールド状態エンティティがなければ作成します。
+func SetStage(world donburi.World, stage core.StageDefinition) {
+	entry, ok := query.NewQuery(filter.Contains(component.StageComponent)).First(world)
+	if !ok {
+		entry = world.Entry(world.Create(component.StageComponent, component.WorldStateTag))
+	}
+	component.StageComponent.SetValue(entry, stage)
+}
+
+// GetComboTracker はコンボの判定に使う命中の記録を返します。記録用のエンティティがない場合は nil を返します。
+func GetComboTracker(world donburi.World) *component.ComboTracker {
+	entry, ok := query.NewQuery(filter.Contains(component.ComboTrackerComponent)).First(world)
+	if !ok {
+		return nil
+	}
+	return component.ComboTrackerComponent.Get(entry)
+}
+
+// GetTeamBrain はチームのAIの戦術と指示を返します。記録用のエンティティがない場合は nil を返します。
+func GetTeamBrain(world donburi.World) *component.TeamBrain {
+	entry, ok := query.NewQuery(filter.Contains(component.TeamBrainComponent)).First(world)
+	if !ok {
+		return nil
+	}
+	return component.TeamBrainComponent.Get(entry)
+}
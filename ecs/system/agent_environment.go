@@ -0,0 +1,357 @@
+package system
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// agentTeamBuffTypes は観測に含めるチームバフ・デバフの種類です。
+var agentTeamBuffTypes = []core.BuffType{
+	core.BuffTypeAccuracy,
+	core.BuffTypeDefense,
+	core.BuffTypeEvasion,
+	core.BuffTypePower,
+	core.BuffTypeChargeSpeed,
+	core.BuffTypeCooldownSpeed,
+}
+
+// agentTargetedWeaponTypes は、支援パーツでもチャージ時に選んだ敵を対象に効果を与える武器種です。
+var agentTargetedWeaponTypes = map[core.WeaponType]bool{
+	core.WeaponTypeScan: true, // ScanEffectHandler がターゲットの敵にマークを付けます
+}
+
+// agentPartSlots は観測と行動の候補でパーツを並べる順序です。
+var agentPartSlots = []core.PartSlotKey{core.PartSlotHead, core.PartSlotRightArm, core.PartSlotLeftArm, core.PartSlotLegs}
+
+// agentEnvironment は、外部のボットとJSONでやり取りしながら戦闘を進めるエージェント環境です。
+type agentEnvironment struct {
+	res     *data.SharedResources
+	config  *data.Config
+	setup   *core.BattleSetup
+	decoder *json.Decoder
+	encoder *json.Encoder
+	episode int
+	err     error // 通信の失敗。設定されるとエピソードを打ち切ります
+}
+
+// RunAgentEnvironment は、戦闘エンジンを外部のボットが1体ずつ行動を選ぶ環境として実行します。
+// プレイヤーチームの機体が行動を選ぶたびに、戦況と選べる行動を observation メッセージとして1行のJSONで w に書き出し、
+// r から {"Action": 行動の番号} を1つ読み込んでその行動のチャージを開始します。不正な行動には error メッセージを返して読み直します。
+// 敵チームの機体は各自の性格に従って行動し、エピソードが終わるたびに result メッセージを書き出します。
+// 各エピソードの乱数は seed から決まるため、同じ seed・データ・行動からは同じ戦闘になります。
+// ボットが入力を閉じた場合は、その時点で正常に終了します。
+func RunAgentEnvironment(res *data.SharedResources, seed int64, r io.Reader, w io.Writer) error {
+	settings := res.Config.Agent
+	if settings.Episodes <= 0 {
+		return fmt.Errorf("エピソード数 %d が不正です", settings.Episodes)
+	}
+	if settings.MaxTicks <= 0 {
+		return fmt.Errorf("上限ティック数 %d が不正です", settings.MaxTicks)
+	}
+
+	setup, err := data.NewBattleSetupFromGameData(res.GameData, core.Team1)
+	if err != nil {
+		return fmt.Errorf("対戦に使う機体構成を組み立てられません: %w", err)
+	}
+	if err := data.ValidateBattleSetupLoads(setup, res.GameDataManager, &res.Config); err != nil {
+		return fmt.Errorf("積載量の上限を超える機体がいるため対戦できません: %w", err)
+	}
+
+	env := &agentEnvironment{
+		res:     res,
+		config:  headlessConfig(res.Config),
+		setup:   setup,
+		decoder: json.NewDecoder(r),
+		encoder: json.NewEncoder(w),
+	}
+	seeds := rand.New(rand.NewSource(seed))
+	for env.episode = 1; env.episode <= settings.Episodes; env.episode++ {
+		var result core.AgentEpisodeResult
+		withLogsSilenced(func() {
+			result = env.runEpisode(seeds.Int63(), settings.MaxTicks)
+		})
+		if errors.Is(env.err, io.EOF) {
+			return nil
+		}
+		if env.err != nil {
+			return env.err
+		}
+		if err := env.send(core.AgentMessage{Type: core.AgentMessageResult, Result: &result}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runEpisode は1エピソードの戦闘を、決着がつくか maxTicks ティックが経過するか通信に失敗するまで進めます。
+func (env *agentEnvironment) runEpisode(seed int64, maxTicks int) core.AgentEpisodeResult {
+	world := donburi.NewWorld()
+	entity.InitializeBattleWorld(world, env.res, env.setup)
+	sim := NewBattleSimulator(world, env.config, env.res.GameDataManager, rand.New(rand.NewSource(seed)))
+	sim.decide = func(entry *donburi.Entry) bool {
+		if env.err != nil {
+			return true // 通信に失敗した後は行動を選ばず、このティックで打ち切ります
+		}
+		if component.SettingsComponent.Get(entry).Team != env.setup.PlayerTeam {
+			return false
+		}
+		return env.decide(sim, entry)
+	}
+
+	result := CheckGameEndSystem(world, sim.victoryRule)
+	for tick := 0; tick < maxTicks && !result.IsGameOver && env.err == nil; tick++ {
+		if result.IsRoundOver {
+			StartNextRoundSystem(world, env.res.GameDataManager, result)
+		}
+		result = sim.step()
+	}
+	return core.AgentEpisodeResult{
+		IsGameOver: result.IsGameOver,
+		Winner:     result.Winner,
+		Ticks:      entity.GetVictoryStateComponent(world).ElapsedTicks,
+	}
+}
+
+// decide はエージェントの機体の観測を送り、受け取った行動のチャージを開始します。
+// 選べる行動がない場合は false を返し、機体の性格に行動を任せます。
+func (env *agentEnvironment) decide(sim *BattleSimulator, entry *donburi.Entry) bool {
+	actions, legalActions := sim.agentLegalActions(entry)
+	if len(actions) == 0 {
+		return false
+	}
+	observation := &core.AgentObservation{
+		Tick:         entity.GetVictoryStateComponent(sim.world).ElapsedTicks,
+		ActingUnit:   component.SettingsComponent.Get(entry).ID,
+		Units:        sim.agentUnits(),
+		LegalActions: legalActions,
+	}
+
+	for {
+		if env.err = env.send(core.AgentMessage{Type: core.AgentMessageObservation, Observation: observation}); env.err != nil {
+			return true
+		}
+		var action core.AgentAction
+		if env.err = env.decoder.Decode(&action); env.err != nil {
+			if !errors.Is(env.err, io.EOF) {
+				env.err = fmt.Errorf("行動を読み込めません: %w", env.err)
+			}
+			return true
+		}
+		if action.Action < 0 || action.Action >= len(actions) {
+			if env.err = env.sendError(fmt.Sprintf("行動の番号 %d は範囲外です (0〜%d)", action.Action, len(actions)-1)); env.err != nil {
+				return true
+			}
+			continue
+		}
+		if !sim.startLookaheadAction(entry, actions[action.Action]) {
+			if env.err = env.sendError(fmt.Sprintf("行動 %d のチャージを開始できません", action.Action)); env.err != nil {
+				return true
+			}
+			continue
+		}
+		return true
+	}
+}
+
+// send はメッセージを1行のJSONとして書き出します。
+func (env *agentEnvironment) send(message core.AgentMessage) error {
+	message.Episode = env.episode
+	if err := env.encoder.Encode(message); err != nil {
+		return fmt.Errorf("メッセージを書き出せません: %w", err)
+	}
+	return nil
+}
+
+// sendError は受け取った行動が不正だったことを通知します。
+func (env *agentEnvironment) sendError(message string) error {
+	return env.send(core.AgentMessage{Type: core.AgentMessageError, Error: message})
+}
+
+// agentLegalActions は、プレイヤーの行動選択と同じく GetAvailableAttackParts で使えるパーツから、選べる行動を決まった順序で返します。
+// 射撃は狙える敵パーツごと、格闘は実行時にターゲットが決まるため1つ、
+// 修復は味方の破壊されていないパーツごと、妨害やスキャンなどターゲットを持つ介入は敵の機体ごと、それ以外の支援はターゲットなしで1つの行動になります。
+func (s *BattleSimulator) agentLegalActions(entry *donburi.Entry) ([]lookaheadAction, []core.AgentLegalAction) {
+	var actions []lookaheadAction
+	var legalActions []core.AgentLegalAction
+	add := func(available core.AvailablePart, target *donburi.Entry, targetPartSlot core.PartSlotKey) {
+		action := lookaheadAction{Slot: available.Slot, TargetEntity: donburi.Null, TargetPartSlot: targetPartSlot}
+		legal := core.AgentLegalAction{
+			Index:          len(actions),
+			Slot:           available.Slot,
+			PartName:       available.PartDef.PartName,
+			Category:       available.PartDef.Category,
+			Trait:          available.PartDef.Trait,
+			TargetPartSlot: targetPartSlot,
+		}
+		if target != nil {
+			action.TargetEntity = target.Entity()
+			legal.TargetUnit = component.SettingsComponent.Get(target).ID
+		}
+		actions = append(actions, action)
+		legalActions = append(legalActions, legal)
+	}
+
+	availableParts := s.targetSelector.FilterReachableParts(entry, s.partInfoProvider.GetAvailableAttackParts(entry))
+	sort.Slice(availableParts, func(i, j int) bool {
+		return agentSlotOrder(availableParts[i].Slot) < agentSlotOrder(availableParts[j].Slot)
+	})
+	for _, available := range availableParts {
+		switch available.PartDef.Category {
+		case core.CategoryRanged:
+			for _, enemy := range s.targetSelector.GetTargetableEnemies(entry) {
+				for _, slot := range agentUnbrokenSlots(enemy) {
+					add(available, enemy, slot)
+				}
+			}
+		case core.CategoryMelee:
+			if s.targetSelector.FindClosestEnemy(entry) != nil {
+				add(available, nil, "")
+			}
+		case core.CategoryIntervention:
+			switch available.PartDef.Trait {
+			case core.TraitRepair:
+				for _, ally := range s.targetSelector.GetAllies(entry) {
+					for _, slot := range agentUnbrokenSlots(ally) {
+						add(available, ally, slot)
+					}
+				}
+			case core.TraitSupport:
+				if !agentTargetedWeaponTypes[available.PartDef.WeaponType] {
+					add(available, nil, "")
+					break
+				}
+				for _, enemy := range s.targetSelector.GetTargetableEnemies(entry) {
+					add(available, enemy, "")
+				}
+			default:
+				for _, enemy := range s.targetSelector.GetTargetableEnemies(entry) {
+					add(available, enemy, "")
+				}
+			}
+		}
+	}
+	return actions, legalActions
+}
+
+// agentUnits は全機体の状態を、チームと表示順による固定の順序で返します。
+func (s *BattleSimulator) agentUnits() []core.AgentUnit {
+	var entries []*donburi.Entry
+	query.NewQuery(filter.Contains(component.SettingsComponent, component.PartsComponent, component.StateComponent)).Each(s.world, func(entry *donburi.Entry) {
+		entries = append(entries, entry)
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		return compareSettingsOrder(entries[i], entries[j])
+	})
+
+	gdm := s.partInfoProvider.GetGameDataManager()
+	units := make([]core.AgentUnit, 0, len(entries))
+	for _, entry := range entries {
+		settings := component.SettingsComponent.Get(entry)
+		unit := core.AgentUnit{
+			ID:        settings.ID,
+			Name:      settings.Name,
+			Team:      settings.Team,
+			IsLeader:  settings.IsLeader,
+			State:     component.StateComponent.Get(entry).CurrentState,
+			Scanned:   entry.HasComponent(component.ScanMarkComponent),
+			TeamBuffs: make(map[core.BuffType]float64),
+		}
+		if entry.HasComponent(component.GaugeComponent) {
+			unit.Gauge = component.GaugeComponent.Get(entry).CurrentGauge
+		}
+
+		partsMap := component.PartsComponent.Get(entry).Map
+		for _, slot := range agentPartSlots {
+			partInst := partsMap[slot]
+			if partInst == nil {
+				continue
+			}
+			part := core.AgentPart{Slot: slot, PartID: partInst.DefinitionID, Armor: partInst.CurrentArmor, IsBroken: partInst.IsBroken}
+			if partDef, ok := gdm.GetPartDefinition(partInst.DefinitionID); ok {
+				part.PartName = partDef.PartName
+				part.Category = partDef.Category
+				part.Trait = partDef.Trait
+				part.Power = partDef.Power
+				part.Accuracy = partDef.Accuracy
+				part.Charge = partDef.Charge
+				part.Cooldown = partDef.Cooldown
+				part.MaxArmor = partDef.MaxArmor
+			}
+			unit.Parts = append(unit.Parts, part)
+		}
+
+		if entry.HasComponent(component.ActiveEffectsComponent) {
+			for _, active := range component.ActiveEffectsComponent.Get(entry).Effects {
+				if effect, ok := agentEffect(active); ok {
+					unit.Effects = append(unit.Effects, effect)
+				}
+			}
+		}
+
+		for _, buffType := range agentTeamBuffTypes {
+			if multiplier := s.partInfoProvider.GetTeamBuffMultiplier(entry, buffType); multiplier != 1.0 {
+				unit.TeamBuffs[buffType] = multiplier
+			}
+		}
+		units = append(units, unit)
+	}
+	return units
+}
+
+// agentEffect は機体にかかっている効果を観測の形式にします。観測に含めない種類の効果は false を返します。
+func agentEffect(active *core.ActiveStatusEffectData) (core.AgentEffect, bool) {
+	effect := core.AgentEffect{RemainingTurns: active.RemainingDur}
+	switch data := active.EffectData.(type) {
+	case *core.ChargeStopEffectData:
+		effect.Type = "charge_stop"
+	case *core.DamageOverTimeEffectData:
+		effect.Type = "damage_over_time"
+		effect.Value = float64(data.DamagePerTurn)
+	case *core.TargetRandomEffectData:
+		effect.Type = "target_random"
+	case *core.EvasionDebuffEffectData:
+		effect.Type = "evasion_debuff"
+		effect.Value = data.Multiplier
+	case *core.DefenseDebuffEffectData:
+		effect.Type = "defense_debuff"
+		effect.Value = data.Multiplier
+	default:
+		return core.AgentEffect{}, false
+	}
+	return effect, true
+}
+
+// agentUnbrokenSlots は機体の破壊されていないパーツのスロットを、決まった順序で返します。
+func agentUnbrokenSlots(entry *donburi.Entry) []core.PartSlotKey {
+	var slots []core.PartSlotKey
+	partsMap := component.PartsComponent.Get(entry).Map
+	for _, slot := range agentPartSlots {
+		if partInst := partsMap[slot]; partInst != nil && !partInst.IsBroken {
+			slots = append(slots, slot)
+		}
+	}
+	return slots
+}
+
+// agentSlotOrder は agentPartSlots でのスロットの順番を返します。
+func agentSlotOrder(slot core.PartSlotKey) int {
+	for i, s := range agentPartSlots {
+		if s == slot {
+			return i
+		}
+	}
+	return len(agentPartSlots)
+}
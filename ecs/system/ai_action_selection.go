@@ -5,6 +5,7 @@ import (
 	"math/rand"
 
 	"medarot-ebiten/core"
+	"medarot-ebiten/data"
 	"medarot-ebiten/ecs/component"
 
 	"github.com/yohamta/donburi"
@@ -18,21 +19,19 @@ func aiSelectAction(
 	partInfoProvider PartInfoProviderInterface,
 	chargeSystem *ChargeInitiationSystem,
 	targetSelector *TargetSelector,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+	gameConfig *data.Config,
 	// randの型を *core.Rand から正しい *rand.Rand に修正しました。
 	rand *rand.Rand,
 ) {
-	settings := component.SettingsComponent.Get(entry)
-
-	// 利用可能な攻撃パーツを取得
-	availableParts := partInfoProvider.GetAvailableAttackParts(entry)
-	if len(availableParts) == 0 {
-		log.Printf("%s: AIは攻撃可能なパーツがないため待機。", settings.Name)
-		return
-	}
+	personality := aiPersonalityFor(entry)
+	aiSelectActionWithPersonality(world, entry, personality, partInfoProvider, chargeSystem, targetSelector, hitCalculator, damageCalculator, gameConfig, rand)
+}
 
-	// AIの性格に基づいた戦略を取得
-	var targetingStrategy TargetingStrategy
-	var partSelectionStrategy AIPartSelectionStrategyFunc
+// aiPersonalityFor はAIの性格に基づいた戦略を取得します。見つからない場合はリーダーの戦略を返します。
+func aiPersonalityFor(entry *donburi.Entry) AIPersonality {
+	settings := component.SettingsComponent.Get(entry)
 	if entry.HasComponent(component.AIComponent) {
 		ai := component.AIComponent.Get(entry)
 		personality, ok := PersonalityRegistry[ai.PersonalityID]
@@ -40,14 +39,88 @@ func aiSelectAction(
 			log.Printf("%s: AIエラー - PersonalityID '%s' がレジストリに見つかりません。デフォルト（リーダー）を使用。", settings.Name, ai.PersonalityID)
 			personality = PersonalityRegistry["リーダー"] // フォールバック
 		}
-		targetingStrategy = personality.TargetingStrategy
-		partSelectionStrategy = personality.PartSelectionStrategy
-	} else {
-		// AIコンポーネントがない場合のフォールバック
-		log.Printf("%s: AIエラー - AIComponentがありません。デフォルト（リーダー）を使用。", settings.Name)
-		personality := PersonalityRegistry["リーダー"] // フォールバック
-		targetingStrategy = personality.TargetingStrategy
-		partSelectionStrategy = personality.PartSelectionStrategy
+		return personality
+	}
+	// AIコンポーネントがない場合のフォールバック
+	log.Printf("%s: AIエラー - AIComponentがありません。デフォルト（リーダー）を使用。", settings.Name)
+	return PersonalityRegistry["リーダー"] // フォールバック
+}
+
+// aiSelectActionWithPersonality は行動を決定し、チャージを開始します。
+// ビヘイビアツリーを持つ機体はツリーで行動を決め、ツリーが行動を決められなかった場合や、
+// ツリーを持たない機体は指定した性格の戦略に従って行動を決めます。
+func aiSelectActionWithPersonality(
+	world donburi.World,
+	entry *donburi.Entry,
+	personality AIPersonality,
+	partInfoProvider PartInfoProviderInterface,
+	chargeSystem *ChargeInitiationSystem,
+	targetSelector *TargetSelector,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+	gameConfig *data.Config,
+	rand *rand.Rand,
+) {
+	settings := component.SettingsComponent.Get(entry)
+
+	// 機体がアイドル状態になったので、チームへの指示を更新します。
+	UpdateTeamBrainSystem(world, entry, targetSelector, partInfoProvider, gameConfig)
+
+	// 利用可能な攻撃パーツを取得（格闘が届く敵がいなければ格闘パーツを除きます）
+	availableParts := targetSelector.FilterReachableParts(entry, partInfoProvider.GetAvailableAttackParts(entry))
+	if len(availableParts) == 0 {
+		log.Printf("%s: AIは攻撃可能なパーツがないため待機。", settings.Name)
+		return
+	}
+
+	if tree := behaviorTreeFor(entry); tree != nil {
+		if plan, ok := tree.PlanAction(&BehaviorContext{
+			World:            world,
+			Entry:            entry,
+			AvailableParts:   availableParts,
+			TargetSelector:   targetSelector,
+			PartInfoProvider: partInfoProvider,
+			HitCalculator:    hitCalculator,
+			DamageCalculator: damageCalculator,
+			GameConfig:       gameConfig,
+			Rand:             rand,
+		}); ok {
+			startAIAction(entry, plan, chargeSystem)
+			return
+		}
+		log.Printf("%s: ビヘイビアツリー %s は行動を決められなかったため、性格に従って行動します。", settings.Name, tree.ID)
+	}
+
+	plan, ok := planActionWithPersonality(world, entry, personality, availableParts, partInfoProvider, targetSelector, hitCalculator, damageCalculator, gameConfig, rand)
+	if !ok {
+		return
+	}
+	startAIAction(entry, plan, chargeSystem)
+}
+
+// planActionWithPersonality は性格の戦略に従って行動を決め、難易度による補正を加えた行動を返します。
+func planActionWithPersonality(
+	world donburi.World,
+	entry *donburi.Entry,
+	personality AIPersonality,
+	availableParts []core.AvailablePart,
+	partInfoProvider PartInfoProviderInterface,
+	targetSelector *TargetSelector,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+	gameConfig *data.Config,
+	rand *rand.Rand,
+) (AIActionPlan, bool) {
+	settings := component.SettingsComponent.Get(entry)
+	targetingStrategy := personality.TargetingStrategy
+	partSelectionStrategy := personality.PartSelectionStrategy
+	actionPlanner := personality.ActionPlanner
+
+	// 行動計画を立てる性格は、パーツとターゲットをまとめて決めます。
+	if actionPlanner != nil {
+		if plan, ok := actionPlanner.PlanAction(world, entry, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator, gameConfig); ok {
+			return applyAIDifficulty(entry, plan, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator, gameConfig, rand), true
+		}
 	}
 
 	// 1. パーツ選択戦略の実行
@@ -55,27 +128,38 @@ func aiSelectAction(
 	slotKey, selectedPartDef := partSelectionStrategy(entry, availableParts)
 	if selectedPartDef == nil {
 		log.Printf("%s: AIは戦略に基づいて選択できるパーツがありませんでした。", settings.Name)
-		return
+		return AIActionPlan{}, false
 	}
+	// 介入パーツは戦況を見て、使う意味がある場合だけ使います。
+	plan := selectSupportPlan(world, entry, personality, availableParts, AIActionPlan{Slot: slotKey, PartDef: selectedPartDef}, targetSelector, partInfoProvider, rand)
 
 	// 2. ターゲット選択戦略の実行
 	// ターゲット選択はWorldの状態に依存するため、必要なシステムを渡します。
-	targetEntry, targetPartSlot := targetingStrategy.SelectTarget(world, entry, targetSelector, partInfoProvider, rand)
+	// 介入パーツの判断でターゲットが決まっている場合は、その機体を狙います。
+	if plan.TargetEntry == nil {
+		plan.TargetEntry, plan.TargetPartSlot = targetingStrategy.SelectTarget(world, entry, targetSelector, partInfoProvider, rand)
+	}
 
-	// 3. 行動開始
-	// カテゴリに応じてチャージを開始します。
-	switch selectedPartDef.Category {
+	// 3. 難易度による補正
+	// 性格の戦略が選んだ行動を、難易度に応じて差し替えます。
+	return applyAIDifficulty(entry, plan, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator, gameConfig, rand), true
+}
+
+// startAIAction は決めた行動のチャージを、パーツのカテゴリに応じて開始します。
+func startAIAction(entry *donburi.Entry, plan AIActionPlan, chargeSystem *ChargeInitiationSystem) {
+	settings := component.SettingsComponent.Get(entry)
+	switch plan.PartDef.Category {
 	case core.CategoryRanged, core.CategoryIntervention:
-		if targetEntry == nil {
-			log.Printf("%s: AIは[%s]の攻撃対象がいないため待機。", settings.Name, selectedPartDef.Category)
+		if plan.TargetEntry == nil {
+			log.Printf("%s: AIは[%s]の攻撃対象がいないため待機。", settings.Name, plan.PartDef.Category)
 			return
 		}
-		chargeSystem.StartCharge(entry, slotKey, targetEntry, targetPartSlot)
+		chargeSystem.StartCharge(entry, plan.Slot, plan.TargetEntry, plan.TargetPartSlot)
 	case core.CategoryMelee:
 		// 格闘の場合は実行時にターゲットが決まるため、ここではターゲットを指定しません。
-		chargeSystem.StartCharge(entry, slotKey, nil, "")
+		chargeSystem.StartCharge(entry, plan.Slot, nil, "")
 	default:
-		log.Printf("%s: AIはパーツカテゴリ '%s' (%s) の行動を決定できませんでした。", settings.Name, selectedPartDef.PartName, selectedPartDef.Category)
+		log.Printf("%s: AIはパーツカテゴリ '%s' (%s) の行動を決定できませんでした。", settings.Name, plan.PartDef.PartName, plan.PartDef.Category)
 	}
 }
 
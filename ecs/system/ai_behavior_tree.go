@@ -0,0 +1,500 @@
+package system
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+)
+
+// BehaviorContext は、ビヘイビアツリーの評価中に各ノードが参照する戦況と、行動ノードが決めた行動です。
+type BehaviorContext struct {
+	World            donburi.World
+	Entry            *donburi.Entry
+	AvailableParts   []core.AvailablePart
+	TargetSelector   *TargetSelector
+	PartInfoProvider PartInfoProviderInterface
+	HitCalculator    *HitCalculator
+	DamageCalculator *DamageCalculator
+	GameConfig       *data.Config
+	Rand             *rand.Rand
+
+	plan       AIActionPlan // 最後に成功した行動ノードが決めた行動
+	actionName string       // 行動を決めた行動ノードの名前（ログ用）
+}
+
+// BehaviorNode はビヘイビアツリーのノードです。Tick は成功した場合に true を返します。
+// AIの行動選択は1回の評価で完結するため、実行中（Running）の状態は持ちません。
+type BehaviorNode interface {
+	Tick(ctx *BehaviorContext) bool
+}
+
+// BehaviorConditionFunc は戦況の条件を判定する関数です。
+type BehaviorConditionFunc func(ctx *BehaviorContext) bool
+
+// BehaviorActionFunc は行動を決める関数です。行動を決められなかった場合は false を返します。
+type BehaviorActionFunc func(ctx *BehaviorContext) (AIActionPlan, bool)
+
+// SelectorNode は子を順に評価し、最初に成功した子で成功します。すべての子が失敗した場合は失敗します。
+type SelectorNode struct {
+	Children []BehaviorNode
+}
+
+func (n *SelectorNode) Tick(ctx *BehaviorContext) bool {
+	for _, child := range n.Children {
+		if child.Tick(ctx) {
+			return true
+		}
+	}
+	return false
+}
+
+// SequenceNode は子を順に評価し、すべての子が成功すれば成功します。失敗した子があればそこで失敗します。
+// 失敗した場合は、途中の行動ノードが決めた行動を取り消します。
+type SequenceNode struct {
+	Children []BehaviorNode
+}
+
+func (n *SequenceNode) Tick(ctx *BehaviorContext) bool {
+	plan, actionName := ctx.plan, ctx.actionName
+	for _, child := range n.Children {
+		if !child.Tick(ctx) {
+			ctx.plan, ctx.actionName = plan, actionName
+			return false
+		}
+	}
+	return true
+}
+
+// ConditionNode は戦況の条件を判定します。Not が true の場合は判定を反転します。
+type ConditionNode struct {
+	Check BehaviorConditionFunc
+	Not   bool
+}
+
+func (n *ConditionNode) Tick(ctx *BehaviorContext) bool {
+	return n.Check(ctx) != n.Not
+}
+
+// ActionNode は行動を決めます。行動を決められれば成功し、その行動をツリーの行動とします。
+type ActionNode struct {
+	Name string
+	Plan BehaviorActionFunc
+}
+
+func (n *ActionNode) Tick(ctx *BehaviorContext) bool {
+	plan, ok := n.Plan(ctx)
+	if !ok {
+		return false
+	}
+	ctx.plan = plan
+	ctx.actionName = n.Name
+	return true
+}
+
+// BehaviorTree はAIの行動を決めるビヘイビアツリーです。
+type BehaviorTree struct {
+	ID   string
+	Root BehaviorNode
+}
+
+// PlanAction はツリーを評価し、行動ノードが決めた行動を返します。行動が決まらなかった場合は false を返します。
+// ツリーの行動は台本どおりに行動させるため、難易度による補正をかけません。
+func (t *BehaviorTree) PlanAction(ctx *BehaviorContext) (AIActionPlan, bool) {
+	ctx.plan = AIActionPlan{}
+	if !t.Root.Tick(ctx) || ctx.plan.PartDef == nil {
+		return AIActionPlan{}, false
+	}
+	log.Printf("%s: ビヘイビアツリー %s の行動 %s により %s を選択。", component.SettingsComponent.Get(ctx.Entry).Name, t.ID, ctx.actionName, ctx.plan.PartDef.PartName)
+	return ctx.plan, true
+}
+
+// BehaviorTreeRegistry は、ツリーのIDをキーとしてビヘイビアツリーを保持するグローバルなマップです。
+// 起動時に InitBehaviorTreeRegistry で behavior_trees.json の定義から組み立てられます。
+var BehaviorTreeRegistry = map[string]*BehaviorTree{}
+
+// behaviorConditionFactories は、behavior_trees.json で指定できる条件の名前と、パラメータから条件を生成する関数です。
+var behaviorConditionFactories = map[string]func(params json.RawMessage) (BehaviorConditionFunc, error){
+	"leader_head_armor_below": newLeaderHeadArmorBelowCondition,
+	"self_armor_below":        newSelfArmorBelowCondition,
+	"ally_armor_below":        newAllyArmorBelowCondition,
+	"enemy_charging":          newEnemyChargingCondition,
+	"has_part":                newHasPartCondition,
+	"chance":                  newChanceCondition,
+}
+
+// behaviorActionFactories は、behavior_trees.json で指定できる行動の名前と、パラメータから行動を生成する関数です。
+var behaviorActionFactories = map[string]func(params json.RawMessage) (BehaviorActionFunc, error){
+	"use_part":    newUsePartAction,
+	"personality": newPersonalityAction,
+}
+
+// InitBehaviorTreeRegistry は、ゲームデータに読み込まれたビヘイビアツリーの定義から BehaviorTreeRegistry を組み立てます。
+// 行動から性格を参照するため、InitPersonalityRegistry の後に呼び出します。
+// 存在しない条件・行動の名前や不正なパラメータはまとめてエラーとして返します。
+func InitBehaviorTreeRegistry(gdm *data.GameDataManager) error {
+	registry := make(map[string]*BehaviorTree)
+	var errs []error
+	for _, def := range gdm.GetAllBehaviorTreeDefinitions() {
+		root, err := buildBehaviorNode(def.Root, "Root")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ビヘイビアツリー %s: %w", def.ID, err))
+			continue
+		}
+		registry[def.ID] = &BehaviorTree{ID: def.ID, Root: root}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	BehaviorTreeRegistry = registry
+	return nil
+}
+
+// buildBehaviorNode はノードの定義から BehaviorNode を組み立てます。path はエラーの位置を示すためのノードの経路です。
+func buildBehaviorNode(def core.BehaviorNodeDefinition, path string) (BehaviorNode, error) {
+	switch def.Type {
+	case core.BehaviorNodeSelector, core.BehaviorNodeSequence:
+		if len(def.Children) == 0 {
+			return nil, fmt.Errorf("%s: %s には子ノードが必要です", path, def.Type)
+		}
+		var children []BehaviorNode
+		var errs []error
+		for i, childDef := range def.Children {
+			child, err := buildBehaviorNode(childDef, fmt.Sprintf("%s.Children[%d]", path, i))
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			children = append(children, child)
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		if def.Type == core.BehaviorNodeSelector {
+			return &SelectorNode{Children: children}, nil
+		}
+		return &SequenceNode{Children: children}, nil
+	case core.BehaviorNodeCondition:
+		if len(def.Children) > 0 {
+			return nil, fmt.Errorf("%s: condition に子ノードは指定できません", path)
+		}
+		newCondition, ok := behaviorConditionFactories[def.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: 条件 '%s' は存在しません", path, def.Name)
+		}
+		check, err := newCondition(def.Params)
+		if err != nil {
+			return nil, fmt.Errorf("%s: 条件 '%s' のパラメータが不正です: %w", path, def.Name, err)
+		}
+		return &ConditionNode{Check: check, Not: def.Not}, nil
+	case core.BehaviorNodeAction:
+		if len(def.Children) > 0 {
+			return nil, fmt.Errorf("%s: action に子ノードは指定できません", path)
+		}
+		if def.Not {
+			return nil, fmt.Errorf("%s: Not は condition にのみ指定できます", path)
+		}
+		newAction, ok := behaviorActionFactories[def.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: 行動 '%s' は存在しません", path, def.Name)
+		}
+		plan, err := newAction(def.Params)
+		if err != nil {
+			return nil, fmt.Errorf("%s: 行動 '%s' のパラメータが不正です: %w", path, def.Name, err)
+		}
+		return &ActionNode{Name: def.Name, Plan: plan}, nil
+	default:
+		return nil, fmt.Errorf("%s: ノードの種類 '%s' は存在しません", path, def.Type)
+	}
+}
+
+// behaviorTreeFor は機体が行動を決めるビヘイビアツリーを返します。ツリーを持たない機体は nil を返します。
+func behaviorTreeFor(entry *donburi.Entry) *BehaviorTree {
+	if !entry.HasComponent(component.AIComponent) {
+		return nil
+	}
+	treeID := component.AIComponent.Get(entry).BehaviorTreeID
+	if treeID == "" {
+		return nil
+	}
+	tree, ok := BehaviorTreeRegistry[treeID]
+	if !ok {
+		log.Printf("%s: AIエラー - ビヘイビアツリー '%s' がレジストリに見つかりません。性格に従って行動します。", component.SettingsComponent.Get(entry).Name, treeID)
+		return nil
+	}
+	return tree
+}
+
+// --- 条件 ---
+
+// validateRatio は割合のパラメータが 0〜1 の範囲かを検証します。
+func validateRatio(name string, ratio float64) error {
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("%s %.2f は 0〜1 の範囲で指定してください", name, ratio)
+	}
+	return nil
+}
+
+// partArmorRatio は機体のパーツの装甲の割合を返します。パーツがない・破壊されている場合は false を返します。
+func partArmorRatio(entry *donburi.Entry, slot core.PartSlotKey, partInfoProvider PartInfoProviderInterface) (float64, bool) {
+	partInst := component.PartsComponent.Get(entry).Map[slot]
+	if partInst == nil || partInst.IsBroken {
+		return 0, false
+	}
+	partDef, found := partInfoProvider.GetGameDataManager().GetPartDefinition(partInst.DefinitionID)
+	if !found || partDef.MaxArmor <= 0 {
+		return 0, false
+	}
+	return float64(partInst.CurrentArmor) / float64(partDef.MaxArmor), true
+}
+
+// newLeaderHeadArmorBelowCondition は、自チームのリーダーの頭部の装甲の割合が Ratio 以下かを判定します。
+func newLeaderHeadArmorBelowCondition(params json.RawMessage) (BehaviorConditionFunc, error) {
+	p, err := decodeStrategy(params, &struct{ Ratio float64 }{})
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRatio("Ratio", p.Ratio); err != nil {
+		return nil, err
+	}
+	return func(ctx *BehaviorContext) bool {
+		leader := entity.FindLeader(ctx.World, component.SettingsComponent.Get(ctx.Entry).Team)
+		if leader == nil {
+			return false
+		}
+		ratio, ok := partArmorRatio(leader, core.PartSlotHead, ctx.PartInfoProvider)
+		return ok && ratio <= p.Ratio
+	}, nil
+}
+
+// newSelfArmorBelowCondition は、自分のパーツ（Slot、省略時は頭部）の装甲の割合が Ratio 以下かを判定します。
+func newSelfArmorBelowCondition(params json.RawMessage) (BehaviorConditionFunc, error) {
+	p, err := decodeStrategy(params, &struct {
+		Slot  core.PartSlotKey
+		Ratio float64
+	}{Slot: core.PartSlotHead})
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRatio("Ratio", p.Ratio); err != nil {
+		return nil, err
+	}
+	switch p.Slot {
+	case core.PartSlotHead, core.PartSlotRightArm, core.PartSlotLeftArm, core.PartSlotLegs:
+	default:
+		return nil, fmt.Errorf("Slot '%s' は存在しません", p.Slot)
+	}
+	return func(ctx *BehaviorContext) bool {
+		ratio, ok := partArmorRatio(ctx.Entry, p.Slot, ctx.PartInfoProvider)
+		return ok && ratio <= p.Ratio
+	}, nil
+}
+
+// newAllyArmorBelowCondition は、味方（自分を含む）に装甲の割合が Ratio 以下のパーツがあるかを判定します。
+func newAllyArmorBelowCondition(params json.RawMessage) (BehaviorConditionFunc, error) {
+	p, err := decodeStrategy(params, &struct{ Ratio float64 }{})
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRatio("Ratio", p.Ratio); err != nil {
+		return nil, err
+	}
+	return func(ctx *BehaviorContext) bool {
+		ally, _ := FindMostDamagedAllyPart(ctx.Entry, ctx.TargetSelector, ctx.PartInfoProvider, p.Ratio)
+		return ally != nil
+	}, nil
+}
+
+// partFilter はパーツのカテゴリと特性による絞り込みです。空の項目は絞り込みません。
+type partFilter struct {
+	Category core.PartCategory
+	Trait    core.Trait
+}
+
+func (f *partFilter) matches(partDef *core.PartDefinition) bool {
+	return (f.Category == "" || partDef.Category == f.Category) && (f.Trait == "" || partDef.Trait == f.Trait)
+}
+
+func (f *partFilter) validate() error {
+	switch f.Category {
+	case "", core.CategoryRanged, core.CategoryMelee, core.CategoryIntervention:
+		return nil
+	default:
+		return fmt.Errorf("Category '%s' は存在しません", f.Category)
+	}
+}
+
+// newEnemyChargingCondition は、Category・Trait に合うパーツでチャージ中の敵がいるかを判定します。
+func newEnemyChargingCondition(params json.RawMessage) (BehaviorConditionFunc, error) {
+	p, err := decodeStrategy(params, &partFilter{})
+	if err != nil {
+		return nil, err
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return func(ctx *BehaviorContext) bool {
+		gdm := ctx.PartInfoProvider.GetGameDataManager()
+		for _, enemy := range ctx.TargetSelector.GetTargetableEnemies(ctx.Entry) {
+			if component.StateComponent.Get(enemy).CurrentState != core.StateCharging {
+				continue
+			}
+			partInst := component.PartsComponent.Get(enemy).Map[component.ActionIntentComponent.Get(enemy).SelectedPartKey]
+			if partInst == nil {
+				continue
+			}
+			if partDef, found := gdm.GetPartDefinition(partInst.DefinitionID); found && p.matches(partDef) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// newHasPartCondition は、Category・Trait に合う使用可能なパーツを持っているかを判定します。
+func newHasPartCondition(params json.RawMessage) (BehaviorConditionFunc, error) {
+	p, err := decodeStrategy(params, &partFilter{})
+	if err != nil {
+		return nil, err
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return func(ctx *BehaviorContext) bool {
+		for _, available := range ctx.AvailableParts {
+			if p.matches(available.PartDef) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// newChanceCondition は Probability の確率で成功します。
+func newChanceCondition(params json.RawMessage) (BehaviorConditionFunc, error) {
+	p, err := decodeStrategy(params, &struct{ Probability float64 }{})
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRatio("Probability", p.Probability); err != nil {
+		return nil, err
+	}
+	return func(ctx *BehaviorContext) bool {
+		return ctx.Rand.Float64() < p.Probability
+	}, nil
+}
+
+// --- 行動 ---
+
+// repairTargetLeaderHead は、use_part の RepairTarget で自チームのリーダーの頭部を修復することを指定します。
+const repairTargetLeaderHead = "leader_head"
+
+// newUsePartAction は、Category・Trait に合うパーツを PartSelection（省略時は highest_power）で選び、
+// Targeting（省略時は hunter）で選んだターゲットに使います。
+// 介入パーツは、機体の性格の Support のしきい値で使う意味があると判断した場合だけ使い、戦況から決まるターゲット（修復する味方・妨害する敵など）を優先します。
+// RepairTarget に leader_head を指定すると、修復パーツはしきい値によらず自チームのリーダーの頭部を修復します。
+func newUsePartAction(params json.RawMessage) (BehaviorActionFunc, error) {
+	p, err := decodeStrategy(params, &struct {
+		partFilter
+		PartSelection string
+		Targeting     *core.StrategyRef
+		RepairTarget  string
+	}{PartSelection: "highest_power"})
+	if err != nil {
+		return nil, err
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if p.RepairTarget != "" && p.RepairTarget != repairTargetLeaderHead {
+		return nil, fmt.Errorf("RepairTarget '%s' は存在しません", p.RepairTarget)
+	}
+	selectPart, ok := partSelectionStrategies[p.PartSelection]
+	if !ok {
+		return nil, fmt.Errorf("パーツ選択戦略 '%s' は存在しません", p.PartSelection)
+	}
+	targetingRef := core.StrategyRef{Name: "hunter"}
+	if p.Targeting != nil {
+		targetingRef = *p.Targeting
+	}
+	newStrategy, ok := targetingStrategyFactories[targetingRef.Name]
+	if !ok {
+		return nil, fmt.Errorf("ターゲット選択戦略 '%s' は存在しません", targetingRef.Name)
+	}
+	targeting, err := newStrategy(targetingRef.Params)
+	if err != nil {
+		return nil, fmt.Errorf("ターゲット選択戦略 '%s' のパラメータが不正です: %w", targetingRef.Name, err)
+	}
+
+	return func(ctx *BehaviorContext) (AIActionPlan, bool) {
+		var candidates []core.AvailablePart
+		for _, available := range ctx.AvailableParts {
+			if p.matches(available.PartDef) {
+				candidates = append(candidates, available)
+			}
+		}
+		if len(candidates) == 0 {
+			return AIActionPlan{}, false
+		}
+		slot, partDef := selectPart(ctx.Entry, candidates)
+		if partDef == nil {
+			return AIActionPlan{}, false
+		}
+
+		plan := AIActionPlan{Slot: slot, PartDef: partDef}
+		switch partDef.Category {
+		case core.CategoryMelee:
+			return plan, true
+		case core.CategoryIntervention:
+			if partDef.Trait == core.TraitRepair && p.RepairTarget == repairTargetLeaderHead {
+				leader := entity.FindLeader(ctx.World, component.SettingsComponent.Get(ctx.Entry).Team)
+				if leader == nil {
+					return AIActionPlan{}, false
+				}
+				if ratio, ok := partArmorRatio(leader, core.PartSlotHead, ctx.PartInfoProvider); !ok || ratio >= 1 {
+					return AIActionPlan{}, false
+				}
+				plan.TargetEntry, plan.TargetPartSlot = leader, core.PartSlotHead
+				return plan, true
+			}
+			target, slot, ok := judgeIntervention(ctx.World, ctx.Entry, partDef, aiPersonalityFor(ctx.Entry).Support, ctx.TargetSelector, ctx.PartInfoProvider)
+			if !ok {
+				return AIActionPlan{}, false
+			}
+			plan.TargetEntry, plan.TargetPartSlot = target, slot
+		}
+		if plan.TargetEntry == nil {
+			plan.TargetEntry, plan.TargetPartSlot = targeting.SelectTarget(ctx.World, ctx.Entry, ctx.TargetSelector, ctx.PartInfoProvider, ctx.Rand)
+		}
+		return plan, plan.TargetEntry != nil
+	}, nil
+}
+
+// newPersonalityAction は、ID の性格の戦略に従って行動を決めます。性格の行動には難易度による補正がかかります。
+func newPersonalityAction(params json.RawMessage) (BehaviorActionFunc, error) {
+	p, err := decodeStrategy(params, &struct{ ID string }{})
+	if err != nil {
+		return nil, err
+	}
+	personality, ok := PersonalityRegistry[p.ID]
+	if !ok {
+		return nil, fmt.Errorf("性格 '%s' が定義されていません", p.ID)
+	}
+	// 先読みAIのシミュレーションの中でもツリーは評価されるため、先読みAIを呼ぶと探索が入れ子になります。
+	if _, ok := personality.ActionPlanner.(*LookaheadPlanner); ok {
+		return nil, fmt.Errorf("先読みAIの性格 '%s' はビヘイビアツリーから使えません", p.ID)
+	}
+	return func(ctx *BehaviorContext) (AIActionPlan, bool) {
+		return planActionWithPersonality(ctx.World, ctx.Entry, PersonalityRegistry[p.ID], ctx.AvailableParts, ctx.PartInfoProvider,
+			ctx.TargetSelector, ctx.HitCalculator, ctx.DamageCalculator, ctx.GameConfig, ctx.Rand)
+	}, nil
+}
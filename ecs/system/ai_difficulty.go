@@ -0,0 +1,122 @@
+package system
+
+import (
+	"log"
+	"math/rand"
+	"sort"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+
+	"github.com/yohamta/donburi"
+)
+
+// aiDifficultySettings は機体の難易度の設定を返します。
+// AIでない機体や、設定にない難易度の機体は補正なしの設定を返します。
+func aiDifficultySettings(config *data.Config, entry *donburi.Entry) data.AIDifficultyConfig {
+	if entry == nil || !entry.HasComponent(component.AIComponent) {
+		return data.AIDifficultyConfig{}
+	}
+	return config.AIDifficulty.Levels[component.AIComponent.Get(entry).Difficulty]
+}
+
+// applyAIDifficulty は、性格の戦略が選んだ行動に難易度による補正を加えた行動を返します。
+// ExpectedValueCheck が有効な場合は期待値でより良い行動に差し替え、
+// MistakeRate の確率でランダムなパーツ・ターゲットに差し替えます。
+func applyAIDifficulty(
+	entry *donburi.Entry,
+	plan AIActionPlan,
+	availableParts []core.AvailablePart,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+	gameConfig *data.Config,
+	rand *rand.Rand,
+) AIActionPlan {
+	difficulty := aiDifficultySettings(gameConfig, entry)
+	if difficulty.ExpectedValueCheck {
+		plan = expectedValuePlan(entry, plan, availableParts, difficulty, targetSelector, partInfoProvider, hitCalculator, damageCalculator)
+	}
+	// 補正のない難易度では乱数を消費しないよう、確率が 0 より大きい場合だけ判定します。
+	if difficulty.MistakeRate > 0 && rand.Float64() < difficulty.MistakeRate {
+		plan = mistakenPlan(entry, plan, availableParts, targetSelector, partInfoProvider, rand)
+	}
+	return plan
+}
+
+// expectedValuePlan は、選ばれた行動と期待値が最も高い行動を比べ、
+// 期待値が SwitchThreshold 倍以上高ければその行動を返します。
+// 敵リーダーを狙う行動の期待値には LeaderFocusBonus を掛け、リーダーへの集中を優先します。
+func expectedValuePlan(
+	entry *donburi.Entry,
+	plan AIActionPlan,
+	availableParts []core.AvailablePart,
+	difficulty data.AIDifficultyConfig,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+) AIActionPlan {
+	weights := DefaultUtilityWeights
+	weights.LeaderFocus = difficulty.LeaderFocusBonus
+	evaluator := &UtilityPlanner{Weights: weights}
+
+	// 介入など期待値で評価できない行動は、性格の判断を尊重してそのまま使います。
+	currentScore, ok := evaluator.evaluatePlan(entry, plan, targetSelector, partInfoProvider, hitCalculator, damageCalculator)
+	if !ok {
+		return plan
+	}
+	bestPlan, bestScore, ok := evaluator.bestPlan(entry, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator)
+	if !ok || bestScore <= currentScore*max(difficulty.SwitchThreshold, 1.0) {
+		return plan
+	}
+
+	log.Printf("%s: AIは難易度の補正により %s（評価値 %.2f）から %s（評価値 %.2f）に行動を変更。",
+		component.SettingsComponent.Get(entry).Name, plan.PartDef.PartName, currentScore, bestPlan.PartDef.PartName, bestScore)
+	return bestPlan
+}
+
+// mistakenPlan は、利用可能な攻撃パーツからランダムに選んだパーツと、ランダムに選んだ敵パーツの行動を返します。
+// 射撃で狙える敵パーツがない場合や、射撃・格闘のパーツがない場合は元の行動を返します。
+func mistakenPlan(
+	entry *donburi.Entry,
+	plan AIActionPlan,
+	availableParts []core.AvailablePart,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	rand *rand.Rand,
+) AIActionPlan {
+	var attackParts []core.AvailablePart
+	for _, available := range availableParts {
+		if available.PartDef.Category == core.CategoryRanged || available.PartDef.Category == core.CategoryMelee {
+			attackParts = append(attackParts, available)
+		}
+	}
+	if len(attackParts) == 0 {
+		return plan
+	}
+
+	available := attackParts[rand.Intn(len(attackParts))]
+	mistaken := AIActionPlan{Slot: available.Slot, PartDef: available.PartDef}
+	if available.PartDef.Category == core.CategoryRanged {
+		targetParts := getAllTargetableParts(entry, targetSelector, partInfoProvider, true)
+		if len(targetParts) == 0 {
+			return plan
+		}
+		// パーツの一覧はマップから作られるため、同じ乱数で同じ結果になるよう並べ替えてから選びます。
+		sort.Slice(targetParts, func(i, j int) bool {
+			if targetParts[i].Entity != targetParts[j].Entity {
+				return targetParts[i].Entity.Entity().Id() < targetParts[j].Entity.Entity().Id()
+			}
+			return targetParts[i].Slot < targetParts[j].Slot
+		})
+		targetPart := targetParts[rand.Intn(len(targetParts))]
+		mistaken.TargetEntry = targetPart.Entity
+		mistaken.TargetPartSlot = targetPart.Slot
+	}
+
+	log.Printf("%s: AIは難易度の補正により %s を選択。", component.SettingsComponent.Get(entry).Name, mistaken.PartDef.PartName)
+	return mistaken
+}
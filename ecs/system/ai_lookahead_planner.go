@@ -0,0 +1,323 @@
+package system
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"time"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// LookaheadPlanner は、戦闘ワールドを複製して候補の行動を実際のシステムでシミュレーションし、
+// モンテカルロ木探索（UCB1）で最も成績の良い行動を選ぶ先読みAIです。
+// 木として展開するのは行動する機体自身の選択だけで、他の機体はシミュレーションの中で各自の性格に従って行動します（open-loop）。
+// シミュレーションごとに専用の乱数を使うため、探索によって戦闘の乱数が消費されることはありません。
+type LookaheadPlanner struct {
+	// RolloutPlanner はシミュレーションの中でこの性格の機体が使う行動計画です。探索を行えない場合にも使います。
+	RolloutPlanner AIActionPlanner
+}
+
+// lookaheadAction は探索木の1手です。エンティティは複製したワールドのもので、複製ごとに同じ値になります。
+type lookaheadAction struct {
+	Slot           core.PartSlotKey
+	TargetEntity   donburi.Entity
+	TargetPartSlot core.PartSlotKey
+}
+
+// lookaheadNode は探索木のノードです。
+type lookaheadNode struct {
+	visits   int
+	total    float64
+	children map[lookaheadAction]*lookaheadNode
+}
+
+// PlanAction は AIActionPlanner インターフェースを実装します。
+// 設定の Lookahead で探索の回数と時間の上限を決め、ルートで最も多く試された行動を選びます。
+func (p *LookaheadPlanner) PlanAction(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	availableParts []core.AvailablePart,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+	gameConfig *data.Config,
+) (AIActionPlan, bool) {
+	settings := gameConfig.Lookahead
+	if settings.Iterations <= 0 && settings.TimeBudgetMillis <= 0 {
+		return p.fallback(world, actingEntry, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator, gameConfig)
+	}
+
+	var root *lookaheadNode
+	var entities map[donburi.Entity]donburi.Entity
+	var iterations int
+	withLogsSilenced(func() {
+		root, entities, iterations = p.search(world, actingEntry, partInfoProvider.GetGameDataManager(), gameConfig)
+	})
+
+	bestAction, bestNode := root.mostVisitedChild()
+	if bestNode == nil {
+		return p.fallback(world, actingEntry, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator, gameConfig)
+	}
+
+	var plan AIActionPlan
+	for _, available := range availableParts {
+		if available.Slot == bestAction.Slot {
+			plan = AIActionPlan{Slot: available.Slot, PartDef: available.PartDef}
+			break
+		}
+	}
+	if plan.PartDef == nil {
+		return p.fallback(world, actingEntry, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator, gameConfig)
+	}
+	// 複製したワールドのエンティティを元のワールドのものに戻します。
+	targetName := "（実行時に決定）"
+	if bestAction.TargetEntity != donburi.Null {
+		for srcEntity, dstEntity := range entities {
+			if dstEntity == bestAction.TargetEntity {
+				plan.TargetEntry = world.Entry(srcEntity)
+				plan.TargetPartSlot = bestAction.TargetPartSlot
+				break
+			}
+		}
+		if plan.TargetEntry == nil || !plan.TargetEntry.Valid() {
+			return p.fallback(world, actingEntry, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator, gameConfig)
+		}
+		targetName = component.SettingsComponent.Get(plan.TargetEntry).Name + " " + string(plan.TargetPartSlot)
+	}
+
+	log.Printf("%s: AIは %d 回の先読みの結果、勝率 %.2f で %s を選択（ターゲット: %s）。",
+		component.SettingsComponent.Get(actingEntry).Name, iterations, bestNode.total/float64(bestNode.visits), plan.PartDef.PartName, targetName)
+	return plan, true
+}
+
+// search は探索の上限に達するまでシミュレーションを繰り返し、探索木のルートと、最初の複製でのエンティティの対応、シミュレーションの回数を返します。
+func (p *LookaheadPlanner) search(world donburi.World, actingEntry *donburi.Entry, gdm *data.GameDataManager, gameConfig *data.Config) (*lookaheadNode, map[donburi.Entity]donburi.Entity, int) {
+	settings := gameConfig.Lookahead
+	root := &lookaheadNode{children: make(map[lookaheadAction]*lookaheadNode)}
+	team := component.SettingsComponent.Get(actingEntry).Team
+	seed := lookaheadSeed(world, actingEntry)
+	deadline := time.Now().Add(time.Duration(settings.TimeBudgetMillis) * time.Millisecond)
+
+	var rootEntities map[donburi.Entity]donburi.Entity
+	iterations := 0
+	for ; settings.Iterations <= 0 || iterations < settings.Iterations; iterations++ {
+		if settings.TimeBudgetMillis > 0 && iterations > 0 && time.Now().After(deadline) {
+			break
+		}
+
+		clone, entities := entity.CloneWorld(world)
+		if rootEntities == nil {
+			rootEntities = entities
+		}
+		sim := NewBattleSimulator(clone, gameConfig, gdm, rand.New(rand.NewSource(seed+int64(iterations))))
+		actingClone := clone.Entry(entities[actingEntry.Entity()])
+
+		// 行動する機体が行動を選ぶたびに、探索木をたどって1手ずつ選びます。
+		node := root
+		path := []*lookaheadNode{root}
+		sim.decide = func(entry *donburi.Entry) bool {
+			if entry != actingClone || len(path) > settings.MaxDepth {
+				return false
+			}
+			// チャージを開始できなかった手は、探索木に残さず候補から外して選び直します。
+			actions := sim.lookaheadActions(entry)
+			for len(actions) > 0 {
+				action := node.selectChild(actions, settings.Exploration)
+				if sim.startLookaheadAction(entry, action) {
+					node = node.children[action]
+					path = append(path, node)
+					return true
+				}
+				if node.children[action].visits == 0 {
+					delete(node.children, action)
+				}
+				actions = slices.DeleteFunc(actions, func(candidate lookaheadAction) bool { return candidate == action })
+			}
+			return false
+		}
+
+		// 元のワールドは行動選択の途中なので、このティックの残りを進めてからシミュレーションします。
+		result := sim.finishTick()
+		if !result.IsGameOver && !result.IsRoundOver {
+			result = sim.Run(settings.HorizonTicks)
+		}
+		value := evaluateLookahead(clone, gdm, team, result)
+		for _, visited := range path {
+			visited.visits++
+			visited.total += value
+		}
+	}
+	return root, rootEntities, iterations
+}
+
+// fallback は探索を行えない場合に RolloutPlanner で行動を決めます。
+func (p *LookaheadPlanner) fallback(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	availableParts []core.AvailablePart,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+	gameConfig *data.Config,
+) (AIActionPlan, bool) {
+	if p.RolloutPlanner == nil {
+		return AIActionPlan{}, false
+	}
+	return p.RolloutPlanner.PlanAction(world, actingEntry, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator, gameConfig)
+}
+
+// lookaheadActions は機体が選べる行動の候補を、決まった順序で返します。
+// 射撃は狙える敵パーツごとに1手、格闘は実行時にターゲットが決まるため1手とし、介入パーツは候補にしません。
+func (s *BattleSimulator) lookaheadActions(entry *donburi.Entry) []lookaheadAction {
+	var actions []lookaheadAction
+	for _, available := range s.targetSelector.FilterReachableParts(entry, s.partInfoProvider.GetAvailableAttackParts(entry)) {
+		switch available.PartDef.Category {
+		case core.CategoryRanged:
+			for _, targetPart := range getAllTargetableParts(entry, s.targetSelector, s.partInfoProvider, true) {
+				actions = append(actions, lookaheadAction{Slot: available.Slot, TargetEntity: targetPart.Entity.Entity(), TargetPartSlot: targetPart.Slot})
+			}
+		case core.CategoryMelee:
+			if s.targetSelector.FindClosestEnemy(entry) != nil {
+				actions = append(actions, lookaheadAction{Slot: available.Slot, TargetEntity: donburi.Null})
+			}
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		if actions[i].Slot != actions[j].Slot {
+			return actions[i].Slot < actions[j].Slot
+		}
+		if actions[i].TargetEntity != actions[j].TargetEntity {
+			return actions[i].TargetEntity.Id() < actions[j].TargetEntity.Id()
+		}
+		return actions[i].TargetPartSlot < actions[j].TargetPartSlot
+	})
+	return actions
+}
+
+// startLookaheadAction は探索木で選んだ1手のチャージを開始します。チャージを開始できなかった場合は false を返します。
+func (s *BattleSimulator) startLookaheadAction(entry *donburi.Entry, action lookaheadAction) bool {
+	var targetEntry *donburi.Entry
+	if action.TargetEntity != donburi.Null {
+		targetEntry = s.world.Entry(action.TargetEntity)
+	}
+	return s.chargeInitiationSystem.StartCharge(entry, action.Slot, targetEntry, action.TargetPartSlot)
+}
+
+// selectChild は候補の中から次に試す手を選びます。まだ試していない手があれば候補の順に選び、
+// すべて試していればUCB1の値が最も高い手を選びます。
+func (n *lookaheadNode) selectChild(actions []lookaheadAction, exploration float64) lookaheadAction {
+	for _, action := range actions {
+		if child, ok := n.children[action]; !ok || child.visits == 0 {
+			if !ok {
+				n.children[action] = &lookaheadNode{children: make(map[lookaheadAction]*lookaheadNode)}
+			}
+			return action
+		}
+	}
+
+	// 候補はその時点で選べる手に限るため、親の試行回数は候補の試行回数の合計を使います。
+	parentVisits := 0
+	for _, action := range actions {
+		parentVisits += n.children[action].visits
+	}
+	bestAction := actions[0]
+	bestScore := math.Inf(-1)
+	for _, action := range actions {
+		child := n.children[action]
+		score := child.total/float64(child.visits) + exploration*math.Sqrt(math.Log(float64(parentVisits))/float64(child.visits))
+		if score > bestScore {
+			bestScore = score
+			bestAction = action
+		}
+	}
+	return bestAction
+}
+
+// mostVisitedChild は最も多く試された子ノードとその手を返します。子ノードがない場合は nil を返します。
+// 試行回数が同じ場合は平均の評価が高い方を選びます。
+func (n *lookaheadNode) mostVisitedChild() (lookaheadAction, *lookaheadNode) {
+	var bestAction lookaheadAction
+	var bestNode *lookaheadNode
+	for action, child := range n.children {
+		if child.visits == 0 {
+			continue
+		}
+		if bestNode == nil || child.visits > bestNode.visits ||
+			(child.visits == bestNode.visits && child.total/float64(child.visits) > bestNode.total/float64(bestNode.visits)) {
+			bestAction, bestNode = action, child
+		}
+	}
+	return bestAction, bestNode
+}
+
+// lookaheadSeed は探索に使う乱数の種を、戦闘の進行状況と行動する機体から求めます。
+// 戦闘の乱数を使わないため、探索の有無によって戦闘の乱数の並びが変わることはありません。
+func lookaheadSeed(world donburi.World, actingEntry *donburi.Entry) int64 {
+	actionSeq := int64(entity.GetActionQueueComponent(world).ActionSeq)
+	elapsedTicks := int64(entity.GetVictoryStateComponent(world).ElapsedTicks)
+	return actionSeq*1_000_003 + elapsedTicks*7_919 + int64(actingEntry.Entity().Id())*104_729
+}
+
+// evaluateLookahead はシミュレーションの結果を、team から見た 0.0〜1.0 の評価値にします。
+// 決着がついた場合は勝ちを 1.0、負けを 0.0、引き分けを 0.5 とし、
+// 決着がつかなかった場合は両チームの残り装甲の割合の差から評価します。
+func evaluateLookahead(world donburi.World, gdm *data.GameDataManager, team core.TeamID, result core.GameEndResult) float64 {
+	if result.IsGameOver || result.IsRoundOver {
+		switch result.Winner {
+		case team:
+			return 1.0
+		case core.TeamNone:
+			return 0.5
+		default:
+			return 0.0
+		}
+	}
+
+	ownRatio, enemyRatio := lookaheadArmorRatios(world, gdm, team)
+	return 0.5 + 0.5*(ownRatio-enemyRatio)
+}
+
+// lookaheadArmorRatios は、team と敵チームそれぞれの残り装甲の割合を返します。機能停止した機体の装甲は 0 として数えます。
+func lookaheadArmorRatios(world donburi.World, gdm *data.GameDataManager, team core.TeamID) (float64, float64) {
+	var ownArmor, ownMax, enemyArmor, enemyMax float64
+	query.NewQuery(filter.Contains(component.SettingsComponent, component.PartsComponent, component.StateComponent)).Each(world, func(entry *donburi.Entry) {
+		broken := component.StateComponent.Get(entry).CurrentState == core.StateBroken
+		armor, maxArmor := 0.0, 0.0
+		for _, partInst := range component.PartsComponent.Get(entry).Map {
+			if partInst == nil {
+				continue
+			}
+			if partDef, ok := gdm.GetPartDefinition(partInst.DefinitionID); ok {
+				maxArmor += float64(partDef.MaxArmor)
+			}
+			if !broken && !partInst.IsBroken {
+				armor += float64(partInst.CurrentArmor)
+			}
+		}
+		if component.SettingsComponent.Get(entry).Team == team {
+			ownArmor, ownMax = ownArmor+armor, ownMax+maxArmor
+		} else {
+			enemyArmor, enemyMax = enemyArmor+armor, enemyMax+maxArmor
+		}
+	})
+
+	ratio := func(armor, maxArmor float64) float64 {
+		if maxArmor <= 0 {
+			return 0
+		}
+		return armor / maxArmor
+	}
+	return ratio(ownArmor, ownMax), ratio(enemyArmor, enemyMax)
+}
@@ -1,7 +1,16 @@
 package system
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
 	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
 
 	"github.com/yohamta/donburi"
 )
@@ -14,56 +23,249 @@ type AIPartSelectionStrategyFunc func(
 	availableParts []core.AvailablePart,
 ) (core.PartSlotKey, *core.PartDefinition)
 
+// PartToDamageRuleFunc は、攻撃する敵パーツが実行時に決まる場合（格闘など）に、破壊されていないパーツから1つを選ぶルールです。
+type PartToDamageRuleFunc func(parts []*core.PartInstanceData, rand *rand.Rand) *core.PartInstanceData
+
 // AIPersonality はAIの性格に関連する戦略をカプセル化します。
+// ActionPlanner が設定されている場合は、パーツとターゲットをまとめて決めます。
+// TargetingStrategy と PartSelectionStrategy は、計画を立てられなかった場合やプレイヤー機体のターゲット提案に使われます。
 type AIPersonality struct {
 	TargetingStrategy     TargetingStrategy
 	PartSelectionStrategy AIPartSelectionStrategyFunc
+	ActionPlanner         AIActionPlanner
+	PartToDamage          PartToDamageRuleFunc
+	SupportUsage          float64           // 介入パーツを使う意味がある場合に、選んだパーツの代わりに介入パーツを使う確率
+	Support               SupportThresholds // 介入パーツを使う意味があるかを判断するしきい値
 }
 
 // PersonalityRegistry は、性格名をキーとしてAIPersonalityを保持するグローバルなマップです。
-var PersonalityRegistry = map[string]AIPersonality{
-	"ハンター": {
-		TargetingStrategy:     &HunterStrategy{},
-		PartSelectionStrategy: SelectHighestPowerPart,
-	},
-	"クラッシャー": {
-		TargetingStrategy:     &CrusherStrategy{},
-		PartSelectionStrategy: SelectHighestPowerPart,
-	},
-	"ジョーカー": {
-		TargetingStrategy:     &JokerStrategy{},
-		PartSelectionStrategy: SelectFastestChargePart,
-	},
-	"リーダー": { // デフォルト/フォールバック用
-		TargetingStrategy:     &LeaderStrategy{},
-		PartSelectionStrategy: SelectFirstAvailablePart,
-	},
-	"アシスト": {
-		TargetingStrategy:     &AssistStrategy{},
-		PartSelectionStrategy: SelectFirstAvailablePart,
-	},
-	"カウンター": {
-		TargetingStrategy:     &CounterStrategy{},
-		PartSelectionStrategy: SelectFirstAvailablePart,
-	},
-	"チェイス": {
-		TargetingStrategy:     &ChaseStrategy{},
-		PartSelectionStrategy: SelectFirstAvailablePart,
-	},
-	"デュエル": {
-		TargetingStrategy:     &DuelStrategy{},
-		PartSelectionStrategy: SelectFirstAvailablePart,
-	},
-	"フォーカス": {
-		TargetingStrategy:     &FocusStrategy{},
-		PartSelectionStrategy: SelectFirstAvailablePart,
-	},
-	"ガード": {
-		TargetingStrategy:     &GuardStrategy{},
-		PartSelectionStrategy: SelectFirstAvailablePart,
-	},
-	"インターセプト": {
-		TargetingStrategy:     &InterceptStrategy{},
-		PartSelectionStrategy: SelectFirstAvailablePart,
-	},
-}
\ No newline at end of file
+// 起動時に InitPersonalityRegistry で personalities.json の定義から組み立てられます。
+var PersonalityRegistry = map[string]AIPersonality{}
+
+// fallbackPersonalityID は性格が見つからない場合に使う性格の名前です。
+const fallbackPersonalityID = "リーダー"
+
+// PersonalityIDFor は機体が行動に使う性格の名前を返します。
+// AIコンポーネントを持つ機体（敵のAI、オートバトル中のプレイヤー機体、トーナメントの参加者）はその性格を、それ以外はメダルの性格を使います。
+func PersonalityIDFor(entry *donburi.Entry) string {
+	if entry.HasComponent(component.AIComponent) {
+		if personalityID := component.AIComponent.Get(entry).PersonalityID; personalityID != "" {
+			return personalityID
+		}
+	}
+	if entry.HasComponent(component.MedalComponent) {
+		return component.MedalComponent.Get(entry).Personality
+	}
+	return ""
+}
+
+// targetingStrategyFactories は、personalities.json で指定できるターゲット選択戦略の名前と、パラメータから戦略を生成する関数です。
+var targetingStrategyFactories = map[string]func(params json.RawMessage) (TargetingStrategy, error){
+	"hunter":    func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &HunterStrategy{}) },
+	"crusher":   func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &CrusherStrategy{}) },
+	"joker":     func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &JokerStrategy{}) },
+	"leader":    func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &LeaderStrategy{}) },
+	"assist":    func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &AssistStrategy{}) },
+	"counter":   func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &CounterStrategy{}) },
+	"chase":     func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &ChaseStrategy{}) },
+	"duel":      func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &DuelStrategy{}) },
+	"focus":     func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &FocusStrategy{}) },
+	"guard":     func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &GuardStrategy{}) },
+	"intercept": func(params json.RawMessage) (TargetingStrategy, error) { return decodeStrategy(params, &InterceptStrategy{}) },
+}
+
+// "team" は代わりの戦略を targetingStrategyFactories から生成するため、初期化の循環を避けて init で登録します。
+func init() {
+	targetingStrategyFactories["team"] = newTeamIntentStrategy
+}
+
+// newTeamIntentStrategy はチームの指示に従う戦略を生成します。
+// パラメータの Fallback で指示がない場合の戦略を指定でき、省略した場合は hunter を使います。
+func newTeamIntentStrategy(params json.RawMessage) (TargetingStrategy, error) {
+	var p struct {
+		Fallback *core.StrategyRef
+	}
+	if _, err := decodeStrategy(params, &p); err != nil {
+		return nil, err
+	}
+	fallback := core.StrategyRef{Name: "hunter"}
+	if p.Fallback != nil {
+		fallback = *p.Fallback
+	}
+	if fallback.Name == "team" {
+		return nil, fmt.Errorf("代わりの戦略に 'team' は使えません")
+	}
+	newFallback, ok := targetingStrategyFactories[fallback.Name]
+	if !ok {
+		return nil, fmt.Errorf("代わりの戦略 '%s' は存在しません", fallback.Name)
+	}
+	strategy, err := newFallback(fallback.Params)
+	if err != nil {
+		return nil, fmt.Errorf("代わりの戦略 '%s' のパラメータが不正です: %w", fallback.Name, err)
+	}
+	return &TeamIntentStrategy{Fallback: strategy}, nil
+}
+
+// partSelectionStrategies は、personalities.json で指定できるパーツ選択戦略の名前です。
+var partSelectionStrategies = map[string]AIPartSelectionStrategyFunc{
+	"first_available": SelectFirstAvailablePart,
+	"highest_power":   SelectHighestPowerPart,
+	"fastest_charge":  SelectFastestChargePart,
+}
+
+// partToDamageRules は、personalities.json で指定できる、攻撃する敵パーツを実行時に決めるルールの名前です。
+var partToDamageRules = map[string]PartToDamageRuleFunc{
+	"highest_armor": SelectHighestArmorPartToDamage,
+	"lowest_armor":  SelectLowestArmorPartToDamage,
+	"random":        SelectRandomPartToDamage,
+}
+
+// InitPersonalityRegistry は、ゲームデータに読み込まれた性格の定義から PersonalityRegistry を組み立てます。
+// 存在しない戦略・ルールの名前や不正なパラメータ、フォールバックの性格の欠落、
+// メダルが参照している性格の欠落はすべてまとめてエラーとして返し、その場合 PersonalityRegistry は変更しません。
+func InitPersonalityRegistry(gdm *data.GameDataManager) error {
+	registry := make(map[string]AIPersonality)
+	var errs []error
+	for _, def := range gdm.GetAllPersonalityDefinitions() {
+		personality, err := buildPersonality(def)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("性格 %s: %w", def.ID, err))
+			continue
+		}
+		registry[def.ID] = personality
+	}
+
+	if _, ok := registry[fallbackPersonalityID]; !ok {
+		errs = append(errs, fmt.Errorf("フォールバック用の性格 %s が定義されていません", fallbackPersonalityID))
+	}
+	for _, medal := range gdm.GetAllMedalDefinitions() {
+		if _, ok := registry[medal.Personality]; !ok {
+			errs = append(errs, fmt.Errorf("メダル %s (%s) の性格 %s が定義されていません", medal.ID, medal.Name, medal.Personality))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	PersonalityRegistry = registry
+	return nil
+}
+
+// buildPersonality は1つの性格の定義から AIPersonality を組み立てます。
+func buildPersonality(def *core.PersonalityDefinition) (AIPersonality, error) {
+	var personality AIPersonality
+
+	newStrategy, ok := targetingStrategyFactories[def.Targeting.Name]
+	if !ok {
+		return personality, fmt.Errorf("ターゲット選択戦略 '%s' は存在しません", def.Targeting.Name)
+	}
+	targeting, err := newStrategy(def.Targeting.Params)
+	if err != nil {
+		return personality, fmt.Errorf("ターゲット選択戦略 '%s' のパラメータが不正です: %w", def.Targeting.Name, err)
+	}
+	personality.TargetingStrategy = targeting
+
+	if personality.PartSelectionStrategy, ok = partSelectionStrategies[def.PartSelection]; !ok {
+		return personality, fmt.Errorf("パーツ選択戦略 '%s' は存在しません", def.PartSelection)
+	}
+	if personality.PartToDamage, ok = partToDamageRules[def.PartToDamage]; !ok {
+		return personality, fmt.Errorf("攻撃パーツの選択ルール '%s' は存在しません", def.PartToDamage)
+	}
+
+	if def.Planner != nil {
+		if personality.ActionPlanner, err = buildActionPlanner(*def.Planner, true); err != nil {
+			return personality, err
+		}
+	}
+
+	if def.Weights.SupportUsage < 0 || def.Weights.SupportUsage > 1 {
+		return personality, fmt.Errorf("SupportUsage %.2f は 0〜1 の範囲で指定してください", def.Weights.SupportUsage)
+	}
+	personality.SupportUsage = def.Weights.SupportUsage
+
+	personality.Support = DefaultSupportThresholds
+	if _, err := decodeStrategy(def.Support, &personality.Support); err != nil {
+		return personality, fmt.Errorf("Support のパラメータが不正です: %w", err)
+	}
+	if personality.Support.BuffRefreshTurns < 0 {
+		return personality, fmt.Errorf("Support.BuffRefreshTurns %d は 0 以上で指定してください", personality.Support.BuffRefreshTurns)
+	}
+	if personality.Support.ObstructChargeRatio < 0 || personality.Support.ObstructChargeRatio > 1 {
+		return personality, fmt.Errorf("Support.ObstructChargeRatio %.2f は 0〜1 の範囲で指定してください", personality.Support.ObstructChargeRatio)
+	}
+	if personality.Support.RepairArmorRatio < 0 || personality.Support.RepairArmorRatio > 1 {
+		return personality, fmt.Errorf("Support.RepairArmorRatio %.2f は 0〜1 の範囲で指定してください", personality.Support.RepairArmorRatio)
+	}
+	return personality, nil
+}
+
+// buildActionPlanner は名前とパラメータから行動計画を生成します。
+// 先読みAIはシミュレーションの中で代わりの行動計画を使うため、allowLookahead が false の場合は先読みAIを指定できません。
+func buildActionPlanner(ref core.StrategyRef, allowLookahead bool) (AIActionPlanner, error) {
+	switch ref.Name {
+	case "utility":
+		planner := &UtilityPlanner{Weights: DefaultUtilityWeights}
+		if _, err := decodeStrategy(ref.Params, planner); err != nil {
+			return nil, fmt.Errorf("行動計画 'utility' のパラメータが不正です: %w", err)
+		}
+		return planner, nil
+	case "lookahead":
+		if !allowLookahead {
+			return nil, fmt.Errorf("行動計画 'lookahead' の中で 'lookahead' は使えません")
+		}
+		var params struct {
+			Rollout *core.StrategyRef
+		}
+		if _, err := decodeStrategy(ref.Params, &params); err != nil {
+			return nil, fmt.Errorf("行動計画 'lookahead' のパラメータが不正です: %w", err)
+		}
+		planner := &LookaheadPlanner{}
+		if params.Rollout != nil {
+			rollout, err := buildActionPlanner(*params.Rollout, false)
+			if err != nil {
+				return nil, err
+			}
+			planner.RolloutPlanner = rollout
+		}
+		return planner, nil
+	default:
+		return nil, fmt.Errorf("行動計画 '%s' は存在しません", ref.Name)
+	}
+}
+
+// decodeStrategy は戦略のパラメータを target に読み込んで target を返します。パラメータが省略された場合は target をそのまま返します。
+// 戦略が持たないパラメータが指定された場合はエラーを返します。
+func decodeStrategy[T any](params json.RawMessage, target T) (T, error) {
+	if len(params) == 0 || string(params) == "null" {
+		return target, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+// --- 攻撃パーツの選択ルール ---
+
+// SelectHighestArmorPartToDamage は装甲の最も高いパーツを選びます。
+func SelectHighestArmorPartToDamage(parts []*core.PartInstanceData, rand *rand.Rand) *core.PartInstanceData {
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].CurrentArmor > parts[j].CurrentArmor
+	})
+	return parts[0]
+}
+
+// SelectLowestArmorPartToDamage は装甲の最も低いパーツを選びます。
+func SelectLowestArmorPartToDamage(parts []*core.PartInstanceData, rand *rand.Rand) *core.PartInstanceData {
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].CurrentArmor < parts[j].CurrentArmor
+	})
+	return parts[0]
+}
+
+// SelectRandomPartToDamage はランダムにパーツを選びます。
+func SelectRandomPartToDamage(parts []*core.PartInstanceData, rand *rand.Rand) *core.PartInstanceData {
+	return parts[rand.Intn(len(parts))]
+}
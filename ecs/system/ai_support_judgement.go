@@ -0,0 +1,248 @@
+package system
+
+import (
+	"log"
+	"math"
+	"math/rand"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// SupportThresholds は、介入パーツを使うかどうかを戦況から判断するためのしきい値です。
+// 性格ごとに personalities.json の Support で調整でき、省略した項目は DefaultSupportThresholds の値を使います。
+type SupportThresholds struct {
+	BuffRefreshTurns    int     // 同等以上のチームバフがかかっていても、残りの行動回数がこれ以下ならかけ直す
+	ObstructChargeRatio float64 // 敵のチャージがこの割合以上進んでいれば、行動の直前とみなして妨害する
+	RepairArmorRatio    float64 // 味方のパーツの装甲の割合がこれ以下になったら修復する
+}
+
+// DefaultSupportThresholds は性格で指定されなかった場合のしきい値です。
+var DefaultSupportThresholds = SupportThresholds{
+	BuffRefreshTurns:    1,
+	ObstructChargeRatio: 0.6,
+	RepairArmorRatio:    0.5,
+}
+
+// teamBuffTolerance はチームバフの効果量を比べるときに同等とみなす誤差です。
+const teamBuffTolerance = 1e-9
+
+// InterventionJudgeFunc は、介入パーツを今使う意味があるかを戦況から判断します。
+// 使う場合は true と、行動のターゲット（ターゲット選択戦略に任せる場合は nil）を返します。
+type InterventionJudgeFunc func(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	partDef *core.PartDefinition,
+	thresholds SupportThresholds,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+) (*donburi.Entry, core.PartSlotKey, bool)
+
+// interventionJudges は介入パーツの特性ごとの判断です。ここにない特性の介入パーツは、いつでも使う意味があるものとして扱います。
+var interventionJudges = map[core.Trait]InterventionJudgeFunc{
+	core.TraitSupport:  judgeSupport,
+	core.TraitObstruct: judgeObstruct,
+	core.TraitRepair:   judgeRepair,
+}
+
+// judgeIntervention は介入パーツを今使う意味があるかを判断します。
+func judgeIntervention(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	partDef *core.PartDefinition,
+	thresholds SupportThresholds,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+) (*donburi.Entry, core.PartSlotKey, bool) {
+	judge, ok := interventionJudges[partDef.Trait]
+	if !ok {
+		return nil, "", true
+	}
+	return judge(world, actingEntry, partDef, thresholds, targetSelector, partInfoProvider)
+}
+
+// selectSupportPlan は、パーツ選択戦略が選んだ行動を戦況に応じて見直します。
+// 介入パーツが選ばれていても使う意味がなければ他のパーツを選び直し、
+// 攻撃パーツが選ばれていても、SupportUsage の確率で使う意味のある介入パーツに切り替えます。
+func selectSupportPlan(
+	world donburi.World,
+	entry *donburi.Entry,
+	personality AIPersonality,
+	availableParts []core.AvailablePart,
+	plan AIActionPlan,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	rand *rand.Rand,
+) AIActionPlan {
+	settings := component.SettingsComponent.Get(entry)
+
+	if plan.PartDef.Category == core.CategoryIntervention {
+		if target, slot, ok := judgeIntervention(world, entry, plan.PartDef, personality.Support, targetSelector, partInfoProvider); ok {
+			plan.TargetEntry, plan.TargetPartSlot = target, slot
+			return plan
+		}
+		var otherParts []core.AvailablePart
+		for _, available := range availableParts {
+			if available.PartDef.Category != core.CategoryIntervention {
+				otherParts = append(otherParts, available)
+			}
+		}
+		// 他に使えるパーツがない場合は、そのまま介入パーツを使います。
+		if len(otherParts) == 0 {
+			return plan
+		}
+		slotKey, partDef := personality.PartSelectionStrategy(entry, otherParts)
+		if partDef == nil {
+			return plan
+		}
+		log.Printf("%s: AIは %s を使う戦況ではないため、%s を選択。", settings.Name, plan.PartDef.PartName, partDef.PartName)
+		return AIActionPlan{Slot: slotKey, PartDef: partDef}
+	}
+
+	if personality.SupportUsage <= 0 {
+		return plan
+	}
+	for _, available := range availableParts {
+		if available.PartDef.Category != core.CategoryIntervention {
+			continue
+		}
+		target, slot, ok := judgeIntervention(world, entry, available.PartDef, personality.Support, targetSelector, partInfoProvider)
+		if !ok {
+			continue
+		}
+		if rand.Float64() < personality.SupportUsage {
+			log.Printf("%s: AIは戦況から %s を選択。", settings.Name, available.PartDef.PartName)
+			return AIActionPlan{Slot: available.Slot, PartDef: available.PartDef, TargetEntry: target, TargetPartSlot: slot}
+		}
+		break
+	}
+	return plan
+}
+
+// judgeSupport は、支援で味方にかかるチームバフのうち、同等以上の効果がまだかかっていないものがあれば使う意味があると判断します。
+func judgeSupport(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	partDef *core.PartDefinition,
+	thresholds SupportThresholds,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+) (*donburi.Entry, core.PartSlotKey, bool) {
+	team := component.SettingsComponent.Get(actingEntry).Team
+	if teamBuffsCovered(world, team, core.BuffTargetAlly, partDef, thresholds, partInfoProvider) {
+		return nil, "", false
+	}
+	return nil, "", true
+}
+
+// judgeObstruct は、チャージが ObstructChargeRatio 以上進んだ（または行動待ちの）敵のうち、
+// 最も行動が近い敵のチームにまだ同等以上の妨害がかかっていなければ、その敵を妨害すると判断します。
+func judgeObstruct(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	partDef *core.PartDefinition,
+	thresholds SupportThresholds,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+) (*donburi.Entry, core.PartSlotKey, bool) {
+	var target *donburi.Entry
+	bestProgress := thresholds.ObstructChargeRatio
+	for _, enemy := range targetSelector.GetTargetableEnemies(actingEntry) {
+		var progress float64
+		switch component.StateComponent.Get(enemy).CurrentState {
+		case core.StateReady:
+			progress = 1.0
+		case core.StateCharging:
+			progress = component.GaugeComponent.Get(enemy).CurrentGauge / 100
+		default:
+			continue
+		}
+		if progress >= bestProgress && (target == nil || progress > bestProgress) {
+			target, bestProgress = enemy, progress
+		}
+	}
+	if target == nil {
+		return nil, "", false
+	}
+	if teamBuffsCovered(world, component.SettingsComponent.Get(target).Team, core.BuffTargetEnemy, partDef, thresholds, partInfoProvider) {
+		return nil, "", false
+	}
+	return target, "", true
+}
+
+// judgeRepair は、装甲の割合が RepairArmorRatio 以下の味方のパーツがあれば、最も傷んだパーツを修復すると判断します。
+func judgeRepair(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	partDef *core.PartDefinition,
+	thresholds SupportThresholds,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+) (*donburi.Entry, core.PartSlotKey, bool) {
+	target, slot := FindMostDamagedAllyPart(actingEntry, targetSelector, partInfoProvider, thresholds.RepairArmorRatio)
+	return target, slot, target != nil
+}
+
+// teamBuffsCovered は、介入パーツの計算式で side 側のチームにかかる効果が、すべて team に同等以上の効果として既にかかっているかを返します。
+// 残りの行動回数が BuffRefreshTurns 以下の効果は、かかっていないものとして扱います。side 側の効果がない場合は false を返します。
+func teamBuffsCovered(
+	world donburi.World,
+	team core.TeamID,
+	side core.BuffTargetSide,
+	partDef *core.PartDefinition,
+	thresholds SupportThresholds,
+	partInfoProvider PartInfoProviderInterface,
+) bool {
+	formula, ok := partInfoProvider.GetGameDataManager().Formulas[partDef.Trait]
+	if !ok {
+		return false
+	}
+	teamBuffsEntry, ok := query.NewQuery(filter.Contains(component.TeamBuffsComponent)).First(world)
+	if !ok {
+		return false
+	}
+	teamBuffs := component.TeamBuffsComponent.Get(teamBuffsEntry)
+	actionSeq := entity.GetActionQueueComponent(world).ActionSeq
+
+	covered := false
+	for _, effect := range formula.TeamBuffs {
+		// 対象の指定がない効果は、ApplyTeamBuffs と同じく味方にかかるものとして扱います。
+		effectSide := core.BuffTargetAlly
+		if effect.Target == core.BuffTargetEnemy {
+			effectSide = core.BuffTargetEnemy
+		}
+		if effectSide != side {
+			continue
+		}
+		delta := effect.PowerScale * float64(partDef.Power) / 100.0
+		if !teamBuffCovered(teamBuffs.Buffs[team][effect.Type], delta, actionSeq, thresholds.BuffRefreshTurns) {
+			return false
+		}
+		covered = true
+	}
+	return covered
+}
+
+// teamBuffCovered は、効果量 delta（乗数の 1.0 からの増減）と同じ向きで同等以上の効果が、
+// 残りの行動回数 refreshTurns を超えてかかっているかを返します。
+func teamBuffCovered(buffSources []*component.BuffSource, delta float64, actionSeq, refreshTurns int) bool {
+	for _, buff := range buffSources {
+		if !isBuffActive(buff, actionSeq) {
+			continue
+		}
+		if buff.ExpiresAtSeq != 0 && buff.ExpiresAtSeq-actionSeq <= refreshTurns {
+			continue
+		}
+		// 乗数の 1.0 からの差は丸め誤差を含むため（1.2 - 1.0 は 0.2 より僅かに小さい）、誤差の範囲で同等なら同じ効果とみなします。
+		existing := buff.Value - 1.0
+		if existing*delta > 0 && math.Abs(existing) >= math.Abs(delta)-teamBuffTolerance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,528 @@
+package system
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"testing"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// テスト用のパーツとメダルのIDです。介入パーツの頭部は実データの H-007〜H-009 と同じ威力にしてあります。
+const (
+	testSupportHead  = "T-H-SUP" // 支援: 味方の命中を 1.0 + 20/100 = 1.2 倍（無期限）
+	testObstructHead = "T-H-OBS" // 妨害: 敵の回避を 1.0 - 0.5*30/100 = 0.85 倍（無期限）
+	testRepairHead   = "T-H-REP" // 修復: 装甲を 30 回復
+	testPlainHead    = "T-H"     // 行動に使えない頭部
+	testRightArm     = "T-RA"
+	testLeftArm      = "T-LA"
+	testLegs         = "T-L"
+
+	testJudgedMedal = "T-M-J"
+	testAlwaysMedal = "T-M-A"
+
+	testJudgedPersonality = "判断テスト"
+	testAlwaysPersonality = "常時介入テスト"
+)
+
+// testSupportSeeds は介入パーツの使い方を比べるシミュレーションの乱数シードです。
+var testSupportSeeds = []int64{1, 2, 3, 4, 5, 6, 7, 8}
+
+// testSupportMaxTicks は1試合のシミュレーションの上限ティック数です。
+const testSupportMaxTicks = 5000
+
+// newSupportTestData は、ゲーム設定とメッセージだけを読み込み、テスト用のパーツ・メダル・計算式を登録したデータを返します。
+// 性格は判断テスト（既定のしきい値）と常時介入テスト（使える場面ではいつでも介入する）を登録し、テストの終了時に元に戻します。
+func newSupportTestData(t *testing.T) (*data.Config, *data.GameDataManager) {
+	t.Helper()
+	settingsJSON, err := os.ReadFile("../../assets/configs/game_settings.json")
+	if err != nil {
+		t.Fatalf("game_settings.json を読み込めません: %v", err)
+	}
+	var config data.Config
+	if err := json.Unmarshal(settingsJSON, &config); err != nil {
+		t.Fatalf("game_settings.json を解析できません: %v", err)
+	}
+	messagesJSON, err := os.ReadFile("../../assets/texts/messages.json")
+	if err != nil {
+		t.Fatalf("messages.json を読み込めません: %v", err)
+	}
+	messageManager, err := data.NewMessageManager(messagesJSON)
+	if err != nil {
+		t.Fatalf("messages.json を解析できません: %v", err)
+	}
+	gdm, err := data.NewGameDataManager(nil, messageManager)
+	if err != nil {
+		t.Fatalf("GameDataManager を生成できません: %v", err)
+	}
+
+	gdm.Formulas = map[core.Trait]core.ActionFormula{
+		core.TraitShoot: {ID: string(core.TraitShoot)},
+		core.TraitSupport: {ID: string(core.TraitSupport), TeamBuffs: []core.TeamBuffEffect{
+			{Type: core.BuffTypeAccuracy, Target: core.BuffTargetAlly, PowerScale: 1.0},
+		}},
+		core.TraitObstruct: {ID: string(core.TraitObstruct), TeamBuffs: []core.TeamBuffEffect{
+			{Type: core.BuffTypeEvasion, Target: core.BuffTargetEnemy, PowerScale: -0.5},
+		}},
+		core.TraitRepair: {ID: string(core.TraitRepair)},
+	}
+	parts := []*core.PartDefinition{
+		{ID: testSupportHead, PartName: "テスト支援ヘッド", Type: core.PartTypeHead, Category: core.CategoryIntervention, Trait: core.TraitSupport, MaxArmor: 80, Power: 20, Accuracy: 50, Charge: 60, Cooldown: 80, Weight: 15},
+		{ID: testObstructHead, PartName: "テスト妨害ヘッド", Type: core.PartTypeHead, Category: core.CategoryIntervention, Trait: core.TraitObstruct, MaxArmor: 80, Power: 30, Accuracy: 50, Charge: 60, Cooldown: 80, Weight: 15},
+		{ID: testRepairHead, PartName: "テスト修復ヘッド", Type: core.PartTypeHead, Category: core.CategoryIntervention, Trait: core.TraitRepair, MaxArmor: 80, Power: 30, Accuracy: 50, Charge: 60, Cooldown: 80, Weight: 15},
+		{ID: testPlainHead, PartName: "テストヘッド", Type: core.PartTypeHead, Category: core.CategoryNone, Trait: core.TraitNone, MaxArmor: 80, Weight: 15},
+		{ID: testRightArm, PartName: "テストライフル", Type: core.PartTypeRArm, Category: core.CategoryRanged, Trait: core.TraitShoot, MaxArmor: 100, Power: 40, Accuracy: 50, Charge: 60, Cooldown: 60, Weight: 30},
+		{ID: testLeftArm, PartName: "テストライフル左", Type: core.PartTypeLArm, Category: core.CategoryRanged, Trait: core.TraitShoot, MaxArmor: 100, Power: 40, Accuracy: 50, Charge: 60, Cooldown: 60, Weight: 30},
+		{ID: testLegs, PartName: "テストレッグ", Type: core.PartTypeLegs, MaxArmor: 120, Propulsion: 50, Mobility: 50, Defense: 30, Stability: 50, LegType: core.LegTypeBipedal, Weight: 40, LoadCapacity: 200},
+	}
+	for _, part := range parts {
+		if err := gdm.AddPartDefinition(part); err != nil {
+			t.Fatalf("パーツ %s を登録できません: %v", part.ID, err)
+		}
+	}
+	medals := []*core.Medal{
+		{ID: testJudgedMedal, Name: "判断メダル", Personality: testJudgedPersonality, SkillLevel: 5},
+		{ID: testAlwaysMedal, Name: "常時メダル", Personality: testAlwaysPersonality, SkillLevel: 5},
+	}
+	for _, medal := range medals {
+		if err := gdm.AddMedalDefinition(medal); err != nil {
+			t.Fatalf("メダル %s を登録できません: %v", medal.ID, err)
+		}
+	}
+
+	judged := mustBuildPersonality(t, core.PersonalityDefinition{ID: testJudgedPersonality})
+	always := mustBuildPersonality(t, core.PersonalityDefinition{
+		ID:      testAlwaysPersonality,
+		Support: json.RawMessage(`{"BuffRefreshTurns": 1000, "ObstructChargeRatio": 0, "RepairArmorRatio": 1}`),
+	})
+	setTestPersonalities(t, map[string]AIPersonality{
+		testJudgedPersonality: judged,
+		testAlwaysPersonality: always,
+		"リーダー":                judged,
+	})
+	return &config, gdm
+}
+
+// mustBuildPersonality は、ハンターのターゲット選択と威力の高いパーツを選ぶ戦略に、def の残りの項目を加えた性格を組み立てます。
+// SupportUsage は 1.0 で、介入パーツを使う意味があれば必ず使います。
+func mustBuildPersonality(t *testing.T, def core.PersonalityDefinition) AIPersonality {
+	t.Helper()
+	def.Targeting = core.StrategyRef{Name: "hunter"}
+	def.PartSelection = "highest_power"
+	def.PartToDamage = "random"
+	def.Weights.SupportUsage = 1.0
+	personality, err := buildPersonality(&def)
+	if err != nil {
+		t.Fatalf("性格 %s を組み立てられません: %v", def.ID, err)
+	}
+	return personality
+}
+
+// setTestPersonalities は PersonalityRegistry を差し替え、テストの終了時に元に戻します。
+func setTestPersonalities(t *testing.T, registry map[string]AIPersonality) {
+	t.Helper()
+	original := PersonalityRegistry
+	PersonalityRegistry = registry
+	t.Cleanup(func() { PersonalityRegistry = original })
+}
+
+// silenceTestLogs はテストの間だけ標準のログ出力を止めます。
+func silenceTestLogs(t *testing.T) {
+	t.Helper()
+	writer := log.Writer()
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(writer) })
+}
+
+// testMedarot はテスト用の機体の構成を返します。
+func testMedarot(id string, team core.TeamID, leader bool, medalID, headID string) core.MedarotData {
+	return core.MedarotData{
+		ID:         id,
+		Name:       id,
+		IsLeader:   leader,
+		Team:       team,
+		MedalID:    medalID,
+		HeadID:     headID,
+		RightArmID: testRightArm,
+		LeftArmID:  testLeftArm,
+		LegsID:     testLegs,
+	}
+}
+
+// newTestBattleWorld は、すべての機体をAIが操作する戦闘ワールドを生成します。
+func newTestBattleWorld(config *data.Config, gdm *data.GameDataManager, teams ...core.TeamSetup) donburi.World {
+	setup := &core.BattleSetup{Teams: teams, PlayerTeam: core.TeamNone}
+	data.NormalizeBattleSetup(setup)
+	world := donburi.NewWorld()
+	entity.InitializeBattleWorld(world, &data.SharedResources{Config: *config, GameDataManager: gdm}, setup)
+	return world
+}
+
+// supportTestWorld は、head の介入パーツを持つ機体と味方1機、敵1機からなる小さな戦闘です。
+type supportTestWorld struct {
+	world            donburi.World
+	actor            *donburi.Entry
+	ally             *donburi.Entry
+	enemy            *donburi.Entry
+	partInfoProvider PartInfoProviderInterface
+	targetSelector   *TargetSelector
+}
+
+func newSupportTestWorld(t *testing.T, config *data.Config, gdm *data.GameDataManager, head string) *supportTestWorld {
+	t.Helper()
+	world := newTestBattleWorld(config, gdm,
+		core.TeamSetup{Team: core.Team1, Medarots: []core.MedarotData{
+			testMedarot("actor", core.Team1, true, testJudgedMedal, head),
+			testMedarot("ally", core.Team1, false, testJudgedMedal, testPlainHead),
+		}},
+		core.TeamSetup{Team: core.Team2, Medarots: []core.MedarotData{
+			testMedarot("enemy", core.Team2, true, testJudgedMedal, testPlainHead),
+		}},
+	)
+	w := &supportTestWorld{world: world}
+	query.NewQuery(filter.Contains(component.SettingsComponent)).Each(world, func(entry *donburi.Entry) {
+		switch component.SettingsComponent.Get(entry).ID {
+		case "actor":
+			w.actor = entry
+		case "ally":
+			w.ally = entry
+		case "enemy":
+			w.enemy = entry
+		}
+	})
+	w.partInfoProvider = NewPartInfoProvider(world, config, gdm)
+	w.targetSelector = NewTargetSelector(world, config, w.partInfoProvider)
+	return w
+}
+
+// selectPlan は、パーツ選択戦略が plan を選んだものとして selectSupportPlan に見直させた行動を返します。
+func (w *supportTestWorld) selectPlan(personality AIPersonality, plan AIActionPlan) AIActionPlan {
+	available := w.partInfoProvider.GetAvailableAttackParts(w.actor)
+	return selectSupportPlan(w.world, w.actor, personality, available, plan, w.targetSelector, w.partInfoProvider, rand.New(rand.NewSource(1)))
+}
+
+// headPlan は機体の頭部を使う行動です。
+func (w *supportTestWorld) headPlan(t *testing.T) AIActionPlan {
+	t.Helper()
+	partInst := component.PartsComponent.Get(w.actor).Map[core.PartSlotHead]
+	partDef, ok := w.partInfoProvider.GetGameDataManager().GetPartDefinition(partInst.DefinitionID)
+	if !ok {
+		t.Fatalf("パーツ %s が見つかりません", partInst.DefinitionID)
+	}
+	return AIActionPlan{Slot: core.PartSlotHead, PartDef: partDef}
+}
+
+// addTeamBuff は team に buffType のチームバフを直接かけます。
+func (w *supportTestWorld) addTeamBuff(team core.TeamID, buffType core.BuffType, source *donburi.Entry, value float64, expiresAtSeq int) {
+	teamBuffsEntry, _ := query.NewQuery(filter.Contains(component.TeamBuffsComponent)).First(w.world)
+	buffs := component.TeamBuffsComponent.Get(teamBuffsEntry).Buffs
+	if buffs[team] == nil {
+		buffs[team] = make(map[core.BuffType][]*component.BuffSource)
+	}
+	buffs[team][buffType] = append(buffs[team][buffType], &component.BuffSource{
+		SourceEntry:  source,
+		SourcePart:   core.PartSlotHead,
+		Value:        value,
+		ExpiresAtSeq: expiresAtSeq,
+	})
+}
+
+// setCharging は機体をチャージ中にし、ゲージを gauge（0〜100）にします。
+func setCharging(entry *donburi.Entry, gauge float64) {
+	component.StateComponent.Get(entry).CurrentState = core.StateCharging
+	component.GaugeComponent.Get(entry).CurrentGauge = gauge
+}
+
+func TestSelectSupportPlanSupport(t *testing.T) {
+	silenceTestLogs(t)
+	config, gdm := newSupportTestData(t)
+	judged := PersonalityRegistry[testJudgedPersonality]
+
+	tests := []struct {
+		name      string
+		buff      float64 // 既にかかっている命中のチームバフ。0 の場合はかかっていない
+		expiresIn int     // バフの残りの行動回数。0 の場合は無期限
+		wantHead  bool
+	}{
+		{name: "バフなし", wantHead: true},
+		{name: "同じ効果が無期限でかかっている", buff: 1.2, wantHead: false},
+		{name: "より強い効果がかかっている", buff: 1.3, wantHead: false},
+		{name: "弱い効果しかかかっていない", buff: 1.1, wantHead: true},
+		{name: "同じ効果が次の行動で切れる", buff: 1.2, expiresIn: 1, wantHead: true},
+		{name: "同じ効果がまだ2行動残っている", buff: 1.2, expiresIn: 2, wantHead: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newSupportTestWorld(t, config, gdm, testSupportHead)
+			if tt.buff != 0 {
+				expiresAtSeq := 0
+				if tt.expiresIn > 0 {
+					expiresAtSeq = entity.GetActionQueueComponent(w.world).ActionSeq + tt.expiresIn
+				}
+				w.addTeamBuff(core.Team1, core.BuffTypeAccuracy, w.ally, tt.buff, expiresAtSeq)
+			}
+
+			plan := w.selectPlan(judged, w.headPlan(t))
+			if gotHead := plan.Slot == core.PartSlotHead; gotHead != tt.wantHead {
+				t.Fatalf("支援を使うか = %v (%s), want %v", gotHead, plan.PartDef.PartName, tt.wantHead)
+			}
+			if !tt.wantHead && plan.PartDef.Category != core.CategoryRanged {
+				t.Errorf("代わりのパーツのカテゴリ = %s, want %s", plan.PartDef.Category, core.CategoryRanged)
+			}
+		})
+	}
+}
+
+func TestSelectSupportPlanObstruct(t *testing.T) {
+	silenceTestLogs(t)
+	config, gdm := newSupportTestData(t)
+	judged := PersonalityRegistry[testJudgedPersonality]
+	early := mustBuildPersonality(t, core.PersonalityDefinition{ID: "早め", Support: json.RawMessage(`{"ObstructChargeRatio": 0.3}`)})
+
+	tests := []struct {
+		name        string
+		personality AIPersonality
+		enemyState  core.StateType
+		enemyGauge  float64
+		debuff      float64 // 敵のチームに既にかかっている回避のデバフ。0 の場合はかかっていない
+		wantHead    bool
+	}{
+		{name: "敵が待機中", personality: judged, enemyState: core.StateIdle, wantHead: false},
+		{name: "チャージが半分", personality: judged, enemyState: core.StateCharging, enemyGauge: 50, wantHead: false},
+		{name: "チャージが6割", personality: judged, enemyState: core.StateCharging, enemyGauge: 60, wantHead: true},
+		{name: "行動待ち", personality: judged, enemyState: core.StateReady, wantHead: true},
+		{name: "同じ妨害がかかっている", personality: judged, enemyState: core.StateReady, debuff: 0.85, wantHead: false},
+		{name: "弱い妨害しかかかっていない", personality: judged, enemyState: core.StateReady, debuff: 0.9, wantHead: true},
+		{name: "しきい値を下げた性格", personality: early, enemyState: core.StateCharging, enemyGauge: 40, wantHead: true},
+		{name: "しきい値を下げた性格でも足りない", personality: early, enemyState: core.StateCharging, enemyGauge: 20, wantHead: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newSupportTestWorld(t, config, gdm, testObstructHead)
+			component.StateComponent.Get(w.enemy).CurrentState = tt.enemyState
+			if tt.enemyState == core.StateCharging {
+				setCharging(w.enemy, tt.enemyGauge)
+			}
+			if tt.debuff != 0 {
+				w.addTeamBuff(core.Team2, core.BuffTypeEvasion, w.ally, tt.debuff, 0)
+			}
+
+			plan := w.selectPlan(tt.personality, w.headPlan(t))
+			if gotHead := plan.Slot == core.PartSlotHead; gotHead != tt.wantHead {
+				t.Fatalf("妨害を使うか = %v (%s), want %v", gotHead, plan.PartDef.PartName, tt.wantHead)
+			}
+			if tt.wantHead && plan.TargetEntry != w.enemy {
+				t.Errorf("妨害のターゲットが行動の近い敵ではありません")
+			}
+		})
+	}
+}
+
+func TestSelectSupportPlanRepair(t *testing.T) {
+	silenceTestLogs(t)
+	config, gdm := newSupportTestData(t)
+	judged := PersonalityRegistry[testJudgedPersonality]
+	careful := mustBuildPersonality(t, core.PersonalityDefinition{ID: "慎重", Support: json.RawMessage(`{"RepairArmorRatio": 0.8}`)})
+
+	tests := []struct {
+		name        string
+		personality AIPersonality
+		allyArmor   int // 味方の右腕の装甲（最大 100）
+		wantHead    bool
+	}{
+		{name: "無傷", personality: judged, allyArmor: 100, wantHead: false},
+		{name: "装甲が6割", personality: judged, allyArmor: 60, wantHead: false},
+		{name: "装甲が5割", personality: judged, allyArmor: 50, wantHead: true},
+		{name: "装甲が2割", personality: judged, allyArmor: 20, wantHead: true},
+		{name: "しきい値を上げた性格", personality: careful, allyArmor: 70, wantHead: true},
+		{name: "しきい値を上げた性格でも傷が浅い", personality: careful, allyArmor: 90, wantHead: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newSupportTestWorld(t, config, gdm, testRepairHead)
+			component.PartsComponent.Get(w.ally).Map[core.PartSlotRightArm].CurrentArmor = tt.allyArmor
+
+			plan := w.selectPlan(tt.personality, w.headPlan(t))
+			if gotHead := plan.Slot == core.PartSlotHead; gotHead != tt.wantHead {
+				t.Fatalf("修復を使うか = %v (%s), want %v", gotHead, plan.PartDef.PartName, tt.wantHead)
+			}
+			if tt.wantHead && (plan.TargetEntry != w.ally || plan.TargetPartSlot != core.PartSlotRightArm) {
+				t.Errorf("修復のターゲットが味方の右腕ではありません: %v %s", plan.TargetEntry, plan.TargetPartSlot)
+			}
+		})
+	}
+}
+
+// TestSelectSupportPlanSupportUsage は、攻撃パーツが選ばれていても、SupportUsage に従って使う意味のある介入パーツに切り替えることを確認します。
+func TestSelectSupportPlanSupportUsage(t *testing.T) {
+	silenceTestLogs(t)
+	config, gdm := newSupportTestData(t)
+	eager := PersonalityRegistry[testJudgedPersonality]
+	never := eager
+	never.SupportUsage = 0
+
+	tests := []struct {
+		name        string
+		personality AIPersonality
+		allyArmor   int
+		wantRepair  bool
+	}{
+		{name: "修復が必要", personality: eager, allyArmor: 40, wantRepair: true},
+		{name: "修復が不要", personality: eager, allyArmor: 100, wantRepair: false},
+		{name: "介入パーツを使わない性格", personality: never, allyArmor: 40, wantRepair: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newSupportTestWorld(t, config, gdm, testRepairHead)
+			component.PartsComponent.Get(w.ally).Map[core.PartSlotRightArm].CurrentArmor = tt.allyArmor
+			armDef, _ := gdm.GetPartDefinition(testRightArm)
+
+			plan := w.selectPlan(tt.personality, AIActionPlan{Slot: core.PartSlotRightArm, PartDef: armDef})
+			if gotRepair := plan.PartDef.Trait == core.TraitRepair; gotRepair != tt.wantRepair {
+				t.Fatalf("修復に切り替えたか = %v (%s), want %v", gotRepair, plan.PartDef.PartName, tt.wantRepair)
+			}
+		})
+	}
+}
+
+// supportTestTally は、シミュレーション中に介入パーツを使った回数と、使う意味がなかった回数を特性ごとに数えます。
+type supportTestTally struct {
+	uses   map[core.Trait]int
+	wasted map[core.Trait]int
+	wins   int
+}
+
+// TestSupportJudgementInSimulation は、既定のしきい値で判断する性格と、使える場面ではいつでも介入する性格を
+// 同じ機体構成で対戦させ、性格どおりの行動選択（aiSelectActionWithPersonality）で使われた介入パーツを数えます。
+// 判断する側は、同じ効果がかかっている間に支援・妨害をかけ直さず、チャージが6割未満の敵を妨害せず、
+// 装甲が半分より多く残るパーツを修復しないことを確認します。
+func TestSupportJudgementInSimulation(t *testing.T) {
+	silenceTestLogs(t)
+	config, gdm := newSupportTestData(t)
+
+	tallies := map[core.TeamID]*supportTestTally{
+		core.Team1: {uses: make(map[core.Trait]int), wasted: make(map[core.Trait]int)},
+		core.Team2: {uses: make(map[core.Trait]int), wasted: make(map[core.Trait]int)},
+	}
+	teamSetup := func(team core.TeamID, medalID string) core.TeamSetup {
+		return core.TeamSetup{Team: team, Medarots: []core.MedarotData{
+			testMedarot("support", team, true, medalID, testSupportHead),
+			testMedarot("obstruct", team, false, medalID, testObstructHead),
+			testMedarot("repair", team, false, medalID, testRepairHead),
+		}}
+	}
+	for _, seed := range testSupportSeeds {
+		world := newTestBattleWorld(config, gdm, teamSetup(core.Team1, testJudgedMedal), teamSetup(core.Team2, testAlwaysMedal))
+		sim := NewBattleSimulator(world, config, gdm, rand.New(rand.NewSource(seed)))
+		sim.decide = func(entry *donburi.Entry) bool {
+			// 両腕を失った機体は介入パーツしか使えないため、判断の対象から外します。
+			hasAttackPart := false
+			for _, available := range sim.partInfoProvider.GetAvailableAttackParts(entry) {
+				hasAttackPart = hasAttackPart || available.PartDef.Category != core.CategoryIntervention
+			}
+			aiSelectActionWithPersonality(world, entry, aiPersonalityFor(entry), sim.partInfoProvider, sim.chargeInitiationSystem,
+				sim.targetSelector, sim.hitCalculator, sim.damageCalculator, sim.config, sim.rand)
+
+			// チャージの開始は戦況を変えないため、使う意味があったかはチャージを開始した直後の戦況で判定します。
+			if !hasAttackPart || component.StateComponent.Get(entry).CurrentState != core.StateCharging {
+				return true
+			}
+			partInst := component.PartsComponent.Get(entry).Map[component.ActionIntentComponent.Get(entry).SelectedPartKey]
+			partDef, _ := gdm.GetPartDefinition(partInst.DefinitionID)
+			if partDef.Category != core.CategoryIntervention {
+				return true
+			}
+			tally := tallies[component.SettingsComponent.Get(entry).Team]
+			tally.uses[partDef.Trait]++
+			if interventionWasted(world, config, gdm, entry, partDef) {
+				tally.wasted[partDef.Trait]++
+			}
+			return true
+		}
+		if result := sim.RunMatch(testSupportMaxTicks); result.IsGameOver {
+			if tally, ok := tallies[result.Winner]; ok {
+				tally.wins++
+			}
+		}
+	}
+
+	judged, always := tallies[core.Team1], tallies[core.Team2]
+	judgedWasted, alwaysWasted := 0, 0
+	for _, trait := range []core.Trait{core.TraitSupport, core.TraitObstruct, core.TraitRepair} {
+		t.Logf("%s: 判断あり %d 回中 %d 回が不要 / 常に介入 %d 回中 %d 回が不要",
+			trait, judged.uses[trait], judged.wasted[trait], always.uses[trait], always.wasted[trait])
+		if judged.uses[trait] == 0 {
+			t.Errorf("%s: 判断する性格が一度も使っていません", trait)
+		}
+		judgedWasted += judged.wasted[trait]
+		alwaysWasted += always.wasted[trait]
+	}
+	t.Logf("勝利数: 判断あり %d / 常に介入 %d（%d 試合）", judged.wins, always.wins, len(testSupportSeeds))
+
+	if judgedWasted != 0 {
+		t.Errorf("判断する性格が使う意味のない介入を %d 回行いました", judgedWasted)
+	}
+	if alwaysWasted <= judgedWasted {
+		t.Errorf("使う意味のない介入の回数: 常に介入 %d, 判断あり %d; 判断によって減っていません", alwaysWasted, judgedWasted)
+	}
+}
+
+// interventionWasted は、entry がチャージを開始した介入パーツに、開始した時点で使う意味がなかったかを返します。
+// 判定はテスト用パーツの効果量から決めた固定の値で行います。
+func interventionWasted(world donburi.World, config *data.Config, gdm *data.GameDataManager, entry *donburi.Entry, partDef *core.PartDefinition) bool {
+	const tolerance = 1e-9
+	target := component.TargetComponent.Get(entry)
+	var targetEntry *donburi.Entry
+	if target.TargetEntity != 0 {
+		targetEntry = world.Entry(target.TargetEntity)
+	}
+
+	switch partDef.Trait {
+	case core.TraitSupport:
+		// 味方の命中が既に 1.2 倍以上なら、かけ直しても変わりません。
+		return teamBuffMultiplier(world, config, component.SettingsComponent.Get(entry).Team, core.BuffTypeAccuracy) >= 1.2-tolerance
+	case core.TraitObstruct:
+		if targetEntry == nil {
+			return true
+		}
+		switch component.StateComponent.Get(targetEntry).CurrentState {
+		case core.StateReady:
+		case core.StateCharging:
+			if component.GaugeComponent.Get(targetEntry).CurrentGauge < 60 {
+				return true
+			}
+		default:
+			return true
+		}
+		// 敵の回避が既に 0.85 倍以下なら、かけ直しても変わりません。
+		return teamBuffMultiplier(world, config, component.SettingsComponent.Get(targetEntry).Team, core.BuffTypeEvasion) <= 0.85+tolerance
+	case core.TraitRepair:
+		if targetEntry == nil {
+			return true
+		}
+		partInst := component.PartsComponent.Get(targetEntry).Map[target.TargetPartSlot]
+		if partInst == nil || partInst.IsBroken {
+			return true
+		}
+		targetDef, _ := gdm.GetPartDefinition(partInst.DefinitionID)
+		return partInst.CurrentArmor*2 > targetDef.MaxArmor
+	}
+	return false
+}
+
+// teamBuffMultiplier は team に今かかっている buffType の乗数を返します。
+func teamBuffMultiplier(world donburi.World, config *data.Config, team core.TeamID, buffType core.BuffType) float64 {
+	teamBuffsEntry, _ := query.NewQuery(filter.Contains(component.TeamBuffsComponent)).First(world)
+	buffs := component.TeamBuffsComponent.Get(teamBuffsEntry).Buffs[team][buffType]
+	return aggregateTeamBuffs(config, buffType, buffs, entity.GetActionQueueComponent(world).ActionSeq)
+}
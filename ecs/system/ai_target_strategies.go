@@ -16,6 +16,7 @@ import (
 
 // getAllTargetablePartsは、指定された攻撃者がターゲットにできる全てのパーツを取得します。
 // BattleLogicへの依存をなくし、必要なTargetSelectorとPartInfoProviderを直接受け取ります。
+// 同じシードから同じ結果が得られるよう、パーツは敵の並び順とスロットの固定の順序で返します。
 func getAllTargetableParts(actingEntry *donburi.Entry, targetSelector *TargetSelector, partInfoProvider PartInfoProviderInterface, includeHead bool) []component.TargetablePart {
 	var allParts []component.TargetablePart
 
@@ -28,8 +29,9 @@ func getAllTargetableParts(actingEntry *donburi.Entry, targetSelector *TargetSel
 		if partsComp == nil {
 			continue
 		}
-		for slotKey, partInst := range partsComp.Map {
-			if partInst.IsBroken {
+		for _, slotKey := range []core.PartSlotKey{core.PartSlotHead, core.PartSlotRightArm, core.PartSlotLeftArm, core.PartSlotLegs} {
+			partInst, ok := partsComp.Map[slotKey]
+			if !ok || partInst == nil || partInst.IsBroken {
 				continue
 			}
 			// 頭部パーツを除外するオプション
@@ -60,9 +62,10 @@ type TargetSortFunc func(parts []component.TargetablePart)
 // --- 戦略の実装 ---
 
 // selectTargetWithSort は、提供されたソート関数を使用してターゲットを選択する共通ロジックです。
-func selectTargetWithSort(actingEntry *donburi.Entry, targetSelector *TargetSelector, partInfoProvider PartInfoProviderInterface, sortFunc TargetSortFunc) (*donburi.Entry, core.PartSlotKey) {
+// includeHead が true の場合は、最初から頭部もターゲット候補に含めます。
+func selectTargetWithSort(actingEntry *donburi.Entry, targetSelector *TargetSelector, partInfoProvider PartInfoProviderInterface, sortFunc TargetSortFunc, includeHead bool) (*donburi.Entry, core.PartSlotKey) {
 	// まずは頭部以外のパーツをターゲット候補とする
-	targetParts := getAllTargetableParts(actingEntry, targetSelector, partInfoProvider, false)
+	targetParts := getAllTargetableParts(actingEntry, targetSelector, partInfoProvider, includeHead)
 	// 候補がなければ頭部も含めて再検索
 	if len(targetParts) == 0 {
 		targetParts = getAllTargetableParts(actingEntry, targetSelector, partInfoProvider, true)
@@ -78,30 +81,34 @@ func selectTargetWithSort(actingEntry *donburi.Entry, targetSelector *TargetSele
 }
 
 // CrusherStrategy は最も装甲の高いパーツを狙います。
-type CrusherStrategy struct{}
+type CrusherStrategy struct {
+	IncludeHead bool // 頭部も最初からターゲット候補に含めるか。false の場合、頭部は他に狙えるパーツがないときだけ狙います
+}
 
 func (s *CrusherStrategy) SelectTarget(world donburi.World, actingEntry *donburi.Entry, targetSelector *TargetSelector, partInfoProvider PartInfoProviderInterface, rand *rand.Rand) (*donburi.Entry, core.PartSlotKey) {
-	return selectTargetWithSort(actingEntry, targetSelector, partInfoProvider, s.GetSortFunction())
+	return selectTargetWithSort(actingEntry, targetSelector, partInfoProvider, s.GetSortFunction(), s.IncludeHead)
 }
 
 func (s *CrusherStrategy) GetSortFunction() TargetSortFunc {
 	return func(parts []component.TargetablePart) {
-		sort.Slice(parts, func(i, j int) bool {
+		sort.SliceStable(parts, func(i, j int) bool {
 			return parts[i].PartInst.CurrentArmor > parts[j].PartInst.CurrentArmor
 		})
 	}
 }
 
 // HunterStrategy は最も装甲の低いパーツを狙います。
-type HunterStrategy struct{}
+type HunterStrategy struct {
+	IncludeHead bool // 頭部も最初からターゲット候補に含めるか。false の場合、頭部は他に狙えるパーツがないときだけ狙います
+}
 
 func (s *HunterStrategy) SelectTarget(world donburi.World, actingEntry *donburi.Entry, targetSelector *TargetSelector, partInfoProvider PartInfoProviderInterface, rand *rand.Rand) (*donburi.Entry, core.PartSlotKey) {
-	return selectTargetWithSort(actingEntry, targetSelector, partInfoProvider, s.GetSortFunction())
+	return selectTargetWithSort(actingEntry, targetSelector, partInfoProvider, s.GetSortFunction(), s.IncludeHead)
 }
 
 func (s *HunterStrategy) GetSortFunction() TargetSortFunc {
 	return func(parts []component.TargetablePart) {
-		sort.Slice(parts, func(i, j int) bool {
+		sort.SliceStable(parts, func(i, j int) bool {
 			return parts[i].PartInst.CurrentArmor < parts[j].PartInst.CurrentArmor
 		})
 	}
@@ -136,10 +143,17 @@ func (s *LeaderStrategy) SelectTarget(
 	partInfoProvider PartInfoProviderInterface,
 	rand *rand.Rand,
 ) (*donburi.Entry, core.PartSlotKey) {
-	opponentTeamID := targetSelector.GetOpponentTeam(actingEntry)
-	leader := entity.FindLeader(world, opponentTeamID)
+	// 敵チームが複数ある場合は、健在なリーダーの中からランダムに1機を選ぶ
+	var leaders []*donburi.Entry
+	for _, opponentTeamID := range targetSelector.GetOpponentTeams(actingEntry) {
+		leader := entity.FindLeader(world, opponentTeamID)
+		if leader != nil && component.StateComponent.Get(leader).CurrentState != core.StateBroken {
+			leaders = append(leaders, leader)
+		}
+	}
 
-	if leader != nil && component.StateComponent.Get(leader).CurrentState != core.StateBroken {
+	if len(leaders) > 0 {
+		leader := leaders[rand.Intn(len(leaders))]
 		targetPart := targetSelector.SelectPartToDamage(leader, actingEntry, rand)
 		if targetPart != nil {
 			slotKey := partInfoProvider.FindPartSlot(leader, targetPart)
@@ -175,7 +189,7 @@ func (s *ChaseStrategy) SelectTarget(
 	}
 
 	if len(legParts) > 0 {
-		sort.Slice(legParts, func(i, j int) bool {
+		sort.SliceStable(legParts, func(i, j int) bool {
 			return legParts[i].PartDef.Propulsion > legParts[j].PartDef.Propulsion
 		})
 		// 最も推進力の高い脚部が複数ある場合、その中からランダムに選ぶ
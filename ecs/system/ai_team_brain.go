@@ -0,0 +1,221 @@
+package system
+
+import (
+	"log"
+	"math/rand"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+)
+
+// TeamTacticFunc は、チームの戦術に従ってチームへの指示を決める関数です。
+// actingEntry はアイドル状態になったチームの機体で、狙える敵はこの機体から見て判断します。
+// 指示を出さない場合は false を返します。
+type TeamTacticFunc func(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	gameConfig *data.Config,
+) (component.TeamIntent, bool)
+
+// teamTactics は戦術ごとの指示の決め方です。ここにない戦術（none など）のチームには指示を出しません。
+var teamTactics = map[core.TeamTactic]TeamTacticFunc{
+	core.TeamTacticFocusFire:     focusFireIntent,
+	core.TeamTacticProtectLeader: protectLeaderIntent,
+	core.TeamTacticBreakSupport:  breakSupportIntent,
+	core.TeamTacticAdaptive:      adaptiveIntent,
+}
+
+// UpdateTeamBrainSystem は、チームの機体がアイドル状態になるたびに呼ばれ、その機体のチームへの指示を戦術に従って更新します。
+func UpdateTeamBrainSystem(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	gameConfig *data.Config,
+) {
+	brain := entity.GetTeamBrain(world)
+	if brain == nil {
+		return
+	}
+	team := component.SettingsComponent.Get(actingEntry).Team
+	decide, ok := teamTactics[brain.Tactics[team]]
+	if !ok {
+		return
+	}
+
+	intent, ok := decide(world, actingEntry, targetSelector, partInfoProvider, gameConfig)
+	if !ok {
+		delete(brain.Intents, team)
+		return
+	}
+	if previous, exists := brain.Intents[team]; !exists || previous != intent {
+		log.Printf("チーム%d のAIの指示: %s（%s %s）", int(team)+1, intent.Kind, component.SettingsComponent.Get(intent.Target).Name, intent.TargetPartSlot)
+	}
+	brain.Intents[team] = intent
+}
+
+// TeamIntentFor は、機体のチームに出ている指示を返します。
+// 指示がない場合や、指示されたターゲット・パーツが既に破壊されている場合は false を返します。
+func TeamIntentFor(world donburi.World, entry *donburi.Entry) (component.TeamIntent, bool) {
+	brain := entity.GetTeamBrain(world)
+	if brain == nil {
+		return component.TeamIntent{}, false
+	}
+	intent, ok := brain.Intents[component.SettingsComponent.Get(entry).Team]
+	if !ok || intent.Target == nil || !intent.Target.Valid() || component.StateComponent.Get(intent.Target).CurrentState == core.StateBroken {
+		return component.TeamIntent{}, false
+	}
+	if intent.TargetPartSlot != "" {
+		if partInst := component.PartsComponent.Get(intent.Target).Map[intent.TargetPartSlot]; partInst == nil || partInst.IsBroken {
+			return component.TeamIntent{}, false
+		}
+	}
+	return intent, true
+}
+
+// focusFireIntent は、頭部の装甲が最も少ない敵の頭部への集中攻撃を指示します。
+func focusFireIntent(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	gameConfig *data.Config,
+) (component.TeamIntent, bool) {
+	var target *donburi.Entry
+	lowestArmor := 0
+	for _, enemy := range targetSelector.GetTargetableEnemies(actingEntry) {
+		head := component.PartsComponent.Get(enemy).Map[core.PartSlotHead]
+		if head == nil || head.IsBroken {
+			continue
+		}
+		if target == nil || head.CurrentArmor < lowestArmor {
+			target = enemy
+			lowestArmor = head.CurrentArmor
+		}
+	}
+	if target == nil {
+		return component.TeamIntent{}, false
+	}
+	return component.TeamIntent{Kind: core.TeamIntentFocusTarget, Target: target, TargetPartSlot: core.PartSlotHead}, true
+}
+
+// protectLeaderIntent は、自チームのリーダーを最後に攻撃した敵を狙うよう指示します。
+// リーダーの攻撃の履歴はAIコンポーネントに記録されるため、リーダーがAIでない場合は指示を出しません。
+func protectLeaderIntent(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	gameConfig *data.Config,
+) (component.TeamIntent, bool) {
+	leader := entity.FindLeader(world, component.SettingsComponent.Get(actingEntry).Team)
+	if leader == nil || !leader.HasComponent(component.AIComponent) {
+		return component.TeamIntent{}, false
+	}
+	attacker := component.AIComponent.Get(leader).TargetHistory.LastAttacker
+	if attacker == nil || !attacker.Valid() || component.StateComponent.Get(attacker).CurrentState == core.StateBroken || !targetSelector.IsOpponent(actingEntry, attacker) {
+		return component.TeamIntent{}, false
+	}
+	return component.TeamIntent{Kind: core.TeamIntentProtectLeader, Target: attacker}, true
+}
+
+// breakSupportIntent は、敵の破壊されていない介入パーツのうち、装甲が最も少ないものの破壊を指示します。
+func breakSupportIntent(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	gameConfig *data.Config,
+) (component.TeamIntent, bool) {
+	gdm := partInfoProvider.GetGameDataManager()
+	var intent component.TeamIntent
+	lowestArmor := 0
+	for _, enemy := range targetSelector.GetTargetableEnemies(actingEntry) {
+		for _, slot := range []core.PartSlotKey{core.PartSlotHead, core.PartSlotRightArm, core.PartSlotLeftArm} {
+			partInst := component.PartsComponent.Get(enemy).Map[slot]
+			if partInst == nil || partInst.IsBroken {
+				continue
+			}
+			partDef, found := gdm.GetPartDefinition(partInst.DefinitionID)
+			if !found || partDef.Category != core.CategoryIntervention {
+				continue
+			}
+			if intent.Target == nil || partInst.CurrentArmor < lowestArmor {
+				intent = component.TeamIntent{Kind: core.TeamIntentBreakSupport, Target: enemy, TargetPartSlot: slot}
+				lowestArmor = partInst.CurrentArmor
+			}
+		}
+	}
+	return intent, intent.Target != nil
+}
+
+// adaptiveIntent は戦況に応じて指示を使い分けます。
+// リーダーの頭部の装甲が LeaderDangerRatio 以下で攻撃を受けていればリーダーの保護を、
+// そうでなければ敵の介入パーツの破壊を、介入パーツがなければ集中攻撃を指示します。
+func adaptiveIntent(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	gameConfig *data.Config,
+) (component.TeamIntent, bool) {
+	leader := entity.FindLeader(world, component.SettingsComponent.Get(actingEntry).Team)
+	if leader != nil && leaderInDanger(leader, partInfoProvider, gameConfig.TeamBrain.LeaderDangerRatio) {
+		if intent, ok := protectLeaderIntent(world, actingEntry, targetSelector, partInfoProvider, gameConfig); ok {
+			return intent, true
+		}
+	}
+	if intent, ok := breakSupportIntent(world, actingEntry, targetSelector, partInfoProvider, gameConfig); ok {
+		return intent, true
+	}
+	return focusFireIntent(world, actingEntry, targetSelector, partInfoProvider, gameConfig)
+}
+
+// leaderInDanger は、リーダーの頭部の装甲の割合が ratio 以下かを返します。
+func leaderInDanger(leader *donburi.Entry, partInfoProvider PartInfoProviderInterface, ratio float64) bool {
+	head := component.PartsComponent.Get(leader).Map[core.PartSlotHead]
+	if head == nil || head.IsBroken {
+		return false
+	}
+	partDef, found := partInfoProvider.GetGameDataManager().GetPartDefinition(head.DefinitionID)
+	if !found || partDef.MaxArmor <= 0 {
+		return false
+	}
+	return float64(head.CurrentArmor)/float64(partDef.MaxArmor) <= ratio
+}
+
+// TeamIntentStrategy はチームの指示に従ってターゲットを選びます。
+// 指示がない場合や、指示されたターゲットを狙えない場合は Fallback の戦略を使います。
+type TeamIntentStrategy struct {
+	Fallback TargetingStrategy
+}
+
+func (s *TeamIntentStrategy) SelectTarget(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	rand *rand.Rand,
+) (*donburi.Entry, core.PartSlotKey) {
+	if intent, ok := TeamIntentFor(world, actingEntry); ok && targetSelector.IsOpponent(actingEntry, intent.Target) {
+		slot := intent.TargetPartSlot
+		if slot == "" {
+			if targetPart := targetSelector.SelectPartToDamage(intent.Target, actingEntry, rand); targetPart != nil {
+				slot = partInfoProvider.FindPartSlot(intent.Target, targetPart)
+			}
+		}
+		if slot != "" {
+			log.Printf("AI戦略 [チーム]: %s がチームの指示（%s）に従い %s の %s を狙います。",
+				component.SettingsComponent.Get(actingEntry).Name, intent.Kind, component.SettingsComponent.Get(intent.Target).Name, slot)
+			return intent.Target, slot
+		}
+	}
+	log.Printf("AI戦略 [チーム]: チームの指示がないため、代わりの戦略を使います。")
+	return s.Fallback.SelectTarget(world, actingEntry, targetSelector, partInfoProvider, rand)
+}
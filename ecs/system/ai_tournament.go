@@ -0,0 +1,239 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// tournamentEntrant はトーナメントの参加者と、その成績の集計です。
+type tournamentEntrant struct {
+	name        string
+	personality string
+	difficulty  core.AIDifficulty
+	rating      core.TournamentRating
+}
+
+// RunAITournament は、AIの性格と難易度の組み合わせを総当たりのヘッドレス戦闘で戦わせ、
+// Eloレーティングと対戦成績を返します。
+// 両チームはプレイヤーチームと同じ構成の機体で戦い、チームの全機体が参加者の性格と難易度で行動します。
+// 1組ごとに GamesPerPairing 試合を左右の陣営を入れ替えながら行い、各試合の乱数は seed から決まるため、
+// 同じ seed・設定・データからは同じ結果が得られます（先読みAIも時間ではなく回数の上限で探索します）。
+func RunAITournament(res *data.SharedResources, seed int64) (*core.TournamentResult, error) {
+	settings := res.Config.Tournament
+	entrants, err := tournamentEntrants(res.GameDataManager, settings.Entrants, settings.InitialRating)
+	if err != nil {
+		return nil, err
+	}
+	if len(entrants) < 2 {
+		return nil, fmt.Errorf("トーナメントには2人以上の参加者が必要です (現在: %d人)", len(entrants))
+	}
+	if settings.GamesPerPairing <= 0 {
+		return nil, fmt.Errorf("1組あたりの試合数 %d が不正です", settings.GamesPerPairing)
+	}
+
+	baseSetup, err := data.NewBattleSetupFromGameData(res.GameData, core.Team1)
+	if err != nil {
+		return nil, fmt.Errorf("対戦に使う機体構成を組み立てられません: %w", err)
+	}
+	if err := data.ValidateBattleSetupLoads(baseSetup, res.GameDataManager, &res.Config); err != nil {
+		return nil, fmt.Errorf("積載量の上限を超える機体がいるため対戦できません: %w", err)
+	}
+	setup := tournamentSetup(baseSetup)
+
+	config := headlessConfig(res.Config)
+
+	headToHead := make(map[[2]int]*core.TournamentHeadToHead)
+	seeds := rand.New(rand.NewSource(seed))
+	for i := 0; i < len(entrants); i++ {
+		for j := i + 1; j < len(entrants); j++ {
+			for game := 0; game < settings.GamesPerPairing; game++ {
+				// 陣営の有利不利をなくすため、試合ごとに左右を入れ替えます。
+				sides := [2]int{i, j}
+				if game%2 == 1 {
+					sides = [2]int{j, i}
+				}
+				winner := runTournamentGame(res, config, setup, entrants, sides, seeds.Int63())
+				recordTournamentGame(entrants, headToHead, sides, winner, settings.KFactor)
+			}
+			record := tournamentHeadToHead(headToHead, entrants, i, j)
+			log.Printf("トーナメント: %s vs %s - %d勝 %d分 %d敗", entrants[i].name, entrants[j].name, record.Wins, record.Draws, record.Losses)
+		}
+	}
+
+	result := &core.TournamentResult{Seed: seed}
+	for i, entrant := range entrants {
+		result.Ratings = append(result.Ratings, entrant.rating)
+		for j := range entrants {
+			if i != j {
+				result.HeadToHead = append(result.HeadToHead, *tournamentHeadToHead(headToHead, entrants, i, j))
+			}
+		}
+	}
+	sort.SliceStable(result.Ratings, func(a, b int) bool { return result.Ratings[a].Rating > result.Ratings[b].Rating })
+	return result, nil
+}
+
+// tournamentEntrants は設定の参加者を検証し、トーナメントの参加者の一覧を作ります。
+// 設定に参加者がない場合は、定義されているすべての性格が normal で参加します。
+func tournamentEntrants(gdm *data.GameDataManager, configs []data.TournamentEntrantConfig, initialRating float64) ([]*tournamentEntrant, error) {
+	if len(configs) == 0 {
+		for _, def := range gdm.GetAllPersonalityDefinitions() {
+			configs = append(configs, data.TournamentEntrantConfig{Personality: def.ID})
+		}
+	}
+
+	var entrants []*tournamentEntrant
+	var errs []error
+	names := make(map[string]bool)
+	for _, cfg := range configs {
+		difficulty := cfg.Difficulty
+		if difficulty == "" {
+			difficulty = core.AIDifficultyNormal
+		}
+		name := cfg.Name
+		if name == "" {
+			name = cfg.Personality
+			if difficulty != core.AIDifficultyNormal {
+				name = fmt.Sprintf("%s(%s)", cfg.Personality, difficulty)
+			}
+		}
+
+		if _, ok := PersonalityRegistry[cfg.Personality]; !ok {
+			errs = append(errs, fmt.Errorf("参加者 %s の性格 %s が定義されていません", name, cfg.Personality))
+		}
+		if !slices.Contains(core.AIDifficulties, difficulty) {
+			errs = append(errs, fmt.Errorf("参加者 %s の難易度 %s は不正です", name, difficulty))
+		}
+		if names[name] {
+			errs = append(errs, fmt.Errorf("参加者の名前 %s が重複しています", name))
+		}
+		names[name] = true
+
+		entrants = append(entrants, &tournamentEntrant{
+			name:        name,
+			personality: cfg.Personality,
+			difficulty:  difficulty,
+			rating:      core.TournamentRating{Entrant: name, Rating: initialRating},
+		})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return entrants, nil
+}
+
+// tournamentSetup は、プレイヤーチームの機体構成をそのまま2チームに複製した編成を返します。
+// どちらのチームもプレイヤーが操作しないため、全機体がAIとして生成されます。
+func tournamentSetup(base *core.BattleSetup) *core.BattleSetup {
+	var medarots []core.MedarotData
+	for _, teamSetup := range base.Teams {
+		if teamSetup.Team == base.PlayerTeam {
+			medarots = teamSetup.Medarots
+		}
+	}
+
+	setup := &core.BattleSetup{PlayerTeam: core.TeamNone, StageID: base.StageID}
+	for _, team := range []core.TeamID{core.Team1, core.Team2} {
+		teamMedarots := make([]core.MedarotData, len(medarots))
+		copy(teamMedarots, medarots)
+		for k := range teamMedarots {
+			teamMedarots[k].ID = fmt.Sprintf("%s-T%d", teamMedarots[k].ID, int(team)+1)
+		}
+		setup.Teams = append(setup.Teams, core.TeamSetup{Team: team, Medarots: teamMedarots})
+	}
+	data.NormalizeBattleSetup(setup)
+	return setup
+}
+
+// runTournamentGame は sides[0] をチーム1、sides[1] をチーム2として1試合を行い、勝った側（0 または 1）を返します。
+// 引き分けや上限ティック数までに決着がつかなかった場合は -1 を返します。
+func runTournamentGame(res *data.SharedResources, config *data.Config, setup *core.BattleSetup, entrants []*tournamentEntrant, sides [2]int, seed int64) int {
+	var result core.GameEndResult
+	withLogsSilenced(func() {
+		world := donburi.NewWorld()
+		entity.InitializeBattleWorld(world, res, setup)
+		query.NewQuery(filter.Contains(component.AIComponent, component.SettingsComponent)).Each(world, func(entry *donburi.Entry) {
+			entrant := entrants[sides[0]]
+			if component.SettingsComponent.Get(entry).Team == core.Team2 {
+				entrant = entrants[sides[1]]
+			}
+			ai := component.AIComponent.Get(entry)
+			ai.PersonalityID = entrant.personality
+			ai.Difficulty = entrant.difficulty
+			ai.BehaviorTreeID = "" // 参加者の性格どおりに行動させます
+		})
+
+		sim := NewBattleSimulator(world, config, res.GameDataManager, rand.New(rand.NewSource(seed)))
+		result = sim.RunMatch(res.Config.Tournament.MaxTicks)
+	})
+	switch {
+	case !result.IsGameOver:
+		return -1
+	case result.Winner == core.Team1:
+		return 0
+	case result.Winner == core.Team2:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// recordTournamentGame は1試合の結果を成績と対戦成績に加え、両者のEloレーティングを更新します。
+func recordTournamentGame(entrants []*tournamentEntrant, headToHead map[[2]int]*core.TournamentHeadToHead, sides [2]int, winner int, kFactor float64) {
+	a, b := entrants[sides[0]], entrants[sides[1]]
+	record := tournamentHeadToHead(headToHead, entrants, sides[0], sides[1])
+	reverse := tournamentHeadToHead(headToHead, entrants, sides[1], sides[0])
+
+	score := 0.5
+	switch winner {
+	case 0:
+		score = 1.0
+		a.rating.Wins++
+		b.rating.Losses++
+		record.Wins++
+		reverse.Losses++
+	case 1:
+		score = 0.0
+		a.rating.Losses++
+		b.rating.Wins++
+		record.Losses++
+		reverse.Wins++
+	default:
+		a.rating.Draws++
+		b.rating.Draws++
+		record.Draws++
+		reverse.Draws++
+	}
+	a.rating.Games++
+	b.rating.Games++
+
+	expected := 1.0 / (1.0 + math.Pow(10, (b.rating.Rating-a.rating.Rating)/400))
+	delta := kFactor * (score - expected)
+	a.rating.Rating += delta
+	b.rating.Rating -= delta
+}
+
+// tournamentHeadToHead は、参加者 i から見た参加者 j との対戦成績を返します。まだなければ作成します。
+func tournamentHeadToHead(headToHead map[[2]int]*core.TournamentHeadToHead, entrants []*tournamentEntrant, i, j int) *core.TournamentHeadToHead {
+	key := [2]int{i, j}
+	record, ok := headToHead[key]
+	if !ok {
+		record = &core.TournamentHeadToHead{Entrant: entrants[i].name, Opponent: entrants[j].name}
+		headToHead[key] = record
+	}
+	return record
+}
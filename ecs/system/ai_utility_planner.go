@@ -0,0 +1,253 @@
+package system
+
+import (
+	"log"
+	"math"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+
+	"github.com/yohamta/donburi"
+)
+
+// UtilityWeights は UtilityPlanner が行動を評価する際の重みです。
+type UtilityWeights struct {
+	Damage    float64 // 狙ったパーツへの期待ダメージ1あたりの評価
+	Break     float64 // 狙ったパーツの破壊確率1.0あたりの評価
+	HeadBreak float64 // 頭部を狙う場合に、破壊確率1.0あたりに加える評価（頭部の破壊は機能停止になるため）
+	Tempo     float64 // 行動にかかる時間（チャージ＋クールダウン）で評価を割る度合い。0 で時間を考慮せず、1 で時間あたりの評価になる
+
+	LeaderFocus float64 // 敵リーダーを狙う行動の評価に掛ける倍率。0 の場合は補正しない
+	TeamIntent  float64 // チームの指示どおりのターゲット（パーツの指定があればそのパーツ）を狙う行動の評価に掛ける倍率。0 の場合は補正しない（性格の Weights で指定した場合だけ使います）
+}
+
+// DefaultUtilityWeights は期待値で行動を評価する性格や難易度が共通して使う重みです。
+var DefaultUtilityWeights = UtilityWeights{
+	Damage:    1.0,
+	Break:     30.0,
+	HeadBreak: 60.0,
+	Tempo:     0.5,
+}
+
+// UtilityPlanner は、利用可能なパーツと狙える敵パーツのすべての組み合わせについて、
+// 命中確率・防御確率・期待ダメージ・破壊確率・行動時間から評価値を求め、最も評価の高い行動を選びます。
+// 確率やダメージは計算機の Preview 系のメソッドで求めるため、乱数は消費しません。
+type UtilityPlanner struct {
+	Weights UtilityWeights
+}
+
+// utilityCandidate は評価する行動1つ分の候補です。
+type utilityCandidate struct {
+	available      core.AvailablePart
+	targetEntry    *donburi.Entry
+	targetPartSlot core.PartSlotKey
+	targetPartInst *core.PartInstanceData
+}
+
+// PlanAction は AIActionPlanner インターフェースを実装します。
+// 射撃は狙える敵パーツごとに評価し、格闘は実行時に最も近い敵のいずれかのパーツを攻撃するため、そのパーツすべての評価の平均を使います。
+// 介入パーツは評価の対象外です。攻撃できる候補がない場合は false を返します。
+func (p *UtilityPlanner) PlanAction(
+	world donburi.World,
+	actingEntry *donburi.Entry,
+	availableParts []core.AvailablePart,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+	gameConfig *data.Config,
+) (AIActionPlan, bool) {
+	bestPlan, bestScore, ok := p.bestPlan(actingEntry, availableParts, targetSelector, partInfoProvider, hitCalculator, damageCalculator)
+	if !ok {
+		return AIActionPlan{}, false
+	}
+	targetName := "（実行時に決定）"
+	if bestPlan.TargetEntry != nil {
+		targetName = component.SettingsComponent.Get(bestPlan.TargetEntry).Name + " " + string(bestPlan.TargetPartSlot)
+	}
+	log.Printf("%s: AIは評価値 %.2f で %s を選択（ターゲット: %s）。", component.SettingsComponent.Get(actingEntry).Name, bestScore, bestPlan.PartDef.PartName, targetName)
+	return bestPlan, true
+}
+
+// bestPlan は評価値が最も高い行動とその評価値を返します。攻撃できる候補がない場合は false を返します。
+func (p *UtilityPlanner) bestPlan(
+	actingEntry *donburi.Entry,
+	availableParts []core.AvailablePart,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+) (AIActionPlan, float64, bool) {
+	var bestPlan AIActionPlan
+	bestScore := math.Inf(-1)
+
+	for _, available := range availableParts {
+		switch available.PartDef.Category {
+		case core.CategoryRanged:
+			for _, targetPart := range getAllTargetableParts(actingEntry, targetSelector, partInfoProvider, true) {
+				score := p.evaluate(actingEntry, utilityCandidate{
+					available:      available,
+					targetEntry:    targetPart.Entity,
+					targetPartSlot: targetPart.Slot,
+					targetPartInst: targetPart.PartInst,
+				}, targetSelector, partInfoProvider, hitCalculator, damageCalculator)
+				if score > bestScore {
+					bestScore = score
+					bestPlan = AIActionPlan{Slot: available.Slot, PartDef: available.PartDef, TargetEntry: targetPart.Entity, TargetPartSlot: targetPart.Slot}
+				}
+			}
+		case core.CategoryMelee:
+			if score, ok := p.evaluateMelee(actingEntry, available, targetSelector, partInfoProvider, hitCalculator, damageCalculator); ok && score > bestScore {
+				bestScore = score
+				bestPlan = AIActionPlan{Slot: available.Slot, PartDef: available.PartDef}
+			}
+		}
+	}
+
+	if bestPlan.PartDef == nil {
+		return AIActionPlan{}, 0, false
+	}
+	return bestPlan, bestScore, true
+}
+
+// evaluatePlan は決まった行動の評価値を返します。評価できない行動（介入やターゲットのない射撃）の場合は false を返します。
+func (p *UtilityPlanner) evaluatePlan(
+	actingEntry *donburi.Entry,
+	plan AIActionPlan,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+) (float64, bool) {
+	available := core.AvailablePart{PartDef: plan.PartDef, Slot: plan.Slot}
+	switch plan.PartDef.Category {
+	case core.CategoryRanged:
+		if plan.TargetEntry == nil || !plan.TargetEntry.Valid() {
+			return 0, false
+		}
+		partInst := component.PartsComponent.Get(plan.TargetEntry).Map[plan.TargetPartSlot]
+		if partInst == nil || partInst.IsBroken {
+			return 0, false
+		}
+		return p.evaluate(actingEntry, utilityCandidate{
+			available:      available,
+			targetEntry:    plan.TargetEntry,
+			targetPartSlot: plan.TargetPartSlot,
+			targetPartInst: partInst,
+		}, targetSelector, partInfoProvider, hitCalculator, damageCalculator), true
+	case core.CategoryMelee:
+		return p.evaluateMelee(actingEntry, available, targetSelector, partInfoProvider, hitCalculator, damageCalculator)
+	}
+	return 0, false
+}
+
+// evaluateMelee は格闘の評価値として、最も近い敵の破壊されていないパーツすべての評価の平均を返します。
+func (p *UtilityPlanner) evaluateMelee(
+	actingEntry *donburi.Entry,
+	available core.AvailablePart,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+) (float64, bool) {
+	closestEnemy := targetSelector.FindClosestEnemy(actingEntry)
+	if closestEnemy == nil {
+		return 0, false
+	}
+	total, count := 0.0, 0
+	for slot, partInst := range component.PartsComponent.Get(closestEnemy).Map {
+		if partInst == nil || partInst.IsBroken {
+			continue
+		}
+		total += p.evaluate(actingEntry, utilityCandidate{
+			available:      available,
+			targetEntry:    closestEnemy,
+			targetPartSlot: slot,
+			targetPartInst: partInst,
+		}, targetSelector, partInfoProvider, hitCalculator, damageCalculator)
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return total / float64(count), true
+}
+
+// evaluate は1つの候補の評価値を求めます。
+func (p *UtilityPlanner) evaluate(
+	actingEntry *donburi.Entry,
+	c utilityCandidate,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+) float64 {
+	partDef := c.available.PartDef
+	hitRate := hitCalculator.PreviewHitChance(actingEntry, c.targetEntry, partDef, c.available.Slot) / 100
+
+	// 防御できるパーツがあり、スキャンで防御を封じられていなければ防御される可能性があります。
+	defenseRate := 0.0
+	defendingPartInst := targetSelector.SelectDefensePart(c.targetEntry)
+	blockedByScan := c.targetEntry.HasComponent(component.ScanMarkComponent) && component.ScanMarkComponent.Get(c.targetEntry).DefenseBlocked
+	if defendingPartInst != nil && !blockedByScan {
+		defenseRate = hitCalculator.PreviewDefenseChance(actingEntry, c.targetEntry, partDef, c.available.Slot) / 100
+	}
+
+	// 攻撃パターンによるダメージの増減（連続攻撃はヒット数分、範囲攻撃のターゲットは等倍）
+	patternScale := attackPatternDamageScale(partInfoProvider.GetAttackPattern(partDef))
+	undefendedDamage := damageCalculator.PreviewDamage(actingEntry, c.targetEntry, partDef, c.available.Slot, false) * patternScale
+	defendedDamage := damageCalculator.PreviewDamage(actingEntry, c.targetEntry, partDef, c.available.Slot, true) * patternScale
+
+	// 狙ったパーツへの期待ダメージ（装甲を超えた分は数えない）と破壊確率
+	armor := float64(c.targetPartInst.CurrentArmor)
+	expectedDamage := (1 - defenseRate) * math.Min(undefendedDamage, armor)
+	breakChance := (1 - defenseRate) * breakProbability(undefendedDamage, armor)
+	if defendingPartInst == c.targetPartInst {
+		// 狙ったパーツ自体で防御した場合も、軽減されたダメージは狙ったパーツに入ります。
+		expectedDamage += defenseRate * math.Min(defendedDamage, armor)
+		breakChance += defenseRate * breakProbability(defendedDamage, armor)
+	}
+	expectedDamage *= hitRate
+	breakChance *= hitRate
+
+	value := p.Weights.Damage*expectedDamage + p.Weights.Break*breakChance
+	if c.targetPartSlot == core.PartSlotHead {
+		value += p.Weights.HeadBreak * breakChance
+	}
+	if p.Weights.LeaderFocus > 0 && component.SettingsComponent.Get(c.targetEntry).IsLeader {
+		value *= p.Weights.LeaderFocus
+	}
+	if p.Weights.TeamIntent > 0 {
+		if intent, ok := TeamIntentFor(targetSelector.world, actingEntry); ok && intent.Target == c.targetEntry &&
+			(intent.TargetPartSlot == "" || intent.TargetPartSlot == c.targetPartSlot) {
+			value *= p.Weights.TeamIntent
+		}
+	}
+
+	// 行動にかかる時間で割り、早く行動できるパーツを評価します。
+	ticks := partInfoProvider.CalculateGaugeDuration(float64(partDef.Charge), actingEntry) +
+		partInfoProvider.CalculateGaugeDuration(float64(partDef.Cooldown), actingEntry)
+	return value / math.Pow(math.Max(ticks, 1), p.Weights.Tempo)
+}
+
+// attackPatternDamageScale は、攻撃パターンによってターゲットに与えるダメージがおおよそ何倍になるかを返します。
+func attackPatternDamageScale(pattern data.AttackPatternConfig) float64 {
+	switch pattern.Pattern {
+	case core.AttackPatternMultiHit:
+		return pattern.DamageScale * float64(pattern.Hits)
+	case core.AttackPatternSpread:
+		// 合計ダメージは倍率分増えますが、狙ったパーツには分け合った分しか入りません。
+		return pattern.DamageScale / float64(max(pattern.Hits, 1))
+	default:
+		return 1.0
+	}
+}
+
+// breakProbability は、ダメージの乱数幅（±10%）を考慮して、期待ダメージで装甲を削り切る確率を返します。
+func breakProbability(damage, armor float64) float64 {
+	if damage <= 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (1.1-armor/damage)/0.2))
+}
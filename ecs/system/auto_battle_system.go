@@ -0,0 +1,55 @@
+package system
+
+import (
+	"log"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/ecs/component"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// SetAutoBattleSystem は、プレイヤーの機体のオートバトルを切り替えます。
+// 有効にするとプレイヤーの機体にAIコンポーネントを追加し、以降の行動選択はAIが行います。
+// 無効にするとAIコンポーネントを取り除き、機体が次にアイドル状態になったときからプレイヤーの操作に戻ります。
+// チャージ中・クールダウン中の行動はそのまま続きます。
+func SetAutoBattleSystem(world donburi.World, enabled bool) {
+	// 繰り返しの途中でコンポーネントを追加・削除しないよう、対象の機体を先に集めます。
+	var entries []*donburi.Entry
+	query.NewQuery(filter.Contains(component.PlayerControlComponent, component.SettingsComponent)).Each(world, func(entry *donburi.Entry) {
+		if entry.HasComponent(component.AIComponent) != enabled {
+			entries = append(entries, entry)
+		}
+	})
+
+	for _, entry := range entries {
+		if !enabled {
+			entry.RemoveComponent(component.AIComponent)
+			continue
+		}
+		personalityID := autoBattlePersonalityID(entry)
+		donburi.Add(entry, component.AIComponent, &component.AI{
+			PersonalityID:     personalityID,
+			TargetHistory:     component.TargetHistoryData{},
+			LastActionHistory: component.LastActionHistoryData{},
+			Difficulty:        core.AIDifficultyNormal, // プレイヤーの機体には難易度の補正をかけません
+		})
+		log.Printf("%s: オートバトルの性格は %s です。", component.SettingsComponent.Get(entry).Name, personalityID)
+	}
+}
+
+// autoBattlePersonalityID は、プレイヤーの機体がオートバトルで使う性格を返します。
+// 機体に指定された性格が定義されていない場合は、メダルの性格を使います。
+func autoBattlePersonalityID(entry *donburi.Entry) string {
+	personalityID := component.PlayerControlComponent.Get(entry).AutoBattlePersonality
+	if personalityID == "" {
+		return component.MedalComponent.Get(entry).Personality
+	}
+	if _, ok := PersonalityRegistry[personalityID]; !ok {
+		log.Printf("%s: オートバトルの性格 %s が定義されていません。メダルの性格を使用します。", component.SettingsComponent.Get(entry).Name, personalityID)
+		return component.MedalComponent.Get(entry).Personality
+	}
+	return personalityID
+}
@@ -22,7 +22,8 @@ type ActionExecutor struct {
 	statusEffectSystem     *StatusEffectSystem
 	postActionEffectSystem *PostActionEffectSystem // 新しく追加したシステム
 	handlers               map[core.Trait]TraitActionHandler
-	weaponHandlers         map[core.WeaponType]WeaponTypeEffectHandler // WeaponTypeごとのハンドラを追加
+	weaponHandlers         map[core.WeaponType]WeaponTypeEffectHandler   // WeaponTypeごとのハンドラを追加
+	setSkillHandlers       map[core.SetSkillType]WeaponTypeEffectHandler // パーツセットの固有スキル
 	rand                   *rand.Rand
 }
 
@@ -40,17 +41,24 @@ func NewActionExecutor(world donburi.World, damageCalculator *DamageCalculator,
 		rand:                   rand,
 
 		handlers: map[core.Trait]TraitActionHandler{
-			core.TraitShoot:    &BaseAttackHandler{},
-			core.TraitAim:      &BaseAttackHandler{},
-			core.TraitStrike:   &BaseAttackHandler{},
-			core.TraitBerserk:  &BaseAttackHandler{},
+			core.TraitShoot:    &BaseAttackHandler{config: gameConfig},
+			core.TraitAim:      &BaseAttackHandler{config: gameConfig},
+			core.TraitStrike:   &BaseAttackHandler{config: gameConfig},
+			core.TraitBerserk:  &BaseAttackHandler{config: gameConfig},
 			core.TraitSupport:  &SupportTraitExecutor{},
 			core.TraitObstruct: &ObstructTraitExecutor{},
+			core.TraitRepair:   &RepairTraitExecutor{},
 		},
 		weaponHandlers: map[core.WeaponType]WeaponTypeEffectHandler{
 			// 将来の拡張に備え、ここにハンドラを登録していく
 			// 例: WeaponTypeThunder: &ThunderEffectHandler{},
 			// 例: WeaponTypeMelt:    &MeltEffectHandler{},
+			core.WeaponTypeScan: &ScanEffectHandler{config: gameConfig},
+		},
+		setSkillHandlers: map[core.SetSkillType]WeaponTypeEffectHandler{
+			core.SetSkillThunder: &ThunderEffectHandler{},
+			core.SetSkillMelt:    &MeltEffectHandler{},
+			core.SetSkillVirus:   &VirusEffectHandler{},
 		},
 	}
 }
@@ -61,15 +69,32 @@ func (e *ActionExecutor) ExecuteAction(actingEntry *donburi.Entry) component.Act
 	partsComp := component.PartsComponent.Get(actingEntry)
 	actingPartInst := partsComp.Map[intent.SelectedPartKey]
 
+	reselectedFrom := ""
 	if actingPartInst == nil || actingPartInst.IsBroken {
+		brokenPartName := ""
+		if actingPartInst != nil {
+			if brokenPartDef, found := e.partInfoProvider.GetGameDataManager().GetPartDefinition(actingPartInst.DefinitionID); found {
+				brokenPartName = brokenPartDef.PartName
+			}
+		}
 		log.Printf("%s は行動しようとしたが、パーツ %s が壊れていた。", component.SettingsComponent.Get(actingEntry).Name, intent.SelectedPartKey)
-		return component.ActionResult{
-			ActingEntry:  actingEntry,
-			ActionDidHit: false,
+
+		actingPartInst = e.reselectActingPart(actingEntry, intent, actingPartInst)
+		if actingPartInst == nil {
+			return component.ActionResult{
+				ActingEntry:  actingEntry,
+				ActionDidHit: false,
+				IsCancelled:  true,
+				AttackerName: component.SettingsComponent.Get(actingEntry).Name,
+				ActionName:   brokenPartName,
+			}
 		}
+		reselectedFrom = brokenPartName
 	}
 	actingPartDef, _ := e.partInfoProvider.GetGameDataManager().GetPartDefinition(actingPartInst.DefinitionID)
 
+	retargeted := e.retargetIfTargetLost(actingEntry, actingPartDef)
+
 	handler, ok := e.handlers[actingPartDef.Trait]
 	if !ok {
 		log.Printf("未対応のTraitです: %s", actingPartDef.Trait)
@@ -80,6 +105,8 @@ func (e *ActionExecutor) ExecuteAction(actingEntry *donburi.Entry) component.Act
 	}
 
 	actionResult := handler.Execute(actingEntry, e.world, intent, e.damageCalculator, e.hitCalculator, e.targetSelector, e.partInfoProvider, actingPartDef, e.rand)
+	actionResult.ReselectedFrom = reselectedFrom
+	actionResult.IsRetargeted = retargeted
 
 	// チャージ時に生成された保留中の効果をActionResultにコピー
 	if len(intent.PendingEffects) > 0 {
@@ -88,13 +115,149 @@ func (e *ActionExecutor) ExecuteAction(actingEntry *donburi.Entry) component.Act
 		intent.PendingEffects = nil
 	}
 
+	// 計算式に定義されたチームバフ・デバフを付与（支援・妨害など）
+	if formula, ok := e.partInfoProvider.GetGameDataManager().Formulas[actingPartDef.Trait]; ok && len(formula.TeamBuffs) > 0 && actionResult.ActionDidHit {
+		actionResult.AppliedTeamBuffs = ApplyTeamBuffs(e.world, e.gameConfig, actingEntry, intent.SelectedPartKey, actingPartDef, formula.TeamBuffs, actionResult.TargetEntry)
+	}
+
 	// WeaponType に基づく追加効果を適用 (Traitの処理から独立)
 	if weaponHandler, ok := e.weaponHandlers[actingPartDef.WeaponType]; ok {
 		weaponHandler.ApplyEffect(&actionResult, e.world, e.damageCalculator, e.hitCalculator, e.targetSelector, e.partInfoProvider, actingPartDef, e.rand)
 	}
 
+	// パーツセットを揃えている場合は、命中した攻撃（射撃・格闘）に固有スキルを適用
+	isAttack := actingPartDef.Category == core.CategoryRanged || actingPartDef.Category == core.CategoryMelee
+	if setBonus := e.partInfoProvider.GetSetBonus(actingEntry); isAttack && actionResult.ActionDidHit && setBonus != nil && setBonus.UniqueSkill != core.SetSkillNone {
+		if skillHandler, ok := e.setSkillHandlers[setBonus.UniqueSkill]; ok {
+			skillHandler.ApplyEffect(&actionResult, e.world, e.damageCalculator, e.hitCalculator, e.targetSelector, e.partInfoProvider, actingPartDef, e.rand)
+		} else {
+			log.Printf("未対応のセット固有スキルです: %s", setBonus.UniqueSkill)
+		}
+	}
+
 	// アクション後の共通処理を実行
 	e.postActionEffectSystem.Process(&actionResult)
 
 	return actionResult
 }
+
+// reselectActingPart は、チャージ中のパーツが破壊されていた場合に設定に従って代わりのパーツを選びます。
+// 選び直しが有効で、破壊されていない行動パーツが残っていれば、同じカテゴリのパーツを優先して選択し、
+// intent の選択スロットと、新しいパーツのカテゴリに応じたターゲットを更新してそのインスタンスを返します。行動を取り消す場合は nil を返します。
+func (e *ActionExecutor) reselectActingPart(actingEntry *donburi.Entry, intent *core.ActionIntent, brokenPartInst *core.PartInstanceData) *core.PartInstanceData {
+	if e.gameConfig.ActionInterruption.BrokenPartPolicy != core.BrokenPartPolicyReselect {
+		return nil
+	}
+
+	var brokenCategory core.PartCategory
+	if brokenPartInst != nil {
+		if brokenPartDef, found := e.partInfoProvider.GetGameDataManager().GetPartDefinition(brokenPartInst.DefinitionID); found {
+			brokenCategory = brokenPartDef.Category
+		}
+	}
+
+	partsComp := component.PartsComponent.Get(actingEntry)
+	var selected *core.AvailablePart
+	for _, available := range e.partInfoProvider.GetAvailableAttackParts(actingEntry) {
+		partInst := partsComp.Map[available.Slot]
+		if partInst == nil || partInst.IsBroken {
+			continue
+		}
+		if selected == nil || (available.PartDef.Category == brokenCategory && selected.PartDef.Category != brokenCategory) {
+			candidate := available
+			selected = &candidate
+		}
+	}
+	if selected == nil {
+		return nil
+	}
+
+	log.Printf("%s は行動を %s (%s) に切り替えた。", component.SettingsComponent.Get(actingEntry).Name, selected.PartDef.PartName, selected.Slot)
+	intent.SelectedPartKey = selected.Slot
+	e.retargetForPart(actingEntry, selected.PartDef)
+	return partsComp.Map[selected.Slot]
+}
+
+// retargetForPart は、選び直したパーツのカテゴリに合わせてターゲット決定方針とターゲットを決め直します。
+// 格闘は実行時に最も近い敵を狙い、修復は最も傷んだ味方のパーツを、射撃とその他の介入はチャージ時の敵が残っていればその敵を、
+// いなければ最も近い敵を狙います。
+func (e *ActionExecutor) retargetForPart(actingEntry *donburi.Entry, partDef *core.PartDefinition) {
+	targetComp := component.TargetComponent.Get(actingEntry)
+	targetComp.Policy = TargetingPolicyFor(partDef.Category)
+
+	switch {
+	case partDef.Category == core.CategoryMelee:
+		targetComp.TargetEntity = 0
+		targetComp.TargetPartSlot = ""
+	case partDef.Trait == core.TraitRepair:
+		targetComp.TargetEntity = 0
+		targetComp.TargetPartSlot = ""
+		if ally, slot := FindMostDamagedAllyPart(actingEntry, e.targetSelector, e.partInfoProvider, 1.0); ally != nil {
+			targetComp.TargetEntity = ally.Entity()
+			targetComp.TargetPartSlot = slot
+		}
+	default:
+		var enemy *donburi.Entry
+		slot := targetComp.TargetPartSlot
+		if targetComp.TargetEntity != 0 {
+			if entry := e.world.Entry(targetComp.TargetEntity); entry != nil && entry.Valid() &&
+				component.StateComponent.Get(entry).CurrentState != core.StateBroken && e.targetSelector.IsOpponent(actingEntry, entry) {
+				enemy = entry
+			}
+		}
+		if enemy == nil {
+			enemy, slot = e.targetSelector.FindClosestEnemy(actingEntry), ""
+		}
+		targetComp.TargetEntity = 0
+		targetComp.TargetPartSlot = ""
+		if enemy == nil {
+			return
+		}
+		targetComp.TargetEntity = enemy.Entity()
+		if partDef.Category != core.CategoryRanged {
+			return
+		}
+		// 射撃はパーツを狙うため、チャージ時に狙っていた部位が残っていればそのまま、なければ性格のルールで選び直します。
+		if partInst := component.PartsComponent.Get(enemy).Map[slot]; partInst == nil || partInst.IsBroken {
+			slot = ""
+			if targetPart := e.targetSelector.SelectPartToDamage(enemy, actingEntry, e.rand); targetPart != nil {
+				slot = e.partInfoProvider.FindPartSlot(enemy, targetPart)
+			}
+		}
+		targetComp.TargetPartSlot = slot
+	}
+}
+
+// retargetIfTargetLost は、射撃のターゲットがチャージ中に機能停止していた場合、設定に従って最寄りの敵へ狙いを変えます。
+// 狙いを変えた場合は true を返します。
+func (e *ActionExecutor) retargetIfTargetLost(actingEntry *donburi.Entry, actingPartDef *core.PartDefinition) bool {
+	if !e.gameConfig.ActionInterruption.RetargetRanged || actingPartDef.Category != core.CategoryRanged {
+		return false
+	}
+	targetComp := component.TargetComponent.Get(actingEntry)
+	if targetComp.Policy != core.PolicyPreselected || targetComp.TargetEntity == 0 {
+		return false
+	}
+	targetEntry := e.world.Entry(targetComp.TargetEntity)
+	if targetEntry != nil && targetEntry.Valid() && component.StateComponent.Get(targetEntry).CurrentState != core.StateBroken {
+		return false
+	}
+
+	newTarget := e.targetSelector.FindClosestEnemy(actingEntry)
+	if newTarget == nil {
+		return false
+	}
+	targetPart := e.targetSelector.SelectPartToDamage(newTarget, actingEntry, e.rand)
+	if targetPart == nil {
+		return false
+	}
+	slot := e.partInfoProvider.FindPartSlot(newTarget, targetPart)
+	if slot == "" {
+		return false
+	}
+
+	log.Printf("%s のターゲットが機能停止したため、%s に狙いを変えた。", component.SettingsComponent.Get(actingEntry).Name, component.SettingsComponent.Get(newTarget).Name)
+	targetComp.TargetEntity = newTarget.Entity()
+	targetComp.TargetPartSlot = slot
+	return true
+}
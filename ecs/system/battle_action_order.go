@@ -0,0 +1,76 @@
+package system
+
+import (
+	"log"
+	"sort"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/ecs/component"
+
+	"github.com/yohamta/donburi"
+)
+
+// readyAtEpsilon は準備完了時刻を同時とみなす誤差です。
+const readyAtEpsilon = 1e-9
+
+// SortActionQueue は実行キューを行動順の規則に従って並べ替えます。
+// 規則は次の順で適用され、前の規則で決まらない場合に次の規則で判定します。
+//  1. 準備完了時刻（端数ティックを含む）が早い機体
+//  2. 脚部の推進力が高い機体
+//  3. チームのイニシアチブを持つ機体（最後の行動が古い、または未行動のチーム）
+//  4. 準備完了時にシード付き乱数で決めたコイントス
+//
+// いずれの規則もアーキタイプの格納順に依存しないため、同じシードであれば戦闘結果は再現されます。
+func SortActionQueue(actionQueue *component.ActionQueueComponentData, partInfoProvider PartInfoProviderInterface) {
+	if partInfoProvider == nil {
+		log.Println("SortActionQueue: partInfoProviderがnilのため、推進力による順序付けを省略します")
+	}
+	queue := actionQueue.Queue
+	sort.SliceStable(queue, func(i, j int) bool {
+		a, b := queue[i], queue[j]
+		gaugeA := component.GaugeComponent.Get(a)
+		gaugeB := component.GaugeComponent.Get(b)
+
+		if diff := gaugeA.ReadyAt - gaugeB.ReadyAt; diff < -readyAtEpsilon || diff > readyAtEpsilon {
+			return diff < 0
+		}
+
+		if partInfoProvider != nil {
+			propA := partInfoProvider.GetOverallPropulsion(a)
+			propB := partInfoProvider.GetOverallPropulsion(b)
+			if propA != propB {
+				return propA > propB
+			}
+		}
+
+		seqA := actionQueue.TeamLastActionSeq[component.SettingsComponent.Get(a).Team]
+		seqB := actionQueue.TeamLastActionSeq[component.SettingsComponent.Get(b).Team]
+		if seqA != seqB {
+			return seqA < seqB
+		}
+
+		if gaugeA.TieBreaker != gaugeB.TieBreaker {
+			return gaugeA.TieBreaker < gaugeB.TieBreaker
+		}
+		return compareSettingsOrder(a, b)
+	})
+}
+
+// recordTeamAction は行動した機体のチームの最終行動を記録し、イニシアチブを他のチームへ移します。
+func recordTeamAction(actionQueue *component.ActionQueueComponentData, actingEntry *donburi.Entry) {
+	if actionQueue.TeamLastActionSeq == nil {
+		actionQueue.TeamLastActionSeq = make(map[core.TeamID]int)
+	}
+	actionQueue.ActionSeq++
+	actionQueue.TeamLastActionSeq[component.SettingsComponent.Get(actingEntry).Team] = actionQueue.ActionSeq
+}
+
+// compareSettingsOrder はチームと表示順による固定の並び順で a が b より前であれば true を返します。
+func compareSettingsOrder(a, b *donburi.Entry) bool {
+	settingsA := component.SettingsComponent.Get(a)
+	settingsB := component.SettingsComponent.Get(b)
+	if settingsA.Team != settingsB.Team {
+		return settingsA.Team < settingsB.Team
+	}
+	return settingsA.DrawIndex < settingsB.DrawIndex
+}
@@ -2,8 +2,8 @@ package system
 
 import (
 	"log"
+	"math"
 	"math/rand"
-	"sort"
 
 	"medarot-ebiten/core"
 	"medarot-ebiten/data"
@@ -31,29 +31,24 @@ func UpdateActionQueueSystem(
 	}
 	results := []component.ActionResult{}
 
-	sort.SliceStable(actionQueueComp.Queue, func(i, j int) bool {
-		if partInfoProvider == nil {
-			log.Println("UpdateActionQueueSystem: ソート中にpartInfoProviderがnilです")
-			return false
-		}
-		propI := partInfoProvider.GetOverallPropulsion(actionQueueComp.Queue[i])
-		propJ := partInfoProvider.GetOverallPropulsion(actionQueueComp.Queue[j])
-		return propI > propJ
-	})
+	SortActionQueue(actionQueueComp, partInfoProvider)
 
 	if len(actionQueueComp.Queue) > 0 {
 		actingEntry := actionQueueComp.Queue[0]
 		actionQueueComp.Queue = actionQueueComp.Queue[1:]
+		recordTeamAction(actionQueueComp, actingEntry)
 
 		executor := NewActionExecutor(world, damageCalculator, hitCalculator, targetSelector, partInfoProvider, gameConfig, statusEffectSystem, postActionEffectSystem, rand)
 		actionResult := executor.ExecuteAction(actingEntry)
+		// 解決した行動の命中を記録し、後に続く味方の攻撃でコンボを判定できるようにします。
+		RecordComboHits(world, gameConfig, &actionResult)
 		results = append(results, actionResult)
 	}
 	return results, nil
 }
 
 // StartCooldownSystem はクールダウン状態を開始します。
-func StartCooldownSystem(entry *donburi.Entry, world donburi.World, partInfoProvider PartInfoProviderInterface) {
+func StartCooldownSystem(entry *donburi.Entry, world donburi.World, config *data.Config, partInfoProvider PartInfoProviderInterface) {
 	intent := component.ActionIntentComponent.Get(entry)
 	partsComp := component.PartsComponent.Get(entry)
 	var actingPartDef *core.PartDefinition
@@ -75,6 +70,10 @@ func StartCooldownSystem(entry *donburi.Entry, world donburi.World, partInfoProv
 
 	// 新しい共通関数を呼び出す
 	totalTicks := partInfoProvider.CalculateGaugeDuration(baseSeconds, entry)
+	// 過積載の機体は放熱にも時間がかかります
+	totalTicks *= 1.0 + partInfoProvider.GetOverloadRatio(entry)*config.Load.CooldownPenaltyFactor
+	// チームバフ・デバフによるクールダウン速度の補正
+	totalTicks = math.Max(totalTicks/partInfoProvider.GetTeamBuffMultiplier(entry, core.BuffTypeCooldownSpeed), 1)
 
 	gauge := component.GaugeComponent.Get(entry)
 	gauge.TotalDuration = totalTicks
@@ -85,3 +84,17 @@ func StartCooldownSystem(entry *donburi.Entry, world donburi.World, partInfoProv
 	gauge.ProgressCounter = 0
 	state.CurrentState = core.StateCooldown
 }
+
+// CancelActionSystem は行動を取り消し、クールダウンを挟まずに待機状態へ戻します。
+// チャージ中のパーツが破壊されていた場合に使用します。
+func CancelActionSystem(entry *donburi.Entry) {
+	intent := component.ActionIntentComponent.Get(entry)
+	intent.PendingEffects = nil
+
+	gauge := component.GaugeComponent.Get(entry)
+	gauge.TotalDuration = 0
+	gauge.ProgressCounter = 0
+	gauge.CurrentGauge = 0
+
+	component.StateComponent.Get(entry).CurrentState = core.StateIdle
+}
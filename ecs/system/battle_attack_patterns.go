@@ -0,0 +1,179 @@
+package system
+
+import (
+	"math/rand"
+	"sort"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+
+	"github.com/yohamta/donburi"
+)
+
+// attackPatternContext は攻撃パターンの処理に必要なシステムと行動の情報をまとめたものです。
+type attackPatternContext struct {
+	actingEntry      *donburi.Entry
+	actingPartDef    *core.PartDefinition
+	selectedPartKey  core.PartSlotKey
+	damageCalculator *DamageCalculator
+	hitCalculator    *HitCalculator
+	targetSelector   *TargetSelector
+	partInfoProvider PartInfoProviderInterface
+	rand             *rand.Rand
+	combo            *comboBonus // コンボが成立する見込みの場合の効果。なければ nil
+}
+
+// resolveHit は1ヒット分の結果を求めます。コンボの相手が命中させた機体へのヒットにはコンボの効果を適用します。
+func (c *attackPatternContext) resolveHit(targetEntry *donburi.Entry, targetPartSlot core.PartSlotKey, damageScale float64) component.HitRecord {
+	guaranteedHit := false
+	if c.combo != nil && c.combo.target == targetEntry {
+		damageScale *= c.combo.damageMultiplier
+		guaranteedHit = c.combo.guaranteedHit
+	}
+	return resolveHit(c.actingEntry, targetEntry, targetPartSlot, c.actingPartDef, c.selectedPartKey, damageScale, guaranteedHit, c.damageCalculator, c.hitCalculator, c.targetSelector, c.partInfoProvider)
+}
+
+// executeAttackPattern は攻撃パターンに従ってヒットを発生させ、すべてのヒットの記録を返します。
+func executeAttackPattern(c *attackPatternContext, pattern data.AttackPatternConfig, targetEntry *donburi.Entry, targetPartSlot core.PartSlotKey) []component.HitRecord {
+	switch pattern.Pattern {
+	case core.AttackPatternMultiHit:
+		return executeMultiHit(c, pattern, targetEntry, targetPartSlot)
+	case core.AttackPatternSpread:
+		return executeSpread(c, pattern, targetEntry, targetPartSlot)
+	case core.AttackPatternArea:
+		return executeArea(c, pattern, targetEntry, targetPartSlot)
+	default:
+		return []component.HitRecord{c.resolveHit(targetEntry, targetPartSlot, 1.0)}
+	}
+}
+
+// executeMultiHit は同じ敵に Hits 回の攻撃を行います。命中・防御の判定は1ヒットごとに行います。
+// ダメージはまだ適用されていないため、それまでのヒットで壊れる予定のパーツは避けて次のパーツを狙い、
+// 頭部が壊れる予定になった時点で残りのヒットは行いません。
+func executeMultiHit(c *attackPatternContext, pattern data.AttackPatternConfig, targetEntry *donburi.Entry, targetPartSlot core.PartSlotKey) []component.HitRecord {
+	partsMap := component.PartsComponent.Get(targetEntry).Map
+	pendingDamage := make(map[*core.PartInstanceData]int)
+	isPartDown := func(partInst *core.PartInstanceData) bool {
+		return partInst == nil || partInst.IsBroken || pendingDamage[partInst] >= partInst.CurrentArmor
+	}
+
+	hits := make([]component.HitRecord, 0, pattern.Hits)
+	slot := targetPartSlot
+	for i := 0; i < pattern.Hits; i++ {
+		if isPartDown(partsMap[core.PartSlotHead]) {
+			break
+		}
+		if isPartDown(partsMap[slot]) {
+			slot = selectLivePartSlot(c, targetEntry, isPartDown)
+			if slot == "" {
+				break
+			}
+		}
+
+		hit := c.resolveHit(targetEntry, slot, pattern.DamageScale)
+		if hit.ActionDidHit && hit.TargetPartInstance != nil {
+			pendingDamage[hit.TargetPartInstance] += hit.DamageToApply
+		}
+		if hit.PiercePartInstance != nil {
+			pendingDamage[hit.PiercePartInstance] += hit.PierceDamage
+		}
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// executeSpread は1回の命中判定で、合計ダメージをターゲットの複数のパーツに分散させます。
+// 狙ったパーツを先頭に、残りは壊れていないパーツからランダムに選びます。防御された場合は分散しません。
+func executeSpread(c *attackPatternContext, pattern data.AttackPatternConfig, targetEntry *donburi.Entry, targetPartSlot core.PartSlotKey) []component.HitRecord {
+	primaryHit := c.resolveHit(targetEntry, targetPartSlot, pattern.DamageScale)
+	if !primaryHit.ActionDidHit || primaryHit.ActionIsDefended || pattern.Hits <= 1 {
+		return []component.HitRecord{primaryHit}
+	}
+
+	partsMap := component.PartsComponent.Get(targetEntry).Map
+	otherSlots := make([]core.PartSlotKey, 0)
+	for _, slot := range []core.PartSlotKey{core.PartSlotHead, core.PartSlotRightArm, core.PartSlotLeftArm, core.PartSlotLegs} {
+		if partInst := partsMap[slot]; slot != primaryHit.ActualHitPartSlot && partInst != nil && !partInst.IsBroken {
+			otherSlots = append(otherSlots, slot)
+		}
+	}
+	c.rand.Shuffle(len(otherSlots), func(i, j int) { otherSlots[i], otherSlots[j] = otherSlots[j], otherSlots[i] })
+	if len(otherSlots) > pattern.Hits-1 {
+		otherSlots = otherSlots[:pattern.Hits-1]
+	}
+
+	// 合計ダメージを等分し、端数は狙ったパーツに加えます。
+	total := primaryHit.DamageToApply
+	share := total / (len(otherSlots) + 1)
+	if share < 1 {
+		return []component.HitRecord{primaryHit}
+	}
+	primaryShare := total - share*len(otherSlots)
+	primaryHit.OriginalDamage, primaryHit.DamageDealt, primaryHit.DamageToApply = primaryShare, primaryShare, primaryShare
+
+	hits := []component.HitRecord{primaryHit}
+	for _, slot := range otherSlots {
+		hit := primaryHit
+		hit.TargetPartSlot = slot
+		hit.ActualHitPartSlot = slot
+		hit.TargetPartInstance = partsMap[slot]
+		hit.OriginalDamage, hit.DamageDealt, hit.DamageToApply = share, share, share
+		hit.IsDefenseBlocked = false
+		finalizeHitRecord(&hit, c.partInfoProvider)
+		hits = append(hits, hit)
+	}
+	return hits
+}
+
+// executeArea はターゲットを攻撃し、さらにターゲットから Radius 以内にいる敵を近い順に巻き込みます。
+// 巻き込んだ敵のパーツは行動者の性格に従って選び、命中・防御の判定はそれぞれ行います。
+func executeArea(c *attackPatternContext, pattern data.AttackPatternConfig, targetEntry *donburi.Entry, targetPartSlot core.PartSlotKey) []component.HitRecord {
+	hits := []component.HitRecord{c.resolveHit(targetEntry, targetPartSlot, 1.0)}
+
+	nearbyEnemies := make([]*donburi.Entry, 0)
+	for _, enemy := range c.targetSelector.GetTargetableEnemies(c.actingEntry) {
+		if enemy != targetEntry && CalculateDistance(targetEntry, enemy) <= pattern.Radius {
+			nearbyEnemies = append(nearbyEnemies, enemy)
+		}
+	}
+	sort.SliceStable(nearbyEnemies, func(i, j int) bool {
+		return CalculateDistance(targetEntry, nearbyEnemies[i]) < CalculateDistance(targetEntry, nearbyEnemies[j])
+	})
+
+	for _, enemy := range nearbyEnemies {
+		if len(hits) >= pattern.Hits {
+			break
+		}
+		partInst := c.targetSelector.SelectPartToDamage(enemy, c.actingEntry, c.rand)
+		if partInst == nil {
+			continue
+		}
+		slot := c.partInfoProvider.FindPartSlot(enemy, partInst)
+		if slot == "" {
+			continue
+		}
+		hits = append(hits, c.resolveHit(enemy, slot, pattern.DamageScale))
+	}
+	return hits
+}
+
+// selectLivePartSlot は、壊れていない（壊れる予定もない）パーツを行動者の性格に従って選び、そのスロットを返します。
+// 性格で選んだパーツが壊れる予定の場合は、残りのパーツからランダムに選びます。
+func selectLivePartSlot(c *attackPatternContext, targetEntry *donburi.Entry, isPartDown func(*core.PartInstanceData) bool) core.PartSlotKey {
+	if partInst := c.targetSelector.SelectPartToDamage(targetEntry, c.actingEntry, c.rand); partInst != nil && !isPartDown(partInst) {
+		return c.partInfoProvider.FindPartSlot(targetEntry, partInst)
+	}
+
+	partsMap := component.PartsComponent.Get(targetEntry).Map
+	liveSlots := make([]core.PartSlotKey, 0)
+	for _, slot := range []core.PartSlotKey{core.PartSlotHead, core.PartSlotRightArm, core.PartSlotLeftArm, core.PartSlotLegs} {
+		if !isPartDown(partsMap[slot]) {
+			liveSlots = append(liveSlots, slot)
+		}
+	}
+	if len(liveSlots) == 0 {
+		return ""
+	}
+	return liveSlots[c.rand.Intn(len(liveSlots))]
+}
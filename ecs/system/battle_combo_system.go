@@ -0,0 +1,147 @@
+package system
+
+import (
+	"log"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+)
+
+// comboBonus は、コンボが成立する見込みのある攻撃に適用する効果です。
+// 効果はコンボの相手が命中させた機体へのヒットにだけ適用されます。
+type comboBonus struct {
+	target           *donburi.Entry
+	partnerHit       component.ComboHit
+	partnerName      string
+	damageMultiplier float64
+	guaranteedHit    bool
+	messageID        string
+}
+
+// comboRuleFor は、先の行動の特性と後の行動の特性の組み合わせに対応するコンボの設定を返します。
+func comboRuleFor(config *data.Config, firstTrait, secondTrait core.Trait) *data.ComboRuleConfig {
+	for i := range config.Combos.Rules {
+		rule := &config.Combos.Rules[i]
+		if rule.FirstTrait == firstTrait && rule.SecondTrait == secondTrait {
+			return rule
+		}
+	}
+	return nil
+}
+
+// FindComboBonus は、味方が WindowTicks 以内に同じ機体・同じパーツへ命中させていて、
+// 特性の組み合わせがコンボの設定にある場合、後から攻撃する行動に適用する効果を返します。
+// 該当する命中が複数ある場合は最も新しいものを相手とします。コンボにならない場合は nil を返します。
+func FindComboBonus(world donburi.World, config *data.Config, actingEntry *donburi.Entry, trait core.Trait, targetEntry *donburi.Entry, targetPartSlot core.PartSlotKey) *comboBonus {
+	tracker := entity.GetComboTracker(world)
+	if tracker == nil || config.Combos.WindowTicks <= 0 {
+		return nil
+	}
+	pruneComboHits(world, config, tracker)
+
+	team := component.SettingsComponent.Get(actingEntry).Team
+	for i := len(tracker.RecentHits) - 1; i >= 0; i-- {
+		hit := tracker.RecentHits[i]
+		if hit.Team != team || hit.Attacker == actingEntry || hit.Target != targetEntry || hit.TargetPartSlot != targetPartSlot {
+			continue
+		}
+		rule := comboRuleFor(config, hit.Trait, trait)
+		if rule == nil {
+			continue
+		}
+
+		bonus := &comboBonus{
+			target:           targetEntry,
+			partnerHit:       hit,
+			partnerName:      component.SettingsComponent.Get(hit.Attacker).Name,
+			damageMultiplier: rule.DamageMultiplier,
+			guaranteedHit:    rule.GuaranteedHit,
+			messageID:        rule.MessageID,
+		}
+		if bonus.damageMultiplier <= 0 {
+			bonus.damageMultiplier = 1.0
+		}
+		if bonus.messageID == "" {
+			bonus.messageID = "combo_triggered"
+		}
+		return bonus
+	}
+	return nil
+}
+
+// RecordComboHits は、行動の結果のうち命中したヒットをコンボの判定用に記録します。
+func RecordComboHits(world donburi.World, config *data.Config, result *component.ActionResult) {
+	tracker := entity.GetComboTracker(world)
+	if tracker == nil || config.Combos.WindowTicks <= 0 || result.ActingEntry == nil || len(result.Hits) == 0 {
+		return
+	}
+
+	team := component.SettingsComponent.Get(result.ActingEntry).Team
+	tick := entity.GetVictoryStateComponent(world).ElapsedTicks
+	recorded := make(map[*donburi.Entry]map[core.PartSlotKey]bool)
+	for _, hit := range result.Hits {
+		if !hit.ActionDidHit || hit.TargetEntry == nil || recorded[hit.TargetEntry][hit.TargetPartSlot] {
+			continue
+		}
+		if recorded[hit.TargetEntry] == nil {
+			recorded[hit.TargetEntry] = make(map[core.PartSlotKey]bool)
+		}
+		recorded[hit.TargetEntry][hit.TargetPartSlot] = true
+		tracker.RecentHits = append(tracker.RecentHits, component.ComboHit{
+			Team:           team,
+			Attacker:       result.ActingEntry,
+			Target:         hit.TargetEntry,
+			TargetPartSlot: hit.TargetPartSlot,
+			Trait:          result.ActionTrait,
+			Tick:           tick,
+		})
+	}
+}
+
+// pruneComboHits は、WindowTicks を過ぎた命中と、機能停止した機体に関わる命中の記録を取り除きます。
+func pruneComboHits(world donburi.World, config *data.Config, tracker *component.ComboTracker) {
+	tick := entity.GetVictoryStateComponent(world).ElapsedTicks
+	remaining := tracker.RecentHits[:0]
+	for _, hit := range tracker.RecentHits {
+		if tick-hit.Tick > config.Combos.WindowTicks || !isComboEntryActive(hit.Attacker) || !isComboEntryActive(hit.Target) {
+			continue
+		}
+		remaining = append(remaining, hit)
+	}
+	tracker.RecentHits = remaining
+}
+
+// isComboEntryActive は、機体がワールドに存在し、機能停止していないかを返します。
+func isComboEntryActive(entry *donburi.Entry) bool {
+	return entry != nil && entry.Valid() && component.StateComponent.Get(entry).CurrentState != core.StateBroken
+}
+
+// applyComboResult は、コンボの相手が命中させた機体に後の攻撃が命中した場合、結果にコンボの情報を書き込みます。
+// コンボが成立した場合は相手の命中の記録を消費し、同じ命中で何度もコンボが起きないようにします。
+func applyComboResult(world donburi.World, result *component.ActionResult, bonus *comboBonus) {
+	if bonus == nil {
+		return
+	}
+	for _, hit := range result.Hits {
+		if hit.TargetEntry != bonus.target || !hit.ActionDidHit {
+			continue
+		}
+		result.IsCombo = true
+		result.ComboPartnerName = bonus.partnerName
+		result.ComboMessageID = bonus.messageID
+		log.Printf("%s と %s のコンボが発生した。", bonus.partnerName, result.AttackerName)
+
+		tracker := entity.GetComboTracker(world)
+		for i, recorded := range tracker.RecentHits {
+			if recorded == bonus.partnerHit {
+				tracker.RecentHits = append(tracker.RecentHits[:i], tracker.RecentHits[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+}
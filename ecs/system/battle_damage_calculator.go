@@ -20,15 +20,79 @@ type DamageCalculator struct {
 	gameDataManager  *data.GameDataManager
 	rand             *rand.Rand
 	logger           BattleLogger // core.BattleLogger を system.BattleLogger に変更
+	modifiers        map[core.DamageModifierType]DamageModifier
 }
 
 // NewDamageCalculator は新しい DamageCalculator のインスタンスを生成します。
 func NewDamageCalculator(world donburi.World, config *data.Config, pip PartInfoProviderInterface, gdm *data.GameDataManager, r *rand.Rand, logger BattleLogger) *DamageCalculator { // core.BattleLogger を system.BattleLogger に変更
-	return &DamageCalculator{world: world, config: config, partInfoProvider: pip, gameDataManager: gdm, rand: r, logger: logger}
+	return &DamageCalculator{
+		world:            world,
+		config:           config,
+		partInfoProvider: pip,
+		gameDataManager:  gdm,
+		rand:             r,
+		logger:           logger,
+		modifiers: map[core.DamageModifierType]DamageModifier{
+			core.DamageModifierCritIgnoreEvasion: &CritIgnoreEvasionModifier{},
+			core.DamageModifierCritIgnoreDefense: &CritIgnoreDefenseModifier{},
+			core.DamageModifierIgnoreDefense:     &IgnoreDefenseModifier{},
+			core.DamageModifierPierce:            &PierceModifier{},
+		},
+	}
 }
 
 // CalculateDamage はActionFormulaと防御の成否に基づいてダメージを計算します。
+// 貫通など、ダメージ量以外の計算結果が必要な場合は CalculateDamageContext を使います。
 func (dc *DamageCalculator) CalculateDamage(attacker, target *donburi.Entry, actingPartDef *core.PartDefinition, selectedPartKey core.PartSlotKey, isDefended bool) (int, bool) {
+	ctx := dc.CalculateDamageContext(attacker, target, actingPartDef, selectedPartKey, isDefended)
+	return ctx.Damage, ctx.IsCritical
+}
+
+// CalculateDamageContext はダメージを計算し、計算の途中経過を含めて返します。
+// クリティカル判定の後、武器タイプごとのダメージ修正（game_settings.json の DamageModifiers）を順に適用してから最終ダメージを求めます。
+func (dc *DamageCalculator) CalculateDamageContext(attacker, target *donburi.Entry, actingPartDef *core.PartDefinition, selectedPartKey core.PartSlotKey, isDefended bool) DamageContext {
+	ctx, criticalChance, formulaID := dc.prepareDamageContext(attacker, target, actingPartDef, selectedPartKey, isDefended)
+
+	// 3. クリティカル判定
+	if dc.rand.Intn(100) < int(criticalChance) {
+		ctx.IsCritical = true
+		dc.logger.LogCriticalHit(component.SettingsComponent.Get(attacker).Name, criticalChance)
+	}
+
+	// 4. ダメージ修正パイプライン
+	// クリティカル時に回避度・防御度を無効にするかどうかも、武器タイプごとの修正で決まります。
+	dc.applyDamageModifiers(&ctx)
+
+	// 5. 最終ダメージ計算
+	// 乱数(±10%)
+	randomFactor := 1.0 + (dc.rand.Float64()*0.2 - 0.1)
+	damage := dc.finalDamage(&ctx, randomFactor)
+
+	log.Printf("ダメージ計算 (%s): (%.1f - %.1f - %.1f) / %.1f + %.1f * %.2f = %d (Crit: %t, Defended: %t)",
+		formulaID, ctx.SuccessRate, ctx.Evasion, ctx.DefenseRate, dc.config.Damage.DamageAdjustmentFactor, ctx.Power, randomFactor, int(damage), ctx.IsCritical, isDefended)
+
+	ctx.Damage = int(damage)
+	return ctx
+}
+
+// PreviewDamage はダメージの期待値を返します。CalculateDamageContext と同じ計算ですが、乱数を使わずログも出力しません。
+// クリティカルは発生確率で重み付けし、ダメージの乱数幅は平均（1.0倍）で計算します。
+func (dc *DamageCalculator) PreviewDamage(attacker, target *donburi.Entry, actingPartDef *core.PartDefinition, selectedPartKey core.PartSlotKey, isDefended bool) float64 {
+	ctx, criticalChance, _ := dc.prepareDamageContext(attacker, target, actingPartDef, selectedPartKey, isDefended)
+	criticalRate := criticalChance / 100
+
+	normalCtx := ctx
+	dc.applyDamageModifiers(&normalCtx)
+	criticalCtx := ctx
+	criticalCtx.IsCritical = true
+	dc.applyDamageModifiers(&criticalCtx)
+
+	return (1-criticalRate)*dc.finalDamage(&normalCtx, 1.0) + criticalRate*dc.finalDamage(&criticalCtx, 1.0)
+}
+
+// prepareDamageContext は計算式と基本パラメータから、クリティカル判定前のダメージ計算の途中経過を作ります。
+// クリティカルの発生確率（%）と、使用した計算式のIDもあわせて返します。
+func (dc *DamageCalculator) prepareDamageContext(attacker, target *donburi.Entry, actingPartDef *core.PartDefinition, selectedPartKey core.PartSlotKey, isDefended bool) (DamageContext, float64, string) {
 	// 1. 計算式の取得
 	formula, ok := dc.gameDataManager.Formulas[actingPartDef.Trait]
 	if !ok || formula.ID == "" { // IDがゼロ値の場合は見つからなかったと判断
@@ -38,7 +102,7 @@ func (dc *DamageCalculator) CalculateDamage(attacker, target *donburi.Entry, act
 
 	// 2. 基本パラメータの取得
 	successRate := dc.partInfoProvider.GetSuccessRate(attacker, actingPartDef, selectedPartKey)
-	power := float64(actingPartDef.Power)
+	power := float64(actingPartDef.Power) + dc.partInfoProvider.GetSetBonusValue(attacker, core.Power)
 	evasion := dc.partInfoProvider.GetEvasionRate(target)
 	defenseRate := 0.0
 	if isDefended {
@@ -50,33 +114,52 @@ func (dc *DamageCalculator) CalculateDamage(attacker, target *donburi.Entry, act
 		power += dc.partInfoProvider.GetPartParameterValue(attacker, selectedPartKey, bonus.SourceParam) * bonus.Multiplier
 	}
 
-	// 3. クリティカル判定
-	isCritical := false
+	// ステージによる武器種ごとの威力補正
+	power *= dc.partInfoProvider.GetStagePowerMultiplier(actingPartDef)
+
+	// チームバフ・デバフによる威力補正
+	power *= dc.partInfoProvider.GetTeamBuffMultiplier(attacker, core.BuffTypePower)
+
+	// クリティカルの発生確率
 	criticalChance := dc.config.Damage.Critical.BaseChance + (successRate * dc.config.Damage.Critical.SuccessRateFactor) + formula.CriticalRateBonus
+	if setBonus := dc.partInfoProvider.GetSetBonus(attacker); setBonus != nil {
+		criticalChance += setBonus.CriticalRateBonus
+	}
 	criticalChance = math.Max(criticalChance, dc.config.Damage.Critical.MinChance)
 	criticalChance = math.Min(criticalChance, dc.config.Damage.Critical.MaxChance)
 
-	if dc.rand.Intn(100) < int(criticalChance) {
-		isCritical = true
-		dc.logger.LogCriticalHit(component.SettingsComponent.Get(attacker).Name, criticalChance)
-		// クリティカル時は回避度を0にする
-		evasion = 0
-		// TODO: クリティカルヒット時に防御度も無効化（0に）するかどうかは、将来の検討事項。
-		// 現在は防御度が有効なまま。
+	ctx := DamageContext{
+		Attacker:      attacker,
+		Target:        target,
+		ActingPartDef: actingPartDef,
+		IsDefended:    isDefended,
+		SuccessRate:   successRate,
+		Power:         power,
+		Evasion:       evasion,
+		DefenseRate:   defenseRate,
+	}
+	return ctx, criticalChance, formula.ID
+}
+
+// applyDamageModifiers は武器タイプごとのダメージ修正を設定された順に適用します。
+func (dc *DamageCalculator) applyDamageModifiers(ctx *DamageContext) {
+	for _, params := range damageModifiersFor(dc.config, ctx.ActingPartDef.WeaponType) {
+		modifier, ok := dc.modifiers[params.Type]
+		if !ok {
+			log.Printf("警告: 未対応のダメージ修正です: %s", params.Type)
+			continue
+		}
+		modifier.Apply(ctx, params)
 	}
+}
 
-	// 4. 最終ダメージ計算
-	damage := (successRate - evasion - defenseRate) / dc.config.Damage.DamageAdjustmentFactor + power
-	// 乱数(±10%)
-	randomFactor := 1.0 + (dc.rand.Float64()*0.2 - 0.1)
+// finalDamage は修正後のパラメータと乱数の倍率から最終ダメージを求めます。最低でも1ダメージになります。
+func (dc *DamageCalculator) finalDamage(ctx *DamageContext, randomFactor float64) float64 {
+	damage := (ctx.SuccessRate - ctx.Evasion - ctx.DefenseRate) / dc.config.Damage.DamageAdjustmentFactor + ctx.Power
 	damage *= randomFactor
 
 	if damage < 1 {
 		damage = 1
 	}
-
-	log.Printf("ダメージ計算 (%s): (%.1f - %.1f - %.1f) / %.1f + %.1f * %.2f = %d (Crit: %t, Defended: %t)",
-		formula.ID, successRate, evasion, defenseRate, dc.config.Damage.DamageAdjustmentFactor, power, randomFactor, int(damage), isCritical, isDefended)
-
-	return int(damage), isCritical
-}
\ No newline at end of file
+	return damage
+}
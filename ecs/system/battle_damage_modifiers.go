@@ -0,0 +1,79 @@
+package system
+
+import (
+	"math"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+
+	"github.com/yohamta/donburi"
+)
+
+// DamageContext はダメージ計算の途中経過です。
+// クリティカル判定の後、武器タイプごとのダメージ修正が順にこれを書き換え、最後に最終ダメージを計算します。
+type DamageContext struct {
+	Attacker      *donburi.Entry
+	Target        *donburi.Entry
+	ActingPartDef *core.PartDefinition
+	IsDefended    bool
+	IsCritical    bool
+
+	SuccessRate float64
+	Power       float64
+	Evasion     float64
+	DefenseRate float64
+
+	// PierceRatio は、防御パーツの装甲を超えたダメージのうち、狙ったパーツに通す割合です。0 の場合は貫通しません。
+	PierceRatio float64
+
+	Damage int // 最終ダメージ
+}
+
+// --- DamageModifiers ---
+
+// CritIgnoreEvasionModifier はクリティカル時に回避度を無効にします。
+type CritIgnoreEvasionModifier struct{}
+
+func (m *CritIgnoreEvasionModifier) Apply(ctx *DamageContext, params data.DamageModifierConfig) {
+	if ctx.IsCritical {
+		ctx.Evasion = 0
+	}
+}
+
+// CritIgnoreDefenseModifier はクリティカル時に防御度を無効にします。
+type CritIgnoreDefenseModifier struct{}
+
+func (m *CritIgnoreDefenseModifier) Apply(ctx *DamageContext, params data.DamageModifierConfig) {
+	if ctx.IsCritical {
+		ctx.DefenseRate = 0
+	}
+}
+
+// IgnoreDefenseModifier は防御度のうち Ratio の割合を無視します（ハンマーなど）。
+type IgnoreDefenseModifier struct{}
+
+func (m *IgnoreDefenseModifier) Apply(ctx *DamageContext, params data.DamageModifierConfig) {
+	ctx.DefenseRate *= 1.0 - math.Max(0, math.Min(1, params.Ratio))
+}
+
+// PierceModifier は、防御された場合に防御パーツの装甲を超えたダメージを狙ったパーツに通します（レーザーなど）。
+// Ratio が未設定の場合は、超えた分をすべて通します。
+type PierceModifier struct{}
+
+func (m *PierceModifier) Apply(ctx *DamageContext, params data.DamageModifierConfig) {
+	if !ctx.IsDefended {
+		return
+	}
+	ctx.PierceRatio = params.Ratio
+	if ctx.PierceRatio <= 0 {
+		ctx.PierceRatio = 1.0
+	}
+}
+
+// damageModifiersFor は武器タイプに対応するダメージ修正の設定を、適用する順に返します。
+func damageModifiersFor(config *data.Config, weaponType core.WeaponType) []data.DamageModifierConfig {
+	if modifiers, ok := config.DamageModifiers.WeaponTypes[weaponType]; ok {
+		return modifiers
+	}
+	return config.DamageModifiers.Default
+}
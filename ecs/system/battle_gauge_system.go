@@ -2,6 +2,8 @@ package system
 
 import (
 	"log"
+	"math/rand"
+	"sort"
 
 	"medarot-ebiten/core"
 	"medarot-ebiten/ecs/component"
@@ -13,7 +15,14 @@ import (
 )
 
 // UpdateGaugeSystem はチャージとクールダウンのゲージ進行を更新します。
-func UpdateGaugeSystem(world donburi.World) {
+// 勝利条件（時間制限など）の判定に用いる戦闘の経過ティックもここで進めます。
+// 同じティックにチャージを終えた機体は、行動順の判定材料（準備完了時刻とコイントス）を付けて実行キューに追加します。
+func UpdateGaugeSystem(world donburi.World, rand *rand.Rand) {
+	victoryState := entity.GetVictoryStateComponent(world)
+	victoryState.ElapsedTicks++
+
+	var readyEntries []*donburi.Entry
+
 	query.NewQuery(filter.Contains(component.StateComponent)).Each(world, func(entry *donburi.Entry) {
 		state := component.StateComponent.Get(entry)
 
@@ -34,12 +43,28 @@ func UpdateGaugeSystem(world donburi.World) {
 			switch state.CurrentState {
 			case core.StateCharging:
 				state.CurrentState = core.StateReady
-				actionQueueComp := entity.GetActionQueueComponent(world)
-				actionQueueComp.Queue = append(actionQueueComp.Queue, entry)
-				log.Printf("%s のチャージが完了。実行キューに追加。", component.SettingsComponent.Get(entry).Name)
+				// 1ティックの進行のうち、ゲージを超過した分だけ前の時刻に完了したものとみなします。
+				gauge.ReadyAt = float64(victoryState.ElapsedTicks) - (gauge.ProgressCounter - gauge.TotalDuration)
+				readyEntries = append(readyEntries, entry)
 			case core.StateCooldown:
 				state.CurrentState = core.StateIdle
 			}
 		}
 	})
+
+	if len(readyEntries) == 0 {
+		return
+	}
+
+	// コイントスの乱数をクエリの走査順（アーキタイプの格納順）に依存させないため、
+	// チームと表示順で並べてから引きます。
+	sort.Slice(readyEntries, func(i, j int) bool {
+		return compareSettingsOrder(readyEntries[i], readyEntries[j])
+	})
+	actionQueueComp := entity.GetActionQueueComponent(world)
+	for _, entry := range readyEntries {
+		component.GaugeComponent.Get(entry).TieBreaker = rand.Float64()
+		actionQueueComp.Queue = append(actionQueueComp.Queue, entry)
+		log.Printf("%s のチャージが完了。実行キューに追加。", component.SettingsComponent.Get(entry).Name)
+	}
 }
@@ -3,6 +3,7 @@ package system
 import (
 	"log"
 
+	"medarot-ebiten/core"
 	"medarot-ebiten/ecs/component"
 
 	"github.com/yohamta/donburi"
@@ -14,6 +15,10 @@ func UpdateHistorySystem(world donburi.World, result *component.ActionResult) {
 	if result == nil {
 		return
 	}
+	// 修復と支援は味方への行動なので、攻撃の履歴には記録しません。
+	if result.ActionTrait == core.TraitRepair || result.ActionTrait == core.TraitSupport {
+		return
+	}
 
 	// --- 攻撃者側の履歴更新 ---
 	// 自分が最後に攻撃をヒットさせたターゲットとパーツを記録します。
@@ -32,15 +37,24 @@ func UpdateHistorySystem(world donburi.World, result *component.ActionResult) {
 	}
 
 	// --- 防御者側の履歴更新 ---
-	// 自分を最後に攻撃してきた相手を記録します。
-	if result.TargetEntry != nil && result.TargetEntry.Valid() && result.TargetEntry.HasComponent(component.AIComponent) {
+	// 自分を最後に攻撃してきた相手を記録します。範囲攻撃で巻き込まれた機体も対象です。
+	attackedEntries := []*donburi.Entry{result.TargetEntry}
+	for _, hit := range result.Hits {
+		if hit.TargetEntry != result.TargetEntry {
+			attackedEntries = append(attackedEntries, hit.TargetEntry)
+		}
+	}
+	for _, attackedEntry := range attackedEntries {
+		if attackedEntry == nil || !attackedEntry.Valid() || !attackedEntry.HasComponent(component.AIComponent) {
+			continue
+		}
 		// 命中したかどうかに関わらず、攻撃してきた相手として記録します。
 		// これにより、回避した場合でもカウンターの対象となります。
-		ai := component.AIComponent.Get(result.TargetEntry)
+		ai := component.AIComponent.Get(attackedEntry)
 		ai.TargetHistory.LastAttacker = result.ActingEntry
 		log.Printf(
 			"履歴更新 (Counter): %s が %s から攻撃されたことを記録しました。",
-			component.SettingsComponent.Get(result.TargetEntry).Name,
+			component.SettingsComponent.Get(attackedEntry).Name,
 			component.SettingsComponent.Get(result.ActingEntry).Name,
 		)
 	}
@@ -1,6 +1,7 @@
 package system
 
 import (
+	"math"
 	"math/rand"
 
 	"medarot-ebiten/core"
@@ -26,17 +27,34 @@ func NewHitCalculator(world donburi.World, config *data.Config, pip PartInfoProv
 
 // CalculateHit は新しいルールに基づいて命中判定を行います。
 func (hc *HitCalculator) CalculateHit(attacker, target *donburi.Entry, partDef *core.PartDefinition, selectedPartKey core.PartSlotKey) bool {
+	chance, successRate, evasion := hc.hitChance(attacker, target, partDef, selectedPartKey)
+	roll := hc.rand.Intn(100)
+	hc.logger.LogHitCheck(component.SettingsComponent.Get(attacker).Name, component.SettingsComponent.Get(target).Name, chance, successRate, evasion, roll)
+	return float64(roll) < chance
+}
+
+// PreviewHitChance は命中確率（%）を返します。CalculateHit と同じ計算ですが、乱数を使わずログも出力しません。
+func (hc *HitCalculator) PreviewHitChance(attacker, target *donburi.Entry, partDef *core.PartDefinition, selectedPartKey core.PartSlotKey) float64 {
+	chance, _, _ := hc.hitChance(attacker, target, partDef, selectedPartKey)
+	return chance
+}
+
+// hitChance は命中確率と、その計算に使った成功度・回避度を返します。
+func (hc *HitCalculator) hitChance(attacker, target *donburi.Entry, partDef *core.PartDefinition, selectedPartKey core.PartSlotKey) (chance, successRate, evasion float64) {
 	// 攻撃側の成功度
-	successRate := hc.partInfoProvider.GetSuccessRate(attacker, partDef, selectedPartKey)
+	successRate = hc.partInfoProvider.GetSuccessRate(attacker, partDef, selectedPartKey)
 
 	// チームバフによる成功度の上昇
-	successRate *= hc.partInfoProvider.GetTeamAccuracyBuffMultiplier(attacker)
+	successRate *= hc.partInfoProvider.GetTeamBuffMultiplier(attacker, core.BuffTypeAccuracy)
 
 	// 防御側の回避度
-	evasion := hc.partInfoProvider.GetEvasionRate(target)
+	evasion = hc.partInfoProvider.GetEvasionRate(target)
 
-	// 命中確率 = 基準値 + (成功度 - 回避度)
-	chance := hc.config.Hit.BaseChance + (successRate - evasion)
+	// 命中確率 = 基準値 + (成功度 - 回避度) - 距離による減衰
+	chance = hc.config.Hit.BaseChance + (successRate - evasion)
+	if partDef.Category == core.CategoryRanged {
+		chance -= hc.CalculateRangePenalty(attacker, target)
+	}
 
 	// 確率の上下限を適用
 	if chance < hc.config.Hit.MinChance {
@@ -45,23 +63,53 @@ func (hc *HitCalculator) CalculateHit(attacker, target *donburi.Entry, partDef *
 	if chance > hc.config.Hit.MaxChance {
 		chance = hc.config.Hit.MaxChance
 	}
+	return chance, successRate, evasion
+}
 
-	roll := hc.rand.Intn(100)
-	hc.logger.LogHitCheck(component.SettingsComponent.Get(attacker).Name, component.SettingsComponent.Get(target).Name, chance, successRate, evasion, roll)
-	return float64(roll) < chance
+// CalculateRangePenalty は射撃攻撃の距離による命中率の低下量を返します。
+// 最適距離までは低下せず、それを超えた距離に比例して上限まで低下します。
+func (hc *HitCalculator) CalculateRangePenalty(attacker, target *donburi.Entry) float64 {
+	excess := CalculateDistance(attacker, target) - hc.config.Spatial.RangedOptimalDistance
+	if excess <= 0 {
+		return 0
+	}
+	return math.Min(excess*hc.config.Spatial.RangedAccuracyFalloff, hc.config.Spatial.RangedMaxAccuracyPenalty)
+}
+
+// IsWithinMeleeRange は格闘攻撃がターゲットに届く距離にいるかを判定します。
+func (hc *HitCalculator) IsWithinMeleeRange(attacker, target *donburi.Entry) bool {
+	return CalculateDistance(attacker, target) <= hc.config.Spatial.MeleeRange
 }
 
 // CalculateDefense は防御の成否を判定します。
 // 【修正点】防御するパーツの定義(defendingPartDef)を引数に追加し、ログ出力で使えるようにしました。
 func (hc *HitCalculator) CalculateDefense(attacker, target *donburi.Entry, actingPartDef *core.PartDefinition, selectedPartKey core.PartSlotKey, defendingPartDef *core.PartDefinition) bool {
+	chance, defenseRate, successRate := hc.defenseChance(attacker, target, actingPartDef, selectedPartKey)
+
+	roll := hc.rand.Intn(100)
+	// 【修正点】ログ出力時に防御パーツ名を渡すように修正しました。
+	// これにより、LogDefenseCheckの6つの引数要件を満たします。
+	hc.logger.LogDefenseCheck(component.SettingsComponent.Get(target).Name, defendingPartDef.PartName, chance, defenseRate, successRate, roll)
+	return float64(roll) < chance
+}
+
+// PreviewDefenseChance は防御成功確率（%）を返します。CalculateDefense と同じ計算ですが、乱数を使わずログも出力しません。
+// 防御できるパーツがあるかどうかは考慮しません。
+func (hc *HitCalculator) PreviewDefenseChance(attacker, target *donburi.Entry, actingPartDef *core.PartDefinition, selectedPartKey core.PartSlotKey) float64 {
+	chance, _, _ := hc.defenseChance(attacker, target, actingPartDef, selectedPartKey)
+	return chance
+}
+
+// defenseChance は防御成功確率と、その計算に使った防御度・成功度を返します。
+func (hc *HitCalculator) defenseChance(attacker, target *donburi.Entry, actingPartDef *core.PartDefinition, selectedPartKey core.PartSlotKey) (chance, defenseRate, successRate float64) {
 	// 攻撃側の成功度
-	successRate := hc.partInfoProvider.GetSuccessRate(attacker, actingPartDef, selectedPartKey)
+	successRate = hc.partInfoProvider.GetSuccessRate(attacker, actingPartDef, selectedPartKey)
 
 	// 防御側の防御度
-	defenseRate := hc.partInfoProvider.GetDefenseRate(target)
+	defenseRate = hc.partInfoProvider.GetDefenseRate(target)
 
 	// 防御成功確率 = 基準値 + (防御度 - 成功度)
-	chance := hc.config.Defense.BaseChance + (defenseRate - successRate)
+	chance = hc.config.Defense.BaseChance + (defenseRate - successRate)
 
 	// 確率の上下限を適用
 	if chance < hc.config.Defense.MinChance {
@@ -70,10 +118,5 @@ func (hc *HitCalculator) CalculateDefense(attacker, target *donburi.Entry, actin
 	if chance > hc.config.Defense.MaxChance {
 		chance = hc.config.Defense.MaxChance
 	}
-
-	roll := hc.rand.Intn(100)
-	// 【修正点】ログ出力時に防御パーツ名を渡すように修正しました。
-	// これにより、LogDefenseCheckの6つの引数要件を満たします。
-	hc.logger.LogDefenseCheck(component.SettingsComponent.Get(target).Name, defendingPartDef.PartName, chance, defenseRate, successRate, roll)
-	return float64(roll) < chance
-}
\ No newline at end of file
+	return chance, defenseRate, successRate
+}
@@ -4,6 +4,7 @@ import (
 	"math/rand"
 
 	"medarot-ebiten/core"
+	"medarot-ebiten/data"
 	"medarot-ebiten/ecs/component"
 	"medarot-ebiten/ecs/entity"
 	"medarot-ebiten/event"
@@ -15,6 +16,7 @@ import (
 
 // UpdatePlayerInputSystem はアイドル状態のすべてのプレイヤー制御メダロットを見つけます。
 // このシステムは、行動が必要なプレイヤーエンティティのリストを含むイベントを発行します。
+// オートバトル中でAIコンポーネントを持つ機体は、AIが行動を選ぶため対象外です。
 func UpdatePlayerInputSystem(world donburi.World) []event.GameEvent {
 	playerActionQueue := entity.GetPlayerActionQueueComponent(world)
 	var gameEvents []event.GameEvent
@@ -22,6 +24,9 @@ func UpdatePlayerInputSystem(world donburi.World) []event.GameEvent {
 	// キューをクリアし、現在のアイドル状態のプレイヤーエンティティを再収集
 	playerActionQueue.Queue = make([]*donburi.Entry, 0)
 	query.NewQuery(filter.Contains(component.PlayerControlComponent)).Each(world, func(entry *donburi.Entry) {
+		if entry.HasComponent(component.AIComponent) {
+			return
+		}
 		if component.StateComponent.Get(entry).CurrentState == core.StateIdle {
 			playerActionQueue.Queue = append(playerActionQueue.Queue, entry)
 		}
@@ -36,25 +41,29 @@ func UpdatePlayerInputSystem(world donburi.World) []event.GameEvent {
 }
 
 // UpdateAIInputSystem はAI制御のメダロットの行動選択を処理します。
+// オートバトル中のプレイヤーの機体もAIコンポーネントを持つため、ここで行動が選ばれます。
 // BattleLogicへの依存をなくし、必要なシステムを直接引数に取ります。
 func UpdateAIInputSystem(
 	world donburi.World,
 	partInfoProvider PartInfoProviderInterface,
 	chargeSystem *ChargeInitiationSystem,
 	targetSelector *TargetSelector,
+	hitCalculator *HitCalculator,
+	damageCalculator *DamageCalculator,
+	gameConfig *data.Config,
 	// randの型を *core.Rand から正しい *rand.Rand に修正しました。
 	rand *rand.Rand,
 ) {
 	// 存在しない filter.In を削除し、Eachループ内でif文によるチェックを行うように修正しました。
 	// これがdonburiの標準的な値によるフィルタリング方法です。
 	query.NewQuery(
-		filter.Not(filter.Contains(component.PlayerControlComponent)), // プレイヤー制御ではないエンティティ
+		filter.Contains(component.AIComponent), // AIが操作するエンティティ
 	).Each(world, func(entry *donburi.Entry) {
 		// アイドル状態のエンティティのみを処理
 		if !entry.HasComponent(component.StateComponent) || component.StateComponent.Get(entry).CurrentState != core.StateIdle {
 			return
 		}
-		aiSelectAction(world, entry, partInfoProvider, chargeSystem, targetSelector, rand)
+		aiSelectAction(world, entry, partInfoProvider, chargeSystem, targetSelector, hitCalculator, damageCalculator, gameConfig, rand)
 	})
 }
 
@@ -2,6 +2,7 @@ package system
 
 import (
 	"log"
+	"math"
 	"math/rand"
 
 	"medarot-ebiten/core"
@@ -27,58 +28,157 @@ func performHitCheck(actingEntry, targetEntry *donburi.Entry, actingPartDef *cor
 	return hitCalculator.CalculateHit(actingEntry, targetEntry, actingPartDef, selectedPartKey)
 }
 
+// resolveHit は1ヒット分の命中判定、防御判定、ダメージ計算を行い、その結果を返します。
+// damageScale は計算したダメージに掛ける倍率です（連続攻撃や範囲攻撃の巻き込みなど）。
+func resolveHit(
+	actingEntry *donburi.Entry,
+	targetEntry *donburi.Entry,
+	targetPartSlot core.PartSlotKey,
+	actingPartDef *core.PartDefinition,
+	selectedPartKey core.PartSlotKey,
+	damageScale float64,
+	guaranteedHit bool,
+	damageCalculator *DamageCalculator,
+	hitCalculator *HitCalculator,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+) component.HitRecord {
+	hit := component.HitRecord{
+		TargetEntry:    targetEntry,
+		TargetPartSlot: targetPartSlot,
+		DefenderName:   component.SettingsComponent.Get(targetEntry).Name,
+	}
+
+	hit.ActionDidHit = guaranteedHit || performHitCheck(actingEntry, targetEntry, actingPartDef, selectedPartKey, hitCalculator)
+	if !hit.ActionDidHit {
+		return hit
+	}
+
+	// ダメージ計算と防御判定をヘルパー関数に集約
+	applyDamageAndDefense(&hit, actingEntry, actingPartDef, selectedPartKey, damageScale, damageCalculator, hitCalculator, targetSelector, partInfoProvider)
+
+	finalizeHitRecord(&hit, partInfoProvider)
+	return hit
+}
+
 // applyDamageAndDefense はダメージ計算と防御判定のロジックをカプセル化します。
 func applyDamageAndDefense(
-	result *component.ActionResult,
+	hit *component.HitRecord,
 	actingEntry *donburi.Entry,
 	actingPartDef *core.PartDefinition,
 	selectedPartKey core.PartSlotKey,
+	damageScale float64,
 	damageCalculator *DamageCalculator,
 	hitCalculator *HitCalculator,
 	targetSelector *TargetSelector,
 	partInfoProvider PartInfoProviderInterface,
 ) {
 	// 1. 防御に使用するパーツを選択
-	defendingPartInst := targetSelector.SelectDefensePart(result.TargetEntry)
+	defendingPartInst := targetSelector.SelectDefensePart(hit.TargetEntry)
 	var isDefended bool
 
-	// 2. 防御判定
+	// 2. 防御判定（スキャンでマークされている場合は防御できません）
+	if defendingPartInst != nil && ConsumeScanDefenseBlock(hit.TargetEntry) {
+		log.Printf("%s はスキャンされているため防御できない！", hit.DefenderName)
+		hit.IsDefenseBlocked = true
+		defendingPartInst = nil
+	}
 	if defendingPartInst != nil {
 		defendingPartDef, _ := partInfoProvider.GetGameDataManager().GetPartDefinition(defendingPartInst.DefinitionID)
-		isDefended = hitCalculator.CalculateDefense(actingEntry, result.TargetEntry, actingPartDef, selectedPartKey, defendingPartDef)
-		result.ActionIsDefended = isDefended
-		result.DefendingPartType = string(defendingPartDef.Type)
+		isDefended = hitCalculator.CalculateDefense(actingEntry, hit.TargetEntry, actingPartDef, selectedPartKey, defendingPartDef)
+		hit.ActionIsDefended = isDefended
+		hit.DefendingPartType = string(defendingPartDef.Type)
 	} else {
 		isDefended = false
-		result.ActionIsDefended = false
+		hit.ActionIsDefended = false
 	}
 
 	// 3. ダメージ計算
 	// 防御の成否を引数に渡し、計算式を切り替える
-	damage, isCritical := damageCalculator.CalculateDamage(actingEntry, result.TargetEntry, actingPartDef, selectedPartKey, isDefended)
-	result.IsCritical = isCritical
-	result.OriginalDamage = damage // 計算後のダメージをOriginalDamageとして記録（UI表示用）
-	result.DamageDealt = damage
-	result.DamageToApply = damage
+	damageCtx := damageCalculator.CalculateDamageContext(actingEntry, hit.TargetEntry, actingPartDef, selectedPartKey, isDefended)
+	damage := scaleDamage(damageCtx.Damage, damageScale)
+	hit.IsCritical = damageCtx.IsCritical
+	hit.OriginalDamage = damage // 計算後のダメージをOriginalDamageとして記録（UI表示用）
+	hit.DamageDealt = damage
+	hit.DamageToApply = damage
 
 	// 4. 実際にダメージを受けるパーツを決定
 	if isDefended {
 		// 防御成功時は防御パーツがダメージを受ける
-		result.ActualHitPartSlot = partInfoProvider.FindPartSlot(result.TargetEntry, defendingPartInst)
-		result.TargetPartInstance = defendingPartInst
+		hit.ActualHitPartSlot = partInfoProvider.FindPartSlot(hit.TargetEntry, defendingPartInst)
+		hit.TargetPartInstance = defendingPartInst
 	} else {
 		// 防御失敗時は元々狙われたパーツがダメージを受ける
-		result.ActualHitPartSlot = result.TargetPartSlot
-		result.TargetPartInstance = component.PartsComponent.Get(result.TargetEntry).Map[result.TargetPartSlot]
+		hit.ActualHitPartSlot = hit.TargetPartSlot
+		hit.TargetPartInstance = component.PartsComponent.Get(hit.TargetEntry).Map[hit.TargetPartSlot]
 	}
+
+	// 5. 貫通：防御パーツの装甲を超えたダメージを、元々狙われたパーツに通す
+	if isDefended && damageCtx.PierceRatio > 0 && hit.ActualHitPartSlot != hit.TargetPartSlot {
+		targetPartInst := component.PartsComponent.Get(hit.TargetEntry).Map[hit.TargetPartSlot]
+		excess := damage - defendingPartInst.CurrentArmor
+		if targetPartInst != nil && !targetPartInst.IsBroken && excess > 0 {
+			hit.PierceDamage = int(math.Max(1, math.Round(float64(excess)*damageCtx.PierceRatio)))
+			hit.PiercePartSlot = hit.TargetPartSlot
+			hit.PiercePartInstance = targetPartInst
+		}
+	}
+}
+
+// scaleDamage はダメージに倍率を掛けます。ダメージが発生している場合は最低1を保証します。
+func scaleDamage(damage int, scale float64) int {
+	if scale == 1.0 || damage <= 0 {
+		return damage
+	}
+	return int(math.Max(1, math.Round(float64(damage)*scale)))
 }
 
-// finalizeActionResult は ActionResult を最終化します。
-func finalizeActionResult(result *component.ActionResult, partInfoProvider PartInfoProviderInterface) {
-	actualHitPartInst := component.PartsComponent.Get(result.TargetEntry).Map[result.ActualHitPartSlot]
+// finalizeHitRecord は実際にヒットしたパーツ（と貫通したパーツ）の部位を記録します。
+func finalizeHitRecord(hit *component.HitRecord, partInfoProvider PartInfoProviderInterface) {
+	actualHitPartInst := component.PartsComponent.Get(hit.TargetEntry).Map[hit.ActualHitPartSlot]
 	actualHitPartDef, _ := partInfoProvider.GetGameDataManager().GetPartDefinition(actualHitPartInst.DefinitionID)
 
-	result.TargetPartType = string(actualHitPartDef.Type)
+	hit.TargetPartType = string(actualHitPartDef.Type)
+
+	if hit.PiercePartInstance != nil {
+		if piercePartDef, found := partInfoProvider.GetGameDataManager().GetPartDefinition(hit.PiercePartInstance.DefinitionID); found {
+			hit.PiercePartType = string(piercePartDef.Type)
+		}
+	}
+}
+
+// applyPrimaryHit は、代表となるヒットの内容を ActionResult の各フィールドに写します。
+// 代表はメインのターゲットへの最初の命中で、命中がなければ最初のヒットです。
+// ActionDidHit は、いずれかのヒットが命中していれば true になります。
+func applyPrimaryHit(result *component.ActionResult) {
+	if len(result.Hits) == 0 {
+		return
+	}
+	primary := &result.Hits[0]
+	anyHit, foundPrimary := false, false
+	for i := range result.Hits {
+		if !result.Hits[i].ActionDidHit {
+			continue
+		}
+		anyHit = true
+		if !foundPrimary && result.Hits[i].TargetEntry == result.TargetEntry {
+			primary = &result.Hits[i]
+			foundPrimary = true
+		}
+	}
+
+	result.ActionDidHit = anyHit
+	result.IsCritical = primary.IsCritical
+	result.ActionIsDefended = primary.ActionIsDefended
+	result.IsDefenseBlocked = primary.IsDefenseBlocked
+	result.DefendingPartType = primary.DefendingPartType
+	result.TargetPartType = primary.TargetPartType
+	result.ActualHitPartSlot = primary.ActualHitPartSlot
+	result.OriginalDamage = primary.OriginalDamage
+	result.DamageDealt = primary.DamageDealt
+	result.DamageToApply = primary.DamageToApply
+	result.TargetPartInstance = primary.TargetPartInstance
+	result.IsTargetPartBroken = primary.IsTargetPartBroken
 }
 
 // resolveAttackTarget は攻撃アクションのターゲットを解決します。
@@ -103,7 +203,7 @@ func resolveAttackTarget(
 		}
 		return targetEntry, targetComp.TargetPartSlot
 	case core.PolicyClosestAtExecution:
-		closestEnemy := targetSelector.FindClosestEnemy(actingEntry, partInfoProvider)
+		closestEnemy := targetSelector.FindClosestEnemy(actingEntry)
 		if closestEnemy == nil {
 			return nil, ""
 		}
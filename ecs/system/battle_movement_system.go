@@ -0,0 +1,77 @@
+package system
+
+import (
+	"math"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// UpdatePositionSystem はゲージの進行度と脚部タイプに基づいて各機体のバトルフィールド上の位置を更新します。
+// チャージ中はホームから実行ラインへ前進し、クールダウン中はホームへ戻ります。
+// 機能停止した機体はその場に留まります。
+func UpdatePositionSystem(world donburi.World, config *data.Config) {
+	query.NewQuery(filter.Contains(component.PositionComponent, component.StateComponent, component.GaugeComponent)).Each(world, func(entry *donburi.Entry) {
+		state := component.StateComponent.Get(entry).CurrentState
+		if state == core.StateBroken {
+			return
+		}
+
+		gauge := component.GaugeComponent.Get(entry)
+		progress := 0.0
+		if gauge.TotalDuration > 0 {
+			progress = math.Min(gauge.ProgressCounter/gauge.TotalDuration, 1.0)
+		}
+
+		var advance float64 // 0.0 = ホーム, 1.0 = 前進の到達点
+		switch state {
+		case core.StateCharging:
+			advance = progress
+		case core.StateReady:
+			advance = 1.0
+		case core.StateCooldown:
+			advance = 1.0 - progress
+		default:
+			advance = 0.0
+		}
+
+		position := component.PositionComponent.Get(entry)
+		movement := legMovementFor(config, position.LegType)
+		targetX := position.Home.X + (position.FrontX-position.Home.X)*movement.AdvanceRatio
+
+		// 前進の途中で上下に膨らむ軌道を描きます。向きは表示順で交互に変え、隣同士が重ならないようにします。
+		sway := movement.SwayRatio * config.Spatial.FieldHeight * math.Sin(math.Pi*advance)
+		if component.SettingsComponent.Get(entry).DrawIndex%2 == 1 {
+			sway = -sway
+		}
+
+		position.Current = core.Vector2{
+			X: position.Home.X + (targetX-position.Home.X)*advance,
+			Y: math.Max(0, math.Min(config.Spatial.FieldHeight, position.Home.Y+sway)),
+		}
+	})
+}
+
+// legMovementFor は脚部タイプの移動設定を返します。設定がない場合は実行ラインまで直進します。
+func legMovementFor(config *data.Config, legType core.LegType) data.LegMovementConfig {
+	if movement, ok := config.Spatial.LegMovement[legType]; ok {
+		return movement
+	}
+	return data.LegMovementConfig{AdvanceRatio: 1.0}
+}
+
+// CalculateDistance は2機のバトルフィールド上の距離を返します。
+// どちらかが位置を持たない場合は 0 を返します。
+func CalculateDistance(a, b *donburi.Entry) float64 {
+	if !a.HasComponent(component.PositionComponent) || !b.HasComponent(component.PositionComponent) {
+		return 0
+	}
+	posA := component.PositionComponent.Get(a).Current
+	posB := component.PositionComponent.Get(b).Current
+	return math.Hypot(posA.X-posB.X, posA.Y-posB.Y)
+}
@@ -2,10 +2,12 @@ package system
 
 import (
 	"log"
+	"math"
 
 	"medarot-ebiten/core"
 	"medarot-ebiten/data"
 	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
 
 	"github.com/yohamta/donburi"
 	"github.com/yohamta/donburi/filter"
@@ -45,24 +47,106 @@ func (pip *PartInfoProvider) GetPartParameterValue(entry *donburi.Entry, partSlo
 		return 0
 	}
 
+	// セットボーナスの機動・推進・安定・防御は脚部のパラメータに加算します。
+	legsBonus := 0.0
+	if partSlot == core.PartSlotLegs {
+		legsBonus = pip.GetSetBonusValue(entry, param)
+	}
+
 	switch param {
 	case core.Power:
 		return float64(partDef.Power)
 	case core.Accuracy:
 		return float64(partDef.Accuracy)
 	case core.Mobility:
-		return float64(partDef.Mobility)
+		return (float64(partDef.Mobility) + legsBonus) * pip.stageMobilityMultiplier(partDef)
 	case core.Propulsion:
-		return float64(partDef.Propulsion)
+		return (float64(partDef.Propulsion) + legsBonus) * pip.stagePropulsionMultiplier(partDef)
 	case core.Stability:
-		return float64(partDef.Stability)
+		return float64(partDef.Stability) + legsBonus
 	case core.Defense:
-		return float64(partDef.Defense)
+		return float64(partDef.Defense) + legsBonus
 	default:
 		return 0
 	}
 }
 
+// GetSetBonus はエンティティが揃えているパーツセットのボーナスを返します。揃えていない場合は nil を返します。
+func (pip *PartInfoProvider) GetSetBonus(entry *donburi.Entry) *core.SetBonusDefinition {
+	if entry == nil || !entry.HasComponent(component.SetBonusComponent) {
+		return nil
+	}
+	return component.SetBonusComponent.Get(entry)
+}
+
+// GetSetBonusValue はセットボーナスによる指定パラメータへの加算値を返します。
+func (pip *PartInfoProvider) GetSetBonusValue(entry *donburi.Entry, param core.PartParameter) float64 {
+	if setBonus := pip.GetSetBonus(entry); setBonus != nil {
+		return setBonus.StatBonuses[param]
+	}
+	return 0
+}
+
+// stageMobilityMultiplier は現在のステージが脚部パーツの機動に与える倍率を返します。
+func (pip *PartInfoProvider) stageMobilityMultiplier(partDef *core.PartDefinition) float64 {
+	stage := entity.GetStage(pip.world)
+	if stage == nil {
+		return 1.0
+	}
+	multiplier := stage.MobilityMultiplier
+	if legModifier, ok := stage.LegTypeModifiers[partDef.LegType]; ok {
+		multiplier *= legModifier.MobilityMultiplier
+	}
+	return multiplier
+}
+
+// stagePropulsionMultiplier は現在のステージが脚部パーツの推進に与える倍率を返します。
+func (pip *PartInfoProvider) stagePropulsionMultiplier(partDef *core.PartDefinition) float64 {
+	stage := entity.GetStage(pip.world)
+	if stage == nil {
+		return 1.0
+	}
+	multiplier := stage.PropulsionMultiplier
+	if legModifier, ok := stage.LegTypeModifiers[partDef.LegType]; ok {
+		multiplier *= legModifier.PropulsionMultiplier
+	}
+	return multiplier
+}
+
+// stageWeaponModifier は現在のステージでの武器種ごとの補正を返します。補正がない場合は等倍です。
+func (pip *PartInfoProvider) stageWeaponModifier(weaponType core.WeaponType) core.StageWeaponModifier {
+	if stage := entity.GetStage(pip.world); stage != nil {
+		if modifier, ok := stage.WeaponModifiers[weaponType]; ok {
+			return modifier
+		}
+	}
+	return core.StageWeaponModifier{PowerMultiplier: 1.0, AccuracyMultiplier: 1.0}
+}
+
+// GetStagePowerMultiplier は現在のステージが攻撃パーツの威力に与える倍率を返します。
+func (pip *PartInfoProvider) GetStagePowerMultiplier(actingPartDef *core.PartDefinition) float64 {
+	return pip.stageWeaponModifier(actingPartDef.WeaponType).PowerMultiplier
+}
+
+// GetAttackPattern は攻撃パーツの攻撃パターンを返します。
+// パーツIDの設定、武器タイプの設定の順に探し、どちらにもない場合は単発攻撃を返します。
+func (pip *PartInfoProvider) GetAttackPattern(actingPartDef *core.PartDefinition) data.AttackPatternConfig {
+	pattern, ok := pip.config.AttackPatterns.Parts[actingPartDef.ID]
+	if !ok {
+		pattern, ok = pip.config.AttackPatterns.WeaponTypes[actingPartDef.WeaponType]
+	}
+	if !ok || pattern.Pattern == "" {
+		return data.AttackPatternConfig{Pattern: core.AttackPatternSingle, Hits: 1, DamageScale: 1.0}
+	}
+	if pattern.Hits < 1 {
+		pattern.Hits = 1
+	}
+	if pattern.DamageScale <= 0 {
+		pattern.DamageScale = 1.0
+	}
+	return pattern
+}
+
 // FindPartSlot は指定されたパーツインスタンスがどのスロットにあるかを返します。
 func (pip *PartInfoProvider) FindPartSlot(entry *donburi.Entry, partToFindInstance *core.PartInstanceData) core.PartSlotKey {
 	partsComp := component.PartsComponent.Get(entry)
@@ -136,7 +220,7 @@ func (pip *PartInfoProvider) GetLegsPartDefinition(entry *donburi.Entry) (*core.
 
 // GetSuccessRate はエンティティの成功度を計算します。
 func (pip *PartInfoProvider) GetSuccessRate(entry *donburi.Entry, actingPartDef *core.PartDefinition, selectedPartKey core.PartSlotKey) float64 {
-	successRate := float64(actingPartDef.Accuracy)
+	successRate := float64(actingPartDef.Accuracy) + pip.GetSetBonusValue(entry, core.Accuracy)
 
 	// 特性によるボーナスを加算
 	formula, ok := pip.gameDataManager.Formulas[actingPartDef.Trait]
@@ -146,6 +230,9 @@ func (pip *PartInfoProvider) GetSuccessRate(entry *donburi.Entry, actingPartDef
 			successRate += pip.GetPartParameterValue(entry, selectedPartKey, bonus.SourceParam) * bonus.Multiplier
 		}
 	}
+
+	// ステージによる武器種ごとの命中補正
+	successRate *= pip.stageWeaponModifier(actingPartDef.WeaponType).AccuracyMultiplier
 	return successRate
 }
 
@@ -153,6 +240,24 @@ func (pip *PartInfoProvider) GetSuccessRate(entry *donburi.Entry, actingPartDef
 func (pip *PartInfoProvider) GetEvasionRate(entry *donburi.Entry) float64 {
 	evasion := pip.GetPartParameterValue(entry, core.PartSlotLegs, core.Mobility)
 
+	// 過積載による回避ペナルティ
+	if overload := pip.GetOverloadRatio(entry); overload > 0 {
+		evasion *= math.Max(0, 1.0-overload*pip.config.Load.EvasionPenaltyFactor)
+	}
+
+	// ステージによる回避補正（遮蔽物の多い地形では回避しやすくなります）
+	if stage := entity.GetStage(pip.world); stage != nil {
+		evasion *= stage.EvasionMultiplier
+	}
+
+	// チームバフ・デバフによる補正
+	evasion *= pip.GetTeamBuffMultiplier(entry, core.BuffTypeEvasion)
+
+	// スキャンでマークされている場合の補正
+	if entry.HasComponent(component.ScanMarkComponent) {
+		evasion *= pip.config.Scan.EvasionMultiplier
+	}
+
 	// ActiveEffectsComponentから回避デバフの影響を適用
 	if entry.HasComponent(component.ActiveEffectsComponent) {
 		activeEffects := component.ActiveEffectsComponent.Get(entry)
@@ -169,6 +274,9 @@ func (pip *PartInfoProvider) GetEvasionRate(entry *donburi.Entry) float64 {
 func (pip *PartInfoProvider) GetDefenseRate(entry *donburi.Entry) float64 {
 	defense := pip.GetPartParameterValue(entry, core.PartSlotLegs, core.Defense)
 
+	// チームバフ・デバフによる補正
+	defense *= pip.GetTeamBuffMultiplier(entry, core.BuffTypeDefense)
+
 	// ActiveEffectsComponentから防御デバフの影響を適用
 	if entry.HasComponent(component.ActiveEffectsComponent) {
 		activeEffects := component.ActiveEffectsComponent.Get(entry)
@@ -181,32 +289,21 @@ func (pip *PartInfoProvider) GetDefenseRate(entry *donburi.Entry) float64 {
 	return defense
 }
 
-// GetTeamAccuracyBuffMultiplier は、指定されたエンティティが所属するチームの
-// 命中率バフ（スキャンなど）の中から最も効果の高いものの乗数を返します。
-func (pip *PartInfoProvider) GetTeamAccuracyBuffMultiplier(entry *donburi.Entry) float64 {
+// GetTeamBuffMultiplier は、指定されたエンティティが所属するチームにかかっている
+// 指定した種類のバフ・デバフを、重ね方の規則に従ってまとめた乗数を返します。
+func (pip *PartInfoProvider) GetTeamBuffMultiplier(entry *donburi.Entry, buffType core.BuffType) float64 {
 	teamBuffsEntry, ok := query.NewQuery(filter.Contains(component.TeamBuffsComponent)).First(pip.world)
 	if !ok {
 		return 1.0 // バフコンポーネントがなければ効果なし
 	}
 	teamBuffs := component.TeamBuffsComponent.Get(teamBuffsEntry)
-	settings := component.SettingsComponent.Get(entry)
-
-	teamID := settings.Team
-	buffType := core.BuffTypeAccuracy
-
-	maxMultiplier := 1.0
+	teamID := component.SettingsComponent.Get(entry).Team
 
-	if teamBuffMap, teamOk := teamBuffs.Buffs[teamID]; teamOk {
-		if buffSources, buffOk := teamBuffMap[buffType]; buffOk {
-			for _, buff := range buffSources {
-				if buff.Value > maxMultiplier {
-					maxMultiplier = buff.Value
-				}
-			}
-		}
+	buffSources := teamBuffs.Buffs[teamID][buffType]
+	if len(buffSources) == 0 {
+		return 1.0
 	}
-
-	return maxMultiplier
+	return aggregateTeamBuffs(pip.config, buffType, buffSources, entity.GetActionQueueComponent(pip.world).ActionSeq)
 }
 
 // RemoveBuffsFromSource は、指定されたパーツインスタンスが提供していたバフをすべて削除します。
@@ -232,6 +329,30 @@ func (pip *PartInfoProvider) RemoveBuffsFromSource(entry *donburi.Entry, partIns
 	}
 }
 
+// GetOverloadRatio は、機体の総重量が脚部の積載量をどれだけ超えているかの割合を返します。
+// 積載量以内であれば 0 を返します。破壊されたパーツも重量として計算に含めます。
+func (pip *PartInfoProvider) GetOverloadRatio(entry *donburi.Entry) float64 {
+	if entry == nil || !entry.HasComponent(component.PartsComponent) {
+		return 0
+	}
+	partsComp := component.PartsComponent.Get(entry)
+	weight, capacity := 0, 0
+	for slot, partInst := range partsComp.Map {
+		if partInst == nil {
+			continue
+		}
+		partDef, found := pip.gameDataManager.GetPartDefinition(partInst.DefinitionID)
+		if !found {
+			continue
+		}
+		weight += partDef.Weight
+		if slot == core.PartSlotLegs {
+			capacity = partDef.LoadCapacity
+		}
+	}
+	return data.OverloadRatio(data.LoadRatio(weight, capacity))
+}
+
 // CalculateGaugeDuration は、行動の基本時間と推進力を基に、
 // 最終的なゲージの持続時間（tick数）を計算します。
 func (pip *PartInfoProvider) CalculateGaugeDuration(baseSeconds float64, entry *donburi.Entry) float64 {
@@ -256,6 +377,9 @@ func (pip *PartInfoProvider) CalculateGaugeDuration(baseSeconds float64, entry *
 	propulsionFactor := 1.0 + (float64(propulsion) * balanceConfig.PropulsionEffectRate)
 	totalTicks := (baseSeconds * 60.0) / (balanceConfig.GameSpeedMultiplier * propulsionFactor)
 
+	// 過積載の場合はゲージの進行が遅くなります
+	totalTicks *= 1.0 + pip.GetOverloadRatio(entry)*pip.config.Load.ChargePenaltyFactor
+
 	if totalTicks < 1 {
 		return 1
 	}
@@ -0,0 +1,73 @@
+package system
+
+import (
+	"log"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// ApplyScanMark は、行動者のチームによるスキャンのマークをターゲットに付けます。
+// すでにマークされている場合は、スキャンしたチームと持続時間を上書きします。
+func ApplyScanMark(world donburi.World, config *data.Config, actingEntry, targetEntry *donburi.Entry) {
+	expiresAtSeq := 0
+	if config.Scan.DurationTurns > 0 {
+		expiresAtSeq = entity.GetActionQueueComponent(world).ActionSeq + config.Scan.DurationTurns
+	}
+
+	mark := &component.ScanMark{
+		ScannedBy:      component.SettingsComponent.Get(actingEntry).Team,
+		ExpiresAtSeq:   expiresAtSeq,
+		DefenseBlocked: true,
+	}
+	if targetEntry.HasComponent(component.ScanMarkComponent) {
+		component.ScanMarkComponent.SetValue(targetEntry, *mark)
+	} else {
+		donburi.Add(targetEntry, component.ScanMarkComponent, mark)
+	}
+	log.Printf("%s が %s をスキャンした。", component.SettingsComponent.Get(actingEntry).Name, component.SettingsComponent.Get(targetEntry).Name)
+}
+
+// ConsumeScanDefenseBlock は、ターゲットのマークが次の攻撃の防御を封じている場合にその効果を消費し、true を返します。
+func ConsumeScanDefenseBlock(targetEntry *donburi.Entry) bool {
+	if !targetEntry.HasComponent(component.ScanMarkComponent) {
+		return false
+	}
+	mark := component.ScanMarkComponent.Get(targetEntry)
+	if !mark.DefenseBlocked {
+		return false
+	}
+	mark.DefenseBlocked = false
+	return true
+}
+
+// IsScannedBy は、機体が指定したチームのスキャンでマークされているかを返します。
+func IsScannedBy(entry *donburi.Entry, team core.TeamID) bool {
+	if !entry.HasComponent(component.ScanMarkComponent) {
+		return false
+	}
+	return component.ScanMarkComponent.Get(entry).ScannedBy == team
+}
+
+// UpdateScanMarkExpirySystem は、持続時間が切れたマークと機能停止した機体のマークを取り除きます。
+func UpdateScanMarkExpirySystem(world donburi.World) {
+	actionSeq := entity.GetActionQueueComponent(world).ActionSeq
+
+	expired := make([]*donburi.Entry, 0)
+	query.NewQuery(filter.Contains(component.ScanMarkComponent)).Each(world, func(entry *donburi.Entry) {
+		mark := component.ScanMarkComponent.Get(entry)
+		if (mark.ExpiresAtSeq > 0 && actionSeq >= mark.ExpiresAtSeq) || component.StateComponent.Get(entry).CurrentState == core.StateBroken {
+			expired = append(expired, entry)
+		}
+	})
+	for _, entry := range expired {
+		log.Printf("%s のスキャンの効果が切れた。", component.SettingsComponent.Get(entry).Name)
+		entry.RemoveComponent(component.ScanMarkComponent)
+	}
+}
@@ -0,0 +1,192 @@
+package system
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"sort"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// BattleSimulator は、UIを介さずに戦闘ワールドを進めるヘッドレスのシミュレーターです。
+// 戦闘シーンと同じシステム（ゲージ進行、行動の実行、クールダウンなど）を、渡されたワールドと乱数に束縛して使います。
+// 先読みAIが複製したワールドで行動の結果を試すために使うため、元のワールドを渡してはいけません。
+type BattleSimulator struct {
+	world                  donburi.World
+	config                 *data.Config
+	rand                   *rand.Rand
+	partInfoProvider       PartInfoProviderInterface
+	damageCalculator       *DamageCalculator
+	hitCalculator          *HitCalculator
+	targetSelector         *TargetSelector
+	chargeInitiationSystem *ChargeInitiationSystem
+	statusEffectSystem     *StatusEffectSystem
+	postActionEffectSystem *PostActionEffectSystem
+	victoryRule            VictoryRule
+
+	// decide は行動を選ぶ機体ごとに呼ばれ、true を返した場合はその機体の行動が決定済みとみなされます。
+	// nil の場合や false を返した場合は、機体の性格に従って行動を選びます。
+	decide func(entry *donburi.Entry) bool
+}
+
+// NewBattleSimulator は、指定したワールドと乱数を使う BattleSimulator を生成します。
+func NewBattleSimulator(world donburi.World, config *data.Config, gdm *data.GameDataManager, rand *rand.Rand) *BattleSimulator {
+	logger := data.NewBattleLogger(gdm)
+	pip := NewPartInfoProvider(world, config, gdm)
+	dc := NewDamageCalculator(world, config, pip, gdm, rand, logger)
+	ses := NewStatusEffectSystem(world, dc)
+	return &BattleSimulator{
+		world:                  world,
+		config:                 config,
+		rand:                   rand,
+		partInfoProvider:       pip,
+		damageCalculator:       dc,
+		hitCalculator:          NewHitCalculator(world, config, pip, rand, logger),
+		targetSelector:         NewTargetSelector(world, config, pip),
+		chargeInitiationSystem: NewChargeInitiationSystem(world, config, pip),
+		statusEffectSystem:     ses,
+		postActionEffectSystem: NewPostActionEffectSystem(world, ses, gdm, pip),
+		victoryRule:            NewVictoryRule(config, gdm),
+	}
+}
+
+// headlessConfig は、ヘッドレスの対戦で使う設定の複製を返します。
+// 先読みAIの探索時間の上限は実行環境によって結果が変わるため、回数の上限だけで探索させ、同じシードから同じ結果が得られるようにします。
+func headlessConfig(config data.Config) *data.Config {
+	config.Lookahead.TimeBudgetMillis = 0
+	return &config
+}
+
+// withLogsSilenced は、fn の実行中だけ標準のログ出力を止めます。シミュレーション中の戦闘ログは大量になるためです。
+func withLogsSilenced(fn func()) {
+	logWriter := log.Writer()
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(logWriter)
+	fn()
+}
+
+// Run は、決着がつくか maxTicks ティックが経過するまで戦闘を進め、最後の判定結果を返します。
+// ラウンド制の戦闘では、ラウンドの終了でシミュレーションを打ち切ります。
+func (s *BattleSimulator) Run(maxTicks int) core.GameEndResult {
+	result := CheckGameEndSystem(s.world, s.victoryRule)
+	for tick := 0; tick < maxTicks && !result.IsGameOver && !result.IsRoundOver; tick++ {
+		result = s.step()
+	}
+	return result
+}
+
+// RunMatch は、ラウンド制の戦闘ではラウンドをまたいで、試合の決着がつくか maxTicks ティックが経過するまで戦闘を進めます。
+func (s *BattleSimulator) RunMatch(maxTicks int) core.GameEndResult {
+	result := CheckGameEndSystem(s.world, s.victoryRule)
+	for tick := 0; tick < maxTicks && !result.IsGameOver; tick++ {
+		if result.IsRoundOver {
+			StartNextRoundSystem(s.world, s.partInfoProvider.GetGameDataManager(), result)
+		}
+		result = s.step()
+	}
+	return result
+}
+
+// step は戦闘シーンの1ティック分（ゲージ進行、行動選択、行動の実行と後処理、ステータス効果、終了判定）を進めます。
+func (s *BattleSimulator) step() core.GameEndResult {
+	UpdateGaugeSystem(s.world, s.rand)
+	UpdatePositionSystem(s.world, s.config)
+	return s.finishTick()
+}
+
+// finishTick はゲージ進行より後の、行動選択から終了判定までを進めます。
+// 戦闘シーンで行動選択の途中に複製したワールドは、このティックの残りをここで進めてから Run します。
+func (s *BattleSimulator) finishTick() core.GameEndResult {
+	s.selectActions()
+
+	// 戦闘シーンではアニメーションとメッセージ表示を挟んで1件ずつ処理する実行キューを、ここでは続けて処理します。
+	actionQueueComp := entity.GetActionQueueComponent(s.world)
+	for len(actionQueueComp.Queue) > 0 {
+		results, _ := UpdateActionQueueSystem(
+			s.world,
+			s.damageCalculator,
+			s.hitCalculator,
+			s.targetSelector,
+			s.partInfoProvider,
+			s.config,
+			s.statusEffectSystem,
+			s.postActionEffectSystem,
+			s.rand,
+		)
+		for i := range results {
+			s.finishAction(&results[i])
+		}
+	}
+
+	s.statusEffectSystem.Update()
+	return CheckGameEndSystem(s.world, s.victoryRule)
+}
+
+// selectActions はアイドル状態のすべての機体の行動を、チームと表示順による固定の順序で選びます。
+// プレイヤーの機体もメダルの性格に従って行動を選びます。
+func (s *BattleSimulator) selectActions() {
+	var idleEntries []*donburi.Entry
+	query.NewQuery(filter.Contains(component.StateComponent, component.SettingsComponent)).Each(s.world, func(entry *donburi.Entry) {
+		if component.StateComponent.Get(entry).CurrentState == core.StateIdle {
+			idleEntries = append(idleEntries, entry)
+		}
+	})
+	sort.Slice(idleEntries, func(i, j int) bool {
+		return compareSettingsOrder(idleEntries[i], idleEntries[j])
+	})
+
+	for _, entry := range idleEntries {
+		if s.decide != nil && s.decide(entry) {
+			continue
+		}
+		aiSelectActionWithPersonality(
+			s.world,
+			entry,
+			rolloutPersonality(entry),
+			s.partInfoProvider,
+			s.chargeInitiationSystem,
+			s.targetSelector,
+			s.hitCalculator,
+			s.damageCalculator,
+			s.config,
+			s.rand,
+		)
+	}
+}
+
+// finishAction は戦闘シーンの PostActionState と同じく、行動後のクールダウン開始・期限切れの効果の削除・AIの履歴の更新を行います。
+func (s *BattleSimulator) finishAction(result *component.ActionResult) {
+	actingEntry := result.ActingEntry
+	if actingEntry != nil && actingEntry.Valid() && component.StateComponent.Get(actingEntry).CurrentState != core.StateBroken {
+		if result.IsCancelled {
+			CancelActionSystem(actingEntry)
+		} else {
+			StartCooldownSystem(actingEntry, s.world, s.config, s.partInfoProvider)
+		}
+	}
+	UpdateTeamBuffExpirySystem(s.world)
+	UpdateScanMarkExpirySystem(s.world)
+	UpdateHistorySystem(s.world, result)
+}
+
+// rolloutPersonality はシミュレーション中に機体が使う性格を返します。
+// AIはAIコンポーネントの性格を、プレイヤーの機体はメダルの性格を使います。
+// 先読みAIの性格はシミュレーションの中で再び探索しないよう、代わりの行動計画に置き換えます。
+func rolloutPersonality(entry *donburi.Entry) AIPersonality {
+	personality, ok := PersonalityRegistry[PersonalityIDFor(entry)]
+	if !ok {
+		personality = PersonalityRegistry["リーダー"] // フォールバック
+	}
+	if lookahead, ok := personality.ActionPlanner.(*LookaheadPlanner); ok {
+		personality.ActionPlanner = lookahead.RolloutPlanner
+	}
+	return personality
+}
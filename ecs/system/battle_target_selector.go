@@ -28,7 +28,11 @@ func NewTargetSelector(world donburi.World, config *data.Config, pip PartInfoPro
 }
 
 // SelectDefensePart は防御に使用するパーツのインスタンスを選択します。
+// 難易度で防御しない設定になっているAIの機体は、防御に使うパーツを選びません。
 func (ts *TargetSelector) SelectDefensePart(target *donburi.Entry) *core.PartInstanceData {
+	if aiDifficultySettings(ts.config, target).IgnoreDefense {
+		return nil
+	}
 	partsComp := component.PartsComponent.Get(target)
 	if partsComp == nil {
 		return nil
@@ -38,9 +42,10 @@ func (ts *TargetSelector) SelectDefensePart(target *donburi.Entry) *core.PartIns
 	var bestPartInstance *core.PartInstanceData
 	maxArmor := -1 // Initialize with a value lower than any possible armor
 
-	// 腕部と脚部を優先して、最も装甲の高いパーツを探す
-	for _, partInst := range partsMap {
-		if partInst.IsBroken {
+	// 腕部と脚部を優先して、最も装甲の高いパーツを探す。装甲が同じ場合に結果が変わらないよう、固定の順序で調べます
+	for _, slot := range []core.PartSlotKey{core.PartSlotRightArm, core.PartSlotLeftArm, core.PartSlotLegs} {
+		partInst, ok := partsMap[slot]
+		if !ok || partInst == nil || partInst.IsBroken {
 			continue
 		}
 		partDef, defFound := ts.partInfoProvider.GetGameDataManager().GetPartDefinition(partInst.DefinitionID)
@@ -86,57 +91,71 @@ func (ts *TargetSelector) SelectPartToDamage(target, actingEntry *donburi.Entry,
 		return nil
 	}
 
-	// 行動者の性格を取得
-	personality := "ジョーカー" // デフォルト
-	if actingEntry.HasComponent(component.MedalComponent) {
-		personality = component.MedalComponent.Get(actingEntry).Personality
-	}
-
-	switch personality {
-	case "クラッシャー":
-		sort.Slice(vulnerableInstances, func(i, j int) bool {
-			return vulnerableInstances[i].CurrentArmor > vulnerableInstances[j].CurrentArmor
-		})
-		return vulnerableInstances[0]
-	case "ハンター":
-		sort.Slice(vulnerableInstances, func(i, j int) bool {
-			return vulnerableInstances[i].CurrentArmor < vulnerableInstances[j].CurrentArmor
-		})
-		return vulnerableInstances[0]
-	default: // "ジョーカー" やその他の性格
-		return vulnerableInstances[rand.Intn(len(vulnerableInstances))]
+	// 行動者の性格のルールでパーツを選びます。性格が見つからない場合はランダムに選びます。
+	rule := SelectRandomPartToDamage
+	if personality, ok := PersonalityRegistry[PersonalityIDFor(actingEntry)]; ok && personality.PartToDamage != nil {
+		rule = personality.PartToDamage
 	}
+	return rule(vulnerableInstances, rand)
 }
 
-// FindClosestEnemy は指定されたエンティティに最も近い敵エンティティを見つけます。
-func (ts *TargetSelector) FindClosestEnemy(actingEntry *donburi.Entry, partInfoProvider PartInfoProviderInterface) *donburi.Entry {
+// FindClosestEnemy は指定されたエンティティからバトルフィールド上の距離が最も近い敵エンティティを見つけます。
+func (ts *TargetSelector) FindClosestEnemy(actingEntry *donburi.Entry) *donburi.Entry {
 	var closestEnemy *donburi.Entry
-	var minDiff float32 = math.MaxFloat32
-
-	actingProgress := partInfoProvider.GetNormalizedActionProgress(actingEntry)
+	minDistance := math.MaxFloat64
 
 	for _, enemy := range ts.GetTargetableEnemies(actingEntry) {
-		enemyProgress := partInfoProvider.GetNormalizedActionProgress(enemy)
-		diff := float32(math.Abs(float64(actingProgress - enemyProgress))) // 抽象的な進行度の差を計算
-		if diff < minDiff {
-			minDiff = diff
+		distance := CalculateDistance(actingEntry, enemy)
+		if distance < minDistance {
+			minDistance = distance
 			closestEnemy = enemy
 		}
 	}
 	return closestEnemy
 }
 
+// CanReachWithMelee は、格闘攻撃の実行時に target へ攻撃が届くかを予測します。
+// 行動者は実行ラインへの前進を終えた位置から、ターゲットは現在の位置から距離を測ります。
+func (ts *TargetSelector) CanReachWithMelee(actingEntry, target *donburi.Entry) bool {
+	if !actingEntry.HasComponent(component.PositionComponent) || !target.HasComponent(component.PositionComponent) {
+		return true
+	}
+	position := component.PositionComponent.Get(actingEntry)
+	movement := legMovementFor(ts.config, position.LegType)
+	executionX := position.Home.X + (position.FrontX-position.Home.X)*movement.AdvanceRatio
+	targetPosition := component.PositionComponent.Get(target).Current
+	return math.Hypot(executionX-targetPosition.X, position.Home.Y-targetPosition.Y) <= ts.config.Spatial.MeleeRange
+}
+
+// FilterReachableParts は、格闘攻撃が届く敵がいない場合に、使えるパーツから格闘パーツを除きます。
+// 格闘パーツしか残らない場合は、行動できなくならないよう元のパーツをそのまま返します。
+func (ts *TargetSelector) FilterReachableParts(actingEntry *donburi.Entry, parts []core.AvailablePart) []core.AvailablePart {
+	for _, enemy := range ts.GetTargetableEnemies(actingEntry) {
+		if ts.CanReachWithMelee(actingEntry, enemy) {
+			return parts
+		}
+	}
+	var reachable []core.AvailablePart
+	for _, available := range parts {
+		if available.PartDef.Category != core.CategoryMelee {
+			reachable = append(reachable, available)
+		}
+	}
+	if len(reachable) == 0 {
+		return parts
+	}
+	return reachable
+}
+
 // GetTargetableEnemies は指定されたエンティティが攻撃可能な敵のリストを返します。
-// 破壊されていない敵チームのエンティティを返します。
+// 破壊されていない、自分以外の全チームのエンティティを返します（3チーム以上やバトルロイヤルにも対応）。
 func (ts *TargetSelector) GetTargetableEnemies(actingEntry *donburi.Entry) []*donburi.Entry {
-	opponentTeamID := ts.GetOpponentTeam(actingEntry)
 	candidates := []*donburi.Entry{}
 	query.NewQuery(filter.Contains(component.SettingsComponent)).Each(ts.world, func(entry *donburi.Entry) {
 		if component.StateComponent.Get(entry).CurrentState == core.StateBroken {
 			return
 		}
-		settings := component.SettingsComponent.Get(entry)
-		if settings.Team == opponentTeamID {
+		if ts.IsOpponent(actingEntry, entry) {
 			candidates = append(candidates, entry)
 		}
 	})
@@ -144,15 +163,49 @@ func (ts *TargetSelector) GetTargetableEnemies(actingEntry *donburi.Entry) []*do
 	sort.Slice(candidates, func(i, j int) bool {
 		iSettings := component.SettingsComponent.Get(candidates[i])
 		jSettings := component.SettingsComponent.Get(candidates[j])
+		if iSettings.Team != jSettings.Team {
+			return iSettings.Team < jSettings.Team
+		}
 		return iSettings.DrawIndex < jSettings.DrawIndex
 	})
 	return candidates
 }
 
-// GetOpponentTeam は指定されたエンティティの敵チームIDを返します。
-func (ts *TargetSelector) GetOpponentTeam(actingEntry *donburi.Entry) core.TeamID {
-	if component.SettingsComponent.Get(actingEntry).Team == core.Team1 {
-		return core.Team2
-	}
-	return core.Team1
+// GetAllies は指定されたエンティティと同じチームの、破壊されていないエンティティのリストを返します（自分自身を含みます）。
+func (ts *TargetSelector) GetAllies(actingEntry *donburi.Entry) []*donburi.Entry {
+	allies := []*donburi.Entry{}
+	query.NewQuery(filter.Contains(component.SettingsComponent)).Each(ts.world, func(entry *donburi.Entry) {
+		if component.StateComponent.Get(entry).CurrentState == core.StateBroken {
+			return
+		}
+		if !ts.IsOpponent(actingEntry, entry) {
+			allies = append(allies, entry)
+		}
+	})
+
+	sort.Slice(allies, func(i, j int) bool {
+		return component.SettingsComponent.Get(allies[i]).DrawIndex < component.SettingsComponent.Get(allies[j]).DrawIndex
+	})
+	return allies
+}
+
+// IsOpponent は2つのエンティティが敵対チームに属しているかを返します。
+func (ts *TargetSelector) IsOpponent(actingEntry, other *donburi.Entry) bool {
+	return component.SettingsComponent.Get(actingEntry).Team != component.SettingsComponent.Get(other).Team
+}
+
+// GetOpponentTeams は指定されたエンティティから見た敵チームIDの一覧をチームID順に返します。
+func (ts *TargetSelector) GetOpponentTeams(actingEntry *donburi.Entry) []core.TeamID {
+	ownTeam := component.SettingsComponent.Get(actingEntry).Team
+	seen := make(map[core.TeamID]bool)
+	var teams []core.TeamID
+	query.NewQuery(filter.Contains(component.SettingsComponent)).Each(ts.world, func(entry *donburi.Entry) {
+		team := component.SettingsComponent.Get(entry).Team
+		if team != ownTeam && !seen[team] {
+			seen[team] = true
+			teams = append(teams, team)
+		}
+	})
+	sort.Slice(teams, func(i, j int) bool { return teams[i] < teams[j] })
+	return teams
 }
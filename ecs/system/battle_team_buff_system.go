@@ -0,0 +1,145 @@
+package system
+
+import (
+	"log"
+	"math"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// minTeamBuffMultiplier は設定によらないチームバフ乗数の下限です。
+const minTeamBuffMultiplier = 0.01
+
+// ApplyTeamBuffs は行動の計算式に定義されたチームバフ・デバフを付与し、付与した内容を返します。
+// 効果量はパーツの威力から求め、同じ種類の効果の重ね方は設定（TeamBuffs.Stacking）に従います。
+func ApplyTeamBuffs(
+	world donburi.World,
+	config *data.Config,
+	actingEntry *donburi.Entry,
+	sourcePart core.PartSlotKey,
+	actingPartDef *core.PartDefinition,
+	effects []core.TeamBuffEffect,
+	targetEntry *donburi.Entry,
+) []component.AppliedTeamBuff {
+	teamBuffsEntry, ok := query.NewQuery(filter.Contains(component.TeamBuffsComponent)).First(world)
+	if !ok {
+		log.Println("エラー: TeamBuffsComponent がワールドに見つかりません。")
+		return nil
+	}
+	teamBuffs := component.TeamBuffsComponent.Get(teamBuffsEntry)
+	actionSeq := entity.GetActionQueueComponent(world).ActionSeq
+
+	var applied []component.AppliedTeamBuff
+	for _, effect := range effects {
+		var teamID core.TeamID
+		switch effect.Target {
+		case core.BuffTargetEnemy:
+			if targetEntry == nil || !targetEntry.Valid() {
+				continue
+			}
+			teamID = component.SettingsComponent.Get(targetEntry).Team
+		default:
+			teamID = component.SettingsComponent.Get(actingEntry).Team
+		}
+
+		newBuffSource := &component.BuffSource{
+			SourceEntry: actingEntry,
+			SourcePart:  sourcePart,
+			Value:       1.0 + effect.PowerScale*float64(actingPartDef.Power)/100.0,
+		}
+		if effect.DurationTurns > 0 {
+			newBuffSource.ExpiresAtSeq = actionSeq + effect.DurationTurns
+		}
+
+		if _, exists := teamBuffs.Buffs[teamID]; !exists {
+			teamBuffs.Buffs[teamID] = make(map[core.BuffType][]*component.BuffSource)
+		}
+		existingBuffs := teamBuffs.Buffs[teamID][effect.Type]
+		filteredBuffs := make([]*component.BuffSource, 0, len(existingBuffs)+1)
+		if stackingRuleFor(config, effect.Type) != core.BuffStackingReplace {
+			// 同じパーツからの効果はかけ直しとして上書きし、それ以外は残します
+			for _, buff := range existingBuffs {
+				if buff.SourceEntry != actingEntry || buff.SourcePart != sourcePart {
+					filteredBuffs = append(filteredBuffs, buff)
+				}
+			}
+		}
+		teamBuffs.Buffs[teamID][effect.Type] = append(filteredBuffs, newBuffSource)
+
+		log.Printf("チーム%dに%s効果を付与: %s (%.2f倍, %d行動)", teamID, effect.Type, component.SettingsComponent.Get(actingEntry).Name, newBuffSource.Value, effect.DurationTurns)
+		applied = append(applied, component.AppliedTeamBuff{Team: teamID, Type: effect.Type, Multiplier: newBuffSource.Value})
+	}
+	return applied
+}
+
+// UpdateTeamBuffExpirySystem は、行動の通し番号が失効時刻に達したチームバフ・デバフを削除します。
+func UpdateTeamBuffExpirySystem(world donburi.World) {
+	teamBuffsEntry, ok := query.NewQuery(filter.Contains(component.TeamBuffsComponent)).First(world)
+	if !ok {
+		return
+	}
+	teamBuffs := component.TeamBuffsComponent.Get(teamBuffsEntry)
+	actionSeq := entity.GetActionQueueComponent(world).ActionSeq
+
+	for teamID, buffMap := range teamBuffs.Buffs {
+		for buffType, buffSources := range buffMap {
+			activeBuffs := make([]*component.BuffSource, 0, len(buffSources))
+			for _, buff := range buffSources {
+				if isBuffActive(buff, actionSeq) {
+					activeBuffs = append(activeBuffs, buff)
+				} else {
+					log.Printf("チーム%dの%s効果が切れた。", teamID, buffType)
+				}
+			}
+			teamBuffs.Buffs[teamID][buffType] = activeBuffs
+		}
+	}
+}
+
+// aggregateTeamBuffs は、同じ種類のチームバフ・デバフを重ね方の規則に従って1つの乗数にまとめます。
+func aggregateTeamBuffs(config *data.Config, buffType core.BuffType, buffSources []*component.BuffSource, actionSeq int) float64 {
+	maxBuff, maxDebuff, sum := 0.0, 0.0, 0.0
+	latest := 0.0
+	for _, buff := range buffSources {
+		if !isBuffActive(buff, actionSeq) {
+			continue
+		}
+		delta := buff.Value - 1.0
+		maxBuff = math.Max(maxBuff, delta)
+		maxDebuff = math.Min(maxDebuff, delta)
+		sum += delta
+		latest = delta
+	}
+
+	var multiplier float64
+	switch stackingRuleFor(config, buffType) {
+	case core.BuffStackingSum:
+		multiplier = 1.0 + sum
+	case core.BuffStackingReplace:
+		multiplier = 1.0 + latest
+	default:
+		multiplier = 1.0 + maxBuff + maxDebuff
+	}
+	// 乗数で時間を割る種類もあるため、0 以下にはしません
+	return math.Max(multiplier, math.Max(config.TeamBuffs.MinMultiplier, minTeamBuffMultiplier))
+}
+
+// stackingRuleFor はバフの種類ごとの重ね方を返します。設定がない場合は max です。
+func stackingRuleFor(config *data.Config, buffType core.BuffType) core.BuffStackingRule {
+	if rule, ok := config.TeamBuffs.Stacking[buffType]; ok {
+		return rule
+	}
+	return core.BuffStackingMax
+}
+
+// isBuffActive はバフが失効していないかを返します。
+func isBuffActive(buff *component.BuffSource, actionSeq int) bool {
+	return buff.ExpiresAtSeq == 0 || actionSeq < buff.ExpiresAtSeq
+}
@@ -5,17 +5,18 @@ import (
 	"math/rand"
 
 	"medarot-ebiten/core"
+	"medarot-ebiten/data"
 	"medarot-ebiten/ecs/component"
 
 	"github.com/yohamta/donburi"
-	"github.com/yohamta/donburi/filter"
-	"github.com/yohamta/donburi/query"
 )
 
 // --- BaseAttackHandler ---
 
 // BaseAttackHandler は、すべての攻撃アクションに共通するロジックをカプセル化します。
-type BaseAttackHandler struct{}
+type BaseAttackHandler struct {
+	config *data.Config
+}
 
 // Execute は TraitActionHandler インターフェースを実装します。
 func (h *BaseAttackHandler) Execute(
@@ -48,16 +49,32 @@ func (h *BaseAttackHandler) Execute(
 		return result
 	}
 
-	didHit := performHitCheck(actingEntry, targetEntry, actingPartDef, intent.SelectedPartKey, hitCalculator)
-	result.ActionDidHit = didHit
-	if !didHit {
+	// 格闘攻撃は一定距離内の相手にしか届きません。
+	if actingPartDef.Category == core.CategoryMelee && !hitCalculator.IsWithinMeleeRange(actingEntry, targetEntry) {
+		result.ActionDidHit = false
+		result.IsOutOfRange = true
 		return result
 	}
 
-	// ダメージ計算と防御判定をヘルパー関数に集約
-	applyDamageAndDefense(&result, actingEntry, actingPartDef, intent.SelectedPartKey, damageCalculator, hitCalculator, targetSelector, partInfoProvider)
-
-	finalizeActionResult(&result, partInfoProvider)
+	// 味方が直前に同じ機体・同じパーツへ命中させていれば、コンボの効果（ダメージ倍率・必中）を適用します。
+	combo := FindComboBonus(world, h.config, actingEntry, actingPartDef.Trait, targetEntry, targetPartSlot)
+
+	// 攻撃パターン（単発・連続攻撃・拡散・範囲）に従ってヒットを発生させ、代表となるヒットを結果に写します。
+	pattern := partInfoProvider.GetAttackPattern(actingPartDef)
+	result.AttackPattern = pattern.Pattern
+	result.Hits = executeAttackPattern(&attackPatternContext{
+		actingEntry:      actingEntry,
+		actingPartDef:    actingPartDef,
+		selectedPartKey:  intent.SelectedPartKey,
+		damageCalculator: damageCalculator,
+		hitCalculator:    hitCalculator,
+		targetSelector:   targetSelector,
+		partInfoProvider: partInfoProvider,
+		rand:             rand,
+		combo:            combo,
+	}, pattern, targetEntry, targetPartSlot)
+	applyPrimaryHit(&result)
+	applyComboResult(world, &result, combo)
 
 	return result
 }
@@ -88,40 +105,8 @@ func (h *SupportTraitExecutor) Execute(
 		WeaponType:     actingPartDef.WeaponType,
 	}
 
-	teamBuffsEntry, ok := query.NewQuery(filter.Contains(component.TeamBuffsComponent)).First(world)
-	if !ok {
-		log.Println("エラー: TeamBuffsComponent がワールドに見つかりません。")
-		result.ActionDidHit = false
-		return result
-	}
-	teamBuffs := component.TeamBuffsComponent.Get(teamBuffsEntry)
-
-	buffValue := 1.0 + (float64(actingPartDef.Power) / 100.0)
-	newBuffSource := &component.BuffSource{
-		SourceEntry: actingEntry,
-		SourcePart:  intent.SelectedPartKey,
-		Value:       buffValue,
-	}
-
-	teamID := settings.Team
-	buffType := core.BuffTypeAccuracy
-
-	if _, exists := teamBuffs.Buffs[teamID]; !exists {
-		teamBuffs.Buffs[teamID] = make(map[core.BuffType][]*component.BuffSource)
-	}
-	if _, exists := teamBuffs.Buffs[teamID][buffType]; !exists {
-		teamBuffs.Buffs[teamID][buffType] = make([]*component.BuffSource, 0)
-	}
-
-	existingBuffs := teamBuffs.Buffs[teamID][buffType]
-	filteredBuffs := make([]*component.BuffSource, 0, len(existingBuffs))
-	for _, buff := range existingBuffs {
-		if buff.SourceEntry != actingEntry || buff.SourcePart != intent.SelectedPartKey {
-			filteredBuffs = append(filteredBuffs, buff)
-		}
-	}
-	teamBuffs.Buffs[teamID][buffType] = append(filteredBuffs, newBuffSource)
-	log.Printf("チーム%dに命中バフを追加: %s (%.2f倍)", teamID, settings.Name, buffValue)
+	// チーム全体へのバフは、ActionExecutor が計算式（formulas.json の TeamBuffs）に従って付与します。
+	log.Printf("%s が支援を実行しました。", settings.Name)
 
 	return result
 }
@@ -167,6 +152,94 @@ func (h *ObstructTraitExecutor) Execute(
 	result.TargetEntry = targetEntry
 	result.DefenderName = component.SettingsComponent.Get(targetEntry).Name
 
-	log.Printf("%s が %s に妨害を実行しました（現在効果なし）。", settings.Name, result.DefenderName)
+	// 妨害によるデバフは、ActionExecutor が計算式（formulas.json の TeamBuffs）に従ってターゲットのチームに付与します。
+	log.Printf("%s が %s に妨害を実行しました。", settings.Name, result.DefenderName)
+	return result
+}
+
+// RepairTraitExecutor は TraitRepair の介入アクションを処理します。
+// 選択されたターゲットが味方であればそのパーツを、そうでなければ装甲の割合が最も低い味方のパーツを、パーツの威力の分だけ修復します。
+type RepairTraitExecutor struct{}
+
+func (h *RepairTraitExecutor) Execute(
+	actingEntry *donburi.Entry,
+	world donburi.World,
+	intent *core.ActionIntent,
+	damageCalculator *DamageCalculator,
+	hitCalculator *HitCalculator,
+	targetSelector *TargetSelector,
+	partInfoProvider PartInfoProviderInterface,
+	actingPartDef *core.PartDefinition,
+	rand *rand.Rand,
+) component.ActionResult {
+	settings := component.SettingsComponent.Get(actingEntry)
+	result := component.ActionResult{
+		ActingEntry:    actingEntry,
+		ActionDidHit:   true,
+		AttackerName:   settings.Name,
+		ActionName:     actingPartDef.PartName,
+		ActionTrait:    actingPartDef.Trait,
+		ActionCategory: actingPartDef.Category,
+		WeaponType:     actingPartDef.WeaponType,
+	}
+
+	var targetEntry *donburi.Entry
+	var targetPartSlot core.PartSlotKey
+	targetComp := component.TargetComponent.Get(actingEntry)
+	if targetComp.TargetEntity != 0 {
+		if entry := world.Entry(targetComp.TargetEntity); entry != nil && entry.Valid() && !targetSelector.IsOpponent(actingEntry, entry) {
+			if partInst := component.PartsComponent.Get(entry).Map[targetComp.TargetPartSlot]; partInst != nil && !partInst.IsBroken &&
+				component.StateComponent.Get(entry).CurrentState != core.StateBroken {
+				targetEntry, targetPartSlot = entry, targetComp.TargetPartSlot
+			}
+		}
+	}
+	if targetEntry == nil {
+		// チャージ中に修復先が破壊された場合や、ターゲットが敵の場合は、最も傷んだ味方のパーツを修復します。
+		targetEntry, targetPartSlot = FindMostDamagedAllyPart(actingEntry, targetSelector, partInfoProvider, 1.0)
+	}
+	if targetEntry == nil {
+		log.Printf("%s は修復を実行しましたが、修復が必要なパーツがありませんでした。", settings.Name)
+		return result
+	}
+
+	partInst := component.PartsComponent.Get(targetEntry).Map[targetPartSlot]
+	partDef, _ := partInfoProvider.GetGameDataManager().GetPartDefinition(partInst.DefinitionID)
+	repaired := min(actingPartDef.Power, partDef.MaxArmor-partInst.CurrentArmor)
+	partInst.CurrentArmor += repaired
+
+	result.TargetEntry = targetEntry
+	result.TargetPartSlot = targetPartSlot
+	result.DefenderName = component.SettingsComponent.Get(targetEntry).Name
+	result.TargetPartType = string(partDef.Type)
+	result.RepairedArmor = repaired
+	log.Printf("%s が %s の %s を修復しました (+%d)。", settings.Name, result.DefenderName, partDef.PartName, repaired)
 	return result
-}
\ No newline at end of file
+}
+
+// FindMostDamagedAllyPart は、味方（自分を含む）の破壊されていないパーツのうち、
+// 装甲の割合が maxRatio 以下で最も低いパーツを持つ機体とそのスロットを返します。装甲が減っていないパーツは対象外です。
+func FindMostDamagedAllyPart(actingEntry *donburi.Entry, targetSelector *TargetSelector, partInfoProvider PartInfoProviderInterface, maxRatio float64) (*donburi.Entry, core.PartSlotKey) {
+	gdm := partInfoProvider.GetGameDataManager()
+	var bestEntry *donburi.Entry
+	var bestSlot core.PartSlotKey
+	bestRatio := maxRatio
+	for _, ally := range targetSelector.GetAllies(actingEntry) {
+		partsMap := component.PartsComponent.Get(ally).Map
+		for _, slot := range []core.PartSlotKey{core.PartSlotHead, core.PartSlotRightArm, core.PartSlotLeftArm, core.PartSlotLegs} {
+			partInst := partsMap[slot]
+			if partInst == nil || partInst.IsBroken {
+				continue
+			}
+			partDef, found := gdm.GetPartDefinition(partInst.DefinitionID)
+			if !found || partDef.MaxArmor <= 0 || partInst.CurrentArmor >= partDef.MaxArmor {
+				continue
+			}
+			ratio := float64(partInst.CurrentArmor) / float64(partDef.MaxArmor)
+			if ratio <= bestRatio && (bestEntry == nil || ratio < bestRatio) {
+				bestEntry, bestSlot, bestRatio = ally, slot, ratio
+			}
+		}
+	}
+	return bestEntry, bestSlot
+}
@@ -0,0 +1,337 @@
+package system
+
+import (
+	"log"
+	"sort"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
+
+	"github.com/yohamta/donburi"
+	"github.com/yohamta/donburi/filter"
+	"github.com/yohamta/donburi/query"
+)
+
+// ticksPerSecond はゲームループの1秒あたりのティック数です（Ebitengineのデフォルト TPS）。
+const ticksPerSecond = 60
+
+// teamSummary は勝敗判定のために集計したチームごとの状況です。
+type teamSummary struct {
+	Team            core.TeamID
+	Leader          *donburi.Entry
+	FunctionalCount int
+	CurrentArmor    int
+	MaxArmor        int
+}
+
+// summarizeTeams はワールド内の全機体をチームごとに集計し、チームID順に並べて返します。
+func summarizeTeams(world donburi.World, gameDataManager *data.GameDataManager) []*teamSummary {
+	summaries := make(map[core.TeamID]*teamSummary)
+	query.NewQuery(filter.Contains(component.SettingsComponent)).Each(world, func(entry *donburi.Entry) {
+		settings := component.SettingsComponent.Get(entry)
+		summary, ok := summaries[settings.Team]
+		if !ok {
+			summary = &teamSummary{Team: settings.Team}
+			summaries[settings.Team] = summary
+		}
+		if settings.IsLeader {
+			summary.Leader = entry
+		}
+		if component.StateComponent.Get(entry).CurrentState != core.StateBroken {
+			summary.FunctionalCount++
+		}
+		if gameDataManager == nil {
+			return
+		}
+		for _, partInst := range component.PartsComponent.Get(entry).Map {
+			partDef, found := gameDataManager.GetPartDefinition(partInst.DefinitionID)
+			if !found {
+				continue
+			}
+			summary.CurrentArmor += partInst.CurrentArmor
+			summary.MaxArmor += partDef.MaxArmor
+		}
+	})
+
+	result := make([]*teamSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Team < result[j].Team })
+	return result
+}
+
+// defeatCheckFunc はチームが敗北しているかを判定し、敗北理由とメッセージパラメータを返します。
+type defeatCheckFunc func(summary *teamSummary) (bool, core.GameEndReason, map[string]interface{})
+
+// teamDisplayNumber はメッセージ表示用のチーム番号（1始まり）を返します。
+func teamDisplayNumber(team core.TeamID) int {
+	return int(team) + 1
+}
+
+// checkLeaderDefeat はリーダーの不在・機能停止、または全滅を敗北とみなします。
+func checkLeaderDefeat(summary *teamSummary) (bool, core.GameEndReason, map[string]interface{}) {
+	params := map[string]interface{}{"loser_team": teamDisplayNumber(summary.Team)}
+	if summary.Leader == nil {
+		return true, core.GameEndReasonLeaderAbsent, params
+	}
+	if component.PartsComponent.Get(summary.Leader).Map[core.PartSlotHead].IsBroken {
+		params["leader_name"] = component.SettingsComponent.Get(summary.Leader).Name
+		return true, core.GameEndReasonLeaderKO, params
+	}
+	return checkAnnihilation(summary)
+}
+
+// checkAnnihilation は行動可能な機体が0になったチームを敗北とみなします。
+func checkAnnihilation(summary *teamSummary) (bool, core.GameEndReason, map[string]interface{}) {
+	if summary.FunctionalCount == 0 {
+		return true, core.GameEndReasonAnnihilation, map[string]interface{}{"loser_team": teamDisplayNumber(summary.Team)}
+	}
+	return false, core.GameEndReasonNone, nil
+}
+
+// resolveByDefeat は各チームの敗北判定を行い、生き残りが1チーム以下になった時点で決着とします。
+// 全チームが同時に敗北した場合は引き分け（Winner = TeamNone）になります。
+func resolveByDefeat(summaries []*teamSummary, isDefeated defeatCheckFunc) core.GameEndResult {
+	var survivors []core.TeamID
+	var firstReason core.GameEndReason
+	var firstParams map[string]interface{}
+	for _, summary := range summaries {
+		defeated, reason, params := isDefeated(summary)
+		if !defeated {
+			survivors = append(survivors, summary.Team)
+			continue
+		}
+		if firstReason == core.GameEndReasonNone {
+			firstReason, firstParams = reason, params
+		}
+	}
+
+	if firstReason == core.GameEndReasonNone || len(survivors) > 1 {
+		return core.GameEndResult{Winner: core.TeamNone}
+	}
+
+	if len(survivors) == 0 {
+		return core.GameEndResult{IsGameOver: true, Winner: core.TeamNone, Reason: core.GameEndReasonDraw, Params: map[string]interface{}{}}
+	}
+	firstParams["winner_team"] = teamDisplayNumber(survivors[0])
+	return core.GameEndResult{IsGameOver: true, Winner: survivors[0], Reason: firstReason, Params: firstParams}
+}
+
+// --- LeaderKORule ---
+
+// LeaderKORule はリーダー機の頭部破壊（または全滅）で決着する標準ルールです。
+type LeaderKORule struct {
+	gameDataManager *data.GameDataManager
+}
+
+// NewLeaderKORule は新しいLeaderKORuleを生成します。
+func NewLeaderKORule(gameDataManager *data.GameDataManager) *LeaderKORule {
+	return &LeaderKORule{gameDataManager: gameDataManager}
+}
+
+func (r *LeaderKORule) Check(world donburi.World) core.GameEndResult {
+	return resolveByDefeat(summarizeTeams(world, r.gameDataManager), checkLeaderDefeat)
+}
+
+// --- AnnihilationRule ---
+
+// AnnihilationRule はリーダーに関係なく、相手チームを全機停止させたら勝利となるルールです。
+type AnnihilationRule struct {
+	gameDataManager *data.GameDataManager
+}
+
+// NewAnnihilationRule は新しいAnnihilationRuleを生成します。
+func NewAnnihilationRule(gameDataManager *data.GameDataManager) *AnnihilationRule {
+	return &AnnihilationRule{gameDataManager: gameDataManager}
+}
+
+func (r *AnnihilationRule) Check(world donburi.World) core.GameEndResult {
+	return resolveByDefeat(summarizeTeams(world, r.gameDataManager), checkAnnihilation)
+}
+
+// --- TimeLimitRule ---
+
+// TimeLimitRule は内側のルールで決着がつかないまま制限時間に達した場合、
+// 残り装甲の割合が最も高いチームを勝者とします。
+type TimeLimitRule struct {
+	inner           VictoryRule
+	limitTicks      int
+	gameDataManager *data.GameDataManager
+}
+
+// NewTimeLimitRule は新しいTimeLimitRuleを生成します。
+func NewTimeLimitRule(inner VictoryRule, limitSeconds float64, gameDataManager *data.GameDataManager) *TimeLimitRule {
+	return &TimeLimitRule{
+		inner:           inner,
+		limitTicks:      int(limitSeconds * ticksPerSecond),
+		gameDataManager: gameDataManager,
+	}
+}
+
+func (r *TimeLimitRule) Check(world donburi.World) core.GameEndResult {
+	if result := r.inner.Check(world); result.IsGameOver {
+		return result
+	}
+
+	victoryState := entity.GetVictoryStateComponent(world)
+	if victoryState.ElapsedTicks < r.limitTicks {
+		return core.GameEndResult{Winner: core.TeamNone}
+	}
+
+	// 残り装甲の割合で比較（チーム人数差の影響を受けないように割合を用いる）
+	winner := core.TeamNone
+	bestRatio := -1.0
+	isTie := false
+	for _, summary := range summarizeTeams(world, r.gameDataManager) {
+		ratio := 0.0
+		if summary.MaxArmor > 0 {
+			ratio = float64(summary.CurrentArmor) / float64(summary.MaxArmor)
+		}
+		switch {
+		case ratio > bestRatio:
+			winner, bestRatio, isTie = summary.Team, ratio, false
+		case ratio == bestRatio:
+			isTie = true
+		}
+	}
+
+	if isTie || winner == core.TeamNone {
+		return core.GameEndResult{IsGameOver: true, Winner: core.TeamNone, Reason: core.GameEndReasonTimeUpDraw, Params: map[string]interface{}{}}
+	}
+	return core.GameEndResult{
+		IsGameOver: true,
+		Winner:     winner,
+		Reason:     core.GameEndReasonTimeUp,
+		Params: map[string]interface{}{
+			"winner_team":   teamDisplayNumber(winner),
+			"armor_percent": int(bestRatio * 100),
+		},
+	}
+}
+
+// --- PartBreaksRule ---
+
+// PartBreaksRule は敵パーツを先に規定数破壊したチームが勝利となるルールです。
+// 規定数に達する前に全滅した場合は全滅ルールで決着します。
+type PartBreaksRule struct {
+	target          int
+	gameDataManager *data.GameDataManager
+}
+
+// NewPartBreaksRule は新しいPartBreaksRuleを生成します。
+func NewPartBreaksRule(target int, gameDataManager *data.GameDataManager) *PartBreaksRule {
+	return &PartBreaksRule{target: target, gameDataManager: gameDataManager}
+}
+
+func (r *PartBreaksRule) Check(world donburi.World) core.GameEndResult {
+	summaries := summarizeTeams(world, r.gameDataManager)
+	victoryState := entity.GetVictoryStateComponent(world)
+	for _, summary := range summaries {
+		count := victoryState.PartBreakCounts[summary.Team]
+		if count >= r.target {
+			return core.GameEndResult{
+				IsGameOver: true,
+				Winner:     summary.Team,
+				Reason:     core.GameEndReasonPartBreaks,
+				Params: map[string]interface{}{
+					"winner_team": teamDisplayNumber(summary.Team),
+					"count":       count,
+				},
+			}
+		}
+	}
+	return resolveByDefeat(summaries, checkAnnihilation)
+}
+
+// --- BestOfRoundsRule ---
+
+// BestOfRoundsRule は内側のルールを1ラウンドとし、先に規定ラウンド数を取ったチームが勝利となるルールです。
+// ラウンドの終了は IsRoundOver で知らせるだけで、勝利数の記録と次のラウンドの準備は呼び出し側が StartNextRoundSystem で行います。
+type BestOfRoundsRule struct {
+	inner           VictoryRule
+	roundsToWin     int
+	gameDataManager *data.GameDataManager
+}
+
+// NewBestOfRoundsRule は新しいBestOfRoundsRuleを生成します。
+func NewBestOfRoundsRule(inner VictoryRule, roundsToWin int, gameDataManager *data.GameDataManager) *BestOfRoundsRule {
+	return &BestOfRoundsRule{inner: inner, roundsToWin: roundsToWin, gameDataManager: gameDataManager}
+}
+
+func (r *BestOfRoundsRule) Check(world donburi.World) core.GameEndResult {
+	roundResult := r.inner.Check(world)
+	if !roundResult.IsGameOver {
+		return roundResult
+	}
+
+	victoryState := entity.GetVictoryStateComponent(world)
+	finishedRound := victoryState.CurrentRound
+	if roundResult.Winner != core.TeamNone {
+		// 判定はワールドを変更しないため、このラウンドの勝利を加えた数で試合の決着を判断します。
+		wins := victoryState.RoundWins[roundResult.Winner] + 1
+		if wins >= r.roundsToWin {
+			return core.GameEndResult{
+				IsGameOver: true,
+				Winner:     roundResult.Winner,
+				Reason:     core.GameEndReasonMatchWon,
+				Params: map[string]interface{}{
+					"winner_team": teamDisplayNumber(roundResult.Winner),
+					"wins":        wins,
+				},
+			}
+		}
+	}
+
+	params := map[string]interface{}{"round": finishedRound}
+	reason := core.GameEndReasonRoundDraw
+	if roundResult.Winner != core.TeamNone {
+		reason = core.GameEndReasonRoundWon
+		params["winner_team"] = teamDisplayNumber(roundResult.Winner)
+	}
+	return core.GameEndResult{IsRoundOver: true, Winner: roundResult.Winner, Reason: reason, Params: params}
+}
+
+// StartNextRoundSystem は、判定がラウンドの終了（IsRoundOver）を返したときに呼ばれ、
+// ラウンドの勝者の勝利数を記録してから、全機体を初期状態に戻して次のラウンドを開始します。
+func StartNextRoundSystem(world donburi.World, gameDataManager *data.GameDataManager, roundResult core.GameEndResult) {
+	victoryState := entity.GetVictoryStateComponent(world)
+	finishedRound := victoryState.CurrentRound
+	if roundResult.Winner != core.TeamNone {
+		victoryState.RoundWins[roundResult.Winner]++
+	}
+	entity.ResetBattleForNextRound(world, gameDataManager)
+	log.Printf("ラウンド%d終了。ラウンド%dを開始します。", finishedRound, victoryState.CurrentRound)
+}
+
+// --- Factory ---
+
+// NewVictoryRule は設定に従って勝利条件を生成します。未知のルール名の場合はリーダー撃破ルールになります。
+func NewVictoryRule(config *data.Config, gameDataManager *data.GameDataManager) VictoryRule {
+	victoryConfig := config.Victory
+	switch victoryConfig.Rule {
+	case core.VictoryRuleBestOfRounds:
+		return NewBestOfRoundsRule(newSingleVictoryRule(victoryConfig.RoundRule, config, gameDataManager), victoryConfig.RoundsToWin, gameDataManager)
+	default:
+		return newSingleVictoryRule(victoryConfig.Rule, config, gameDataManager)
+	}
+}
+
+// newSingleVictoryRule はラウンド制以外の勝利条件を生成します。
+func newSingleVictoryRule(ruleType core.VictoryRuleType, config *data.Config, gameDataManager *data.GameDataManager) VictoryRule {
+	switch ruleType {
+	case core.VictoryRuleLeaderKO, "":
+		return NewLeaderKORule(gameDataManager)
+	case core.VictoryRuleAnnihilation:
+		return NewAnnihilationRule(gameDataManager)
+	case core.VictoryRuleTimeLimit:
+		return NewTimeLimitRule(NewLeaderKORule(gameDataManager), config.Victory.TimeLimitSeconds, gameDataManager)
+	case core.VictoryRulePartBreaks:
+		return NewPartBreaksRule(config.Victory.PartBreaksToWin, gameDataManager)
+	default:
+		log.Printf("警告: 未知の勝利条件 '%s' が指定されました。リーダー撃破ルールを使用します。", ruleType)
+		return NewLeaderKORule(gameDataManager)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"math/rand"
 
 	"medarot-ebiten/core"
+	"medarot-ebiten/data"
 	"medarot-ebiten/ecs/component"
 
 	"github.com/yohamta/donburi"
@@ -43,4 +44,36 @@ func (h *VirusEffectHandler) ApplyEffect(result *component.ActionResult, world d
 		// ActionResult.AppliedEffectsにTargetRandomEffectDataを追加
 		result.AppliedEffects = append(result.AppliedEffects, &core.TargetRandomEffectData{DurationTurns: 1}) // 例として1ターン
 	}
-}
\ No newline at end of file
+}
+
+// ScanEffectHandler はスキャン効果（マーク）を付与します。
+// マークされた敵は回避度が下がり、次に命中した攻撃を防御できなくなります。
+type ScanEffectHandler struct {
+	config *data.Config
+}
+
+func (h *ScanEffectHandler) ApplyEffect(result *component.ActionResult, world donburi.World, damageCalculator *DamageCalculator, hitCalculator *HitCalculator, targetSelector *TargetSelector, partInfoProvider PartInfoProviderInterface, actingPartDef *core.PartDefinition, rand *rand.Rand) {
+	if !result.ActionDidHit || result.ActingEntry == nil {
+		return
+	}
+	// 支援など、ターゲットを結果に持たない行動でも、チャージ時に選んだ敵をスキャンします。
+	targetEntry := result.TargetEntry
+	if targetEntry == nil {
+		targetComp := component.TargetComponent.Get(result.ActingEntry)
+		if targetComp.TargetEntity == 0 {
+			return
+		}
+		targetEntry = world.Entry(targetComp.TargetEntity)
+	}
+	if targetEntry == nil || !targetEntry.Valid() || component.StateComponent.Get(targetEntry).CurrentState == core.StateBroken {
+		return
+	}
+	if component.SettingsComponent.Get(targetEntry).Team == component.SettingsComponent.Get(result.ActingEntry).Team {
+		return
+	}
+
+	ApplyScanMark(world, h.config, result.ActingEntry, targetEntry)
+	result.TargetEntry = targetEntry
+	result.DefenderName = component.SettingsComponent.Get(targetEntry).Name
+	result.IsScanApplied = true
+}
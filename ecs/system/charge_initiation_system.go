@@ -1,6 +1,8 @@
 package system
 
 import (
+	"math"
+
 	"medarot-ebiten/core"
 	"medarot-ebiten/data"
 	"medarot-ebiten/ecs/component"
@@ -9,21 +11,37 @@ import (
 )
 
 // ChargeInitiationSystem はチャージ状態の開始ロジックをカプセル化します。
+// プレイヤーによるチャージ中の行動のキャンセル・ターゲット変更もここで扱います。
 type ChargeInitiationSystem struct {
 	world            donburi.World
+	config           *data.Config
 	partInfoProvider PartInfoProviderInterface
 	gameDataManager  *data.GameDataManager
 }
 
 // NewChargeInitiationSystem は新しいChargeInitiationSystemのインスタンスを生成します。
-func NewChargeInitiationSystem(world donburi.World, partInfoProvider PartInfoProviderInterface) *ChargeInitiationSystem {
+func NewChargeInitiationSystem(world donburi.World, config *data.Config, partInfoProvider PartInfoProviderInterface) *ChargeInitiationSystem {
 	return &ChargeInitiationSystem{
 		world:            world,
+		config:           config,
 		partInfoProvider: partInfoProvider,
 		gameDataManager:  partInfoProvider.GetGameDataManager(),
 	}
 }
 
+// TargetingPolicyFor はパーツのカテゴリに応じたターゲット決定方針を返します。
+// 射撃・介入はチャージ開始時に決めたターゲットを、格闘は実行時に最も近い敵を狙います。
+func TargetingPolicyFor(category core.PartCategory) core.TargetingPolicyType {
+	switch category {
+	case core.CategoryRanged, core.CategoryIntervention:
+		return core.PolicyPreselected
+	case core.CategoryMelee:
+		return core.PolicyClosestAtExecution
+	default:
+		return core.PolicyPreselected // デフォルト
+	}
+}
+
 // StartCharge はチャージ状態を開始するための主要なロジックを実行します。
 func (s *ChargeInitiationSystem) StartCharge(
 	entry *donburi.Entry,
@@ -60,14 +78,7 @@ func (s *ChargeInitiationSystem) StartCharge(
 	target.TargetPartSlot = targetPartSlot
 
 	// カテゴリに基づいてターゲット決定方針を設定
-	switch actingPartDef.Category {
-	case core.CategoryRanged, core.CategoryIntervention:
-		target.Policy = core.PolicyPreselected
-	case core.CategoryMelee:
-		target.Policy = core.PolicyClosestAtExecution
-	default:
-		target.Policy = core.PolicyPreselected // デフォルト
-	}
+	target.Policy = TargetingPolicyFor(actingPartDef.Category)
 
 	// 1. 計算式の取得
 	formula, ok := s.gameDataManager.Formulas[actingPartDef.Trait]
@@ -105,6 +116,8 @@ func (s *ChargeInitiationSystem) StartCharge(
 	baseSeconds := float64(actingPartDef.Charge)
 	// 新しい共通関数を呼び出す
 	totalTicks := s.partInfoProvider.CalculateGaugeDuration(baseSeconds, entry)
+	// チームバフ・デバフによるチャージ速度の補正
+	totalTicks = math.Max(totalTicks/s.partInfoProvider.GetTeamBuffMultiplier(entry, core.BuffTypeChargeSpeed), 1)
 
 	gauge := component.GaugeComponent.Get(entry)
 	gauge.TotalDuration = totalTicks
@@ -113,3 +126,52 @@ func (s *ChargeInitiationSystem) StartCharge(
 	state.CurrentState = core.StateCharging
 	return true
 }
+
+// CancelCharge はチャージ中の行動を取り消します。
+// ペナルティとして、それまでのチャージ時間に ChargeControl.CancelCooldownRatio を掛けた時間のクールダウンに入ります。
+// クールダウン時間が 0 の場合はそのまま待機状態に戻ります。
+func (s *ChargeInitiationSystem) CancelCharge(entry *donburi.Entry) bool {
+	state := component.StateComponent.Get(entry)
+	if state.CurrentState != core.StateCharging {
+		return false
+	}
+
+	gauge := component.GaugeComponent.Get(entry)
+	cooldownTicks := gauge.ProgressCounter * s.config.ChargeControl.CancelCooldownRatio
+	component.ActionIntentComponent.Get(entry).PendingEffects = nil
+
+	if cooldownTicks <= 0 {
+		CancelActionSystem(entry)
+		return true
+	}
+	gauge.TotalDuration = math.Max(cooldownTicks, 1)
+	gauge.ProgressCounter = 0
+	gauge.CurrentGauge = 0
+	state.CurrentState = core.StateCooldown
+	return true
+}
+
+// RetargetCharge はチャージ中の射撃・介入行動のターゲットを変更します。
+// ペナルティとして、チャージの進行度のうち ChargeControl.RetargetProgressLoss の割合を失います。
+func (s *ChargeInitiationSystem) RetargetCharge(entry *donburi.Entry, targetEntry *donburi.Entry, targetPartSlot core.PartSlotKey) bool {
+	if component.StateComponent.Get(entry).CurrentState != core.StateCharging {
+		return false
+	}
+	target := component.TargetComponent.Get(entry)
+	if target.Policy != core.PolicyPreselected {
+		return false // 実行時にターゲットを決める行動は変更できない
+	}
+	if targetEntry == nil || !targetEntry.Valid() || component.StateComponent.Get(targetEntry).CurrentState == core.StateBroken {
+		return false
+	}
+	target.TargetEntity = targetEntry.Entity()
+	target.TargetPartSlot = targetPartSlot
+
+	gauge := component.GaugeComponent.Get(entry)
+	loss := math.Min(math.Max(s.config.ChargeControl.RetargetProgressLoss, 0), 1)
+	gauge.ProgressCounter *= 1.0 - loss
+	if gauge.TotalDuration > 0 {
+		gauge.CurrentGauge = (gauge.ProgressCounter / gauge.TotalDuration) * 100
+	}
+	return true
+}
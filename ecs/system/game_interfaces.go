@@ -21,7 +21,12 @@ type UIUpdater interface {
 	EnqueueMessageQueue(messages []string, callback func())
 	// DisplayMessagesForResult は、ActionResultからUIに表示するメッセージを生成し、表示キューに入れます。
 	DisplayMessagesForResult(result *component.ActionResult, callback func())
+	// DisplayGameEndResult は、GameEndResultの理由コードからメッセージを生成し、表示キューに入れます。
+	DisplayGameEndResult(result core.GameEndResult, callback func())
 	IsMessageFinished() bool
+	// SetAutoBattle は、オートバトルの状態と戦闘の速度の倍率をUIに伝えます。
+	// オートバトル中はメッセージが自動で送られ、画面に状態が表示されます。
+	SetAutoBattle(enabled bool, speed int)
 	SetCurrentTarget(entityID donburi.Entity)
 	ClearCurrentTarget()
 	SetAnimation(anim *component.ActionAnimationData)
@@ -35,6 +40,7 @@ type ViewModelBuilder interface {
 	BuildInfoPanelViewModel(entry *donburi.Entry) (core.InfoPanelViewModel, error)
 	BuildBattlefieldViewModel(world donburi.World) (core.BattlefieldViewModel, error)
 	BuildActionModalViewModel(actingEntry *donburi.Entry, actionTargetMap map[core.PartSlotKey]core.ActionTarget) (core.ActionModalViewModel, error)
+	BuildChargeMenuViewModel(world donburi.World, actingEntry *donburi.Entry, retargetTargets []core.ActionTarget) (core.ChargeMenuViewModel, error)
 	GetAvailableAttackParts(entry *donburi.Entry) []core.AvailablePart
 }
 
@@ -51,6 +57,37 @@ type TargetingStrategy interface {
 	) (*donburi.Entry, core.PartSlotKey)
 }
 
+// AIActionPlanner はAIの行動パーツとターゲットをまとめて決めるアルゴリズムをカプセル化するインターフェースです。
+// 性格に設定されている場合、パーツ選択戦略とターゲット選択戦略の代わりに使われます。
+// 計画を立てられなかった場合は false を返し、その場合は通常の戦略で行動を決めます。
+type AIActionPlanner interface {
+	PlanAction(
+		world donburi.World,
+		actingEntry *donburi.Entry,
+		availableParts []core.AvailablePart,
+		targetSelector *TargetSelector,
+		partInfoProvider PartInfoProviderInterface,
+		hitCalculator *HitCalculator,
+		damageCalculator *DamageCalculator,
+		gameConfig *data.Config,
+	) (AIActionPlan, bool)
+}
+
+// AIActionPlan は AIActionPlanner が決めた行動です。格闘の場合、ターゲットは実行時に決まるため空になります。
+type AIActionPlan struct {
+	Slot           core.PartSlotKey
+	PartDef        *core.PartDefinition
+	TargetEntry    *donburi.Entry
+	TargetPartSlot core.PartSlotKey
+}
+
+// VictoryRule は勝敗判定のルールをカプセル化するインターフェースです。
+// 試合・ラウンドの決着がついていない場合は IsGameOver, IsRoundOver ともに false の結果を返します。
+// Check は判定だけを行い、ワールドを変更してはいけません（先読みAIのシミュレーションなどからも呼ばれるため）。
+type VictoryRule interface {
+	Check(world donburi.World) core.GameEndResult
+}
+
 // BattleLogger インターフェースを更新し、デバッグログ出力に特化させました。
 // UIメッセージに関連するメソッドは削除されました。
 type BattleLogger interface {
@@ -60,6 +97,12 @@ type BattleLogger interface {
 	LogPartBroken(medarotName, partName, partID string)
 }
 
+// DamageModifier はダメージ計算の修正パイプラインの1段です。
+// 計算の途中経過（DamageContext）を、設定（params）に従って書き換えます。
+type DamageModifier interface {
+	Apply(ctx *DamageContext, params data.DamageModifierConfig)
+}
+
 // TraitActionHandler はカテゴリ固有のアクション処理全体をカプセル化します。
 // ActionResultを返し、副作用をなくします。
 type TraitActionHandler interface {
@@ -106,8 +149,21 @@ type PartInfoProviderInterface interface {
 	GetEvasionRate(entry *donburi.Entry) float64
 	GetDefenseRate(entry *donburi.Entry) float64
 
-	// チームの命中率バフ乗数を取得するメソッド
-	GetTeamAccuracyBuffMultiplier(entry *donburi.Entry) float64
+	// パーツセットのボーナスを取得するメソッド
+	GetSetBonus(entry *donburi.Entry) *core.SetBonusDefinition
+	GetSetBonusValue(entry *donburi.Entry, param core.PartParameter) float64
+
+	// 総重量と脚部積載量から過積載の割合を取得するメソッド
+	GetOverloadRatio(entry *donburi.Entry) float64
+
+	// ステージによる攻撃パーツの威力倍率を取得するメソッド
+	GetStagePowerMultiplier(actingPartDef *core.PartDefinition) float64
+
+	// 攻撃パーツの攻撃パターン（連続攻撃・拡散・範囲）を取得するメソッド
+	GetAttackPattern(actingPartDef *core.PartDefinition) data.AttackPatternConfig
+
+	// チームのバフ・デバフ乗数を種類ごとに取得するメソッド
+	GetTeamBuffMultiplier(entry *donburi.Entry, buffType core.BuffType) float64
 
 	// バフを削除するメソッド
 	RemoveBuffsFromSource(entry *donburi.Entry, partInst *core.PartInstanceData)
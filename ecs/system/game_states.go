@@ -37,6 +37,7 @@ type BattleContext struct {
 	TargetSelector         *TargetSelector
 	DamageCalculator       *DamageCalculator
 	HitCalculator          *HitCalculator
+	VictoryRule            VictoryRule
 }
 
 // BattleState は戦闘シーンの各状態が満たすべきインターフェースです。
@@ -52,8 +53,9 @@ type GaugeProgressState struct{}
 func (s *GaugeProgressState) Update(ctx *BattleContext) ([]event.GameEvent, error) {
 	var gameEvents []event.GameEvent
 
-	// ゲージ進行
-	UpdateGaugeSystem(ctx.World)
+	// ゲージ進行と、それに伴うバトルフィールド上の移動
+	UpdateGaugeSystem(ctx.World, ctx.Rand)
+	UpdatePositionSystem(ctx.World, ctx.Config)
 
 	// プレイヤーの行動選択が必要かチェック
 	playerInputEvents := UpdatePlayerInputSystem(ctx.World)
@@ -71,6 +73,9 @@ func (s *GaugeProgressState) Update(ctx *BattleContext) ([]event.GameEvent, erro
 		ctx.PartInfoProvider,
 		ctx.ChargeInitiationSystem,
 		ctx.TargetSelector,
+		ctx.HitCalculator,
+		ctx.DamageCalculator,
+		ctx.Config,
 		ctx.Rand,
 	)
 
@@ -85,10 +90,15 @@ func (s *GaugeProgressState) Update(ctx *BattleContext) ([]event.GameEvent, erro
 	ctx.StatusEffectSystem.Update()
 
 	// ゲーム終了判定
-	gameEndResult := CheckGameEndSystem(ctx.World)
+	gameEndResult := CheckGameEndSystem(ctx.World, ctx.VictoryRule)
 	if gameEndResult.IsGameOver {
-		gameEvents = append(gameEvents, event.MessageDisplayRequestGameEvent{Messages: []string{gameEndResult.Message}, Callback: nil})
+		ctx.BattleUIManager.DisplayGameEndResult(gameEndResult, nil)
 		gameEvents = append(gameEvents, event.GameOverGameEvent{Winner: gameEndResult.Winner})
+	} else if gameEndResult.IsRoundOver {
+		// 次のラウンドの準備をし、ラウンド終了メッセージを表示した後、次のラウンドのゲージ進行へ戻る
+		StartNextRoundSystem(ctx.World, ctx.GameDataManager, gameEndResult)
+		ctx.BattleUIManager.DisplayGameEndResult(gameEndResult, nil)
+		gameEvents = append(gameEvents, event.StateChangeRequestedGameEvent{NextState: core.StateMessage})
 	}
 
 	return gameEvents, nil
@@ -113,6 +123,16 @@ func (s *PlayerActionSelectState) Update(ctx *BattleContext) ([]event.GameEvent,
 
 	playerActionQueue := entity.GetPlayerActionQueueComponent(ctx.World)
 
+	// 行動選択の途中でオートバトルに切り替わった機体は、モーダルを閉じてAIの行動選択に任せる
+	if len(playerActionQueue.Queue) > 0 && playerActionQueue.Queue[0].HasComponent(component.AIComponent) {
+		if s.processedEntry == playerActionQueue.Queue[0] {
+			gameEvents = append(gameEvents, event.HideActionModalGameEvent{}, event.ClearCurrentTargetGameEvent{})
+			s.processedEntry = nil
+		}
+		playerActionQueue.Queue = playerActionQueue.Queue[1:]
+		return gameEvents, nil
+	}
+
 	// 行動選択待ちのプレイヤーがいるかチェック
 	if len(playerActionQueue.Queue) > 0 {
 		actingEntry := playerActionQueue.Queue[0]
@@ -121,7 +141,7 @@ func (s *PlayerActionSelectState) Update(ctx *BattleContext) ([]event.GameEvent,
 		if s.processedEntry != actingEntry {
 			if actingEntry.Valid() && component.StateComponent.Get(actingEntry).CurrentState == core.StateIdle {
 				actionTargetMap := make(map[core.PartSlotKey]core.ActionTarget)
-				availableParts := ctx.PartInfoProvider.GetAvailableAttackParts(actingEntry)
+				availableParts := ctx.TargetSelector.FilterReachableParts(actingEntry, ctx.PartInfoProvider.GetAvailableAttackParts(actingEntry))
 
 				for _, available := range availableParts {
 					partDef := available.PartDef
@@ -242,9 +262,18 @@ func (s *PostActionState) Update(ctx *BattleContext) ([]event.GameEvent, error)
 	// クールダウン開始
 	actingEntry := result.ActingEntry
 	if actingEntry != nil && actingEntry.Valid() && component.StateComponent.Get(actingEntry).CurrentState != core.StateBroken {
-		StartCooldownSystem(actingEntry, ctx.World, ctx.PartInfoProvider)
+		if result.IsCancelled {
+			CancelActionSystem(actingEntry)
+		} else {
+			StartCooldownSystem(actingEntry, ctx.World, ctx.Config, ctx.PartInfoProvider)
+		}
 	}
 
+	// 期限の切れたチームバフ・デバフを削除
+	UpdateTeamBuffExpirySystem(ctx.World)
+	// 期限の切れたスキャンのマークを削除
+	UpdateScanMarkExpirySystem(ctx.World)
+
 	// UIマネージャーにメッセージ表示を依頼
 	ctx.BattleUIManager.DisplayMessagesForResult(result, func() {
 		// メッセージ表示後のコールバックでAIの行動履歴を更新
@@ -278,6 +307,73 @@ func (s *MessageState) Update(ctx *BattleContext) ([]event.GameEvent, error) {
 
 func (s *MessageState) Draw(screen *ebiten.Image) {}
 
+// --- ChargeMenuState ---
+
+// ChargeMenuState は、プレイヤーがチャージ中の機体の行動をキャンセル・ターゲット変更するメニューを表示している状態です。
+// この状態の間、ゲージの進行は停止します。
+type ChargeMenuState struct {
+	actingEntityID donburi.Entity
+	shown          bool
+}
+
+// Open はメニューの対象となる機体を設定します。
+func (s *ChargeMenuState) Open(actingEntityID donburi.Entity) {
+	s.actingEntityID = actingEntityID
+	s.shown = false
+}
+
+func (s *ChargeMenuState) Update(ctx *BattleContext) ([]event.GameEvent, error) {
+	if s.shown {
+		// UIからのキャンセル・ターゲット変更・クローズのイベントを待つ
+		return nil, nil
+	}
+	s.shown = true
+
+	actingEntry := ctx.World.Entry(s.actingEntityID)
+	if actingEntry == nil || !actingEntry.Valid() || component.StateComponent.Get(actingEntry).CurrentState != core.StateCharging {
+		return []event.GameEvent{event.StateChangeRequestedGameEvent{NextState: core.StateGaugeProgress}}, nil
+	}
+
+	// ターゲットを変更できるのは、チャージ開始時にターゲットを決めた射撃行動のみ
+	var retargetTargets []core.ActionTarget
+	intent := component.ActionIntentComponent.Get(actingEntry)
+	if partInst, ok := component.PartsComponent.Get(actingEntry).Map[intent.SelectedPartKey]; ok && partInst != nil {
+		partDef, found := ctx.GameDataManager.GetPartDefinition(partInst.DefinitionID)
+		if found && partDef.Category == core.CategoryRanged && component.TargetComponent.Get(actingEntry).Policy == core.PolicyPreselected {
+			// メニューを開くだけで戦闘の乱数を消費しないよう、狙うパーツは乱数を使わずに決めます。
+			// 今狙っているパーツと同じ部位が残っていればその部位を、なければ固定の順序で最初に残っている部位を狙います。
+			currentSlot := component.TargetComponent.Get(actingEntry).TargetPartSlot
+			for _, enemy := range ctx.TargetSelector.GetTargetableEnemies(actingEntry) {
+				if slot := retargetPartSlot(enemy, currentSlot); slot != "" {
+					retargetTargets = append(retargetTargets, core.ActionTarget{TargetEntityID: enemy.Entity(), Slot: slot})
+				}
+			}
+		}
+	}
+
+	return []event.GameEvent{event.ShowChargeMenuGameEvent{
+		ActingEntry:     actingEntry,
+		RetargetTargets: retargetTargets,
+	}}, nil
+}
+
+func (s *ChargeMenuState) Draw(screen *ebiten.Image) {}
+
+// retargetPartSlot は、ターゲット変更で敵の狙う部位を返します。preferred の部位が壊れていなければそれを、
+// そうでなければ頭部・右腕・左腕・脚部の順で最初に壊れていない部位を返します。狙える部位がない場合は空を返します。
+func retargetPartSlot(enemy *donburi.Entry, preferred core.PartSlotKey) core.PartSlotKey {
+	partsMap := component.PartsComponent.Get(enemy).Map
+	if partInst, ok := partsMap[preferred]; ok && partInst != nil && !partInst.IsBroken {
+		return preferred
+	}
+	for _, slot := range []core.PartSlotKey{core.PartSlotHead, core.PartSlotRightArm, core.PartSlotLeftArm, core.PartSlotLegs} {
+		if partInst, ok := partsMap[slot]; ok && partInst != nil && !partInst.IsBroken {
+			return slot
+		}
+	}
+	return ""
+}
+
 // --- GameOverState ---
 
 type GameOverState struct{}
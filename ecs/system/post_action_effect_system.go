@@ -6,6 +6,7 @@ import (
 	"medarot-ebiten/core"
 	"medarot-ebiten/data"
 	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
 
 	"github.com/yohamta/donburi"
 )
@@ -60,40 +61,13 @@ func (s *PostActionEffectSystem) Process(result *component.ActionResult) {
 		}
 	}
 
-	// 2. ダメージ適用とパーツ破壊の状態遷移
-	if result.TargetPartInstance != nil && result.DamageToApply > 0 {
-		// ダメージを適用
-		result.TargetPartInstance.CurrentArmor -= result.DamageToApply
-		if result.TargetPartInstance.CurrentArmor <= 0 {
-			result.TargetPartInstance.CurrentArmor = 0
-			result.TargetPartInstance.IsBroken = true
-		}
-		result.IsTargetPartBroken = result.TargetPartInstance.IsBroken // 結果に反映
-
-		// パーツ破壊時のログメッセージ
-		if result.TargetPartInstance.IsBroken {
-			settings := component.SettingsComponent.Get(result.TargetEntry)
-			partDef, defFound := s.gameDataManager.GetPartDefinition(result.TargetPartInstance.DefinitionID)
-			partNameForLog := "(不明パーツ)"
-			if defFound {
-				partNameForLog = partDef.PartName
-			}
-			log.Print(s.gameDataManager.Messages.FormatMessage("log_part_broken_notification", map[string]interface{}{
-				"ordered_args": []interface{}{settings.Name, partNameForLog, result.TargetPartInstance.DefinitionID},
-			}))
-
-			// パーツ破壊時にバフを解除する
-			s.partInfoProvider.RemoveBuffsFromSource(result.TargetEntry, result.TargetPartInstance)
-		}
-	}
-
-	// 3. 頭部パーツ破壊による機能停止
-	if result.TargetEntry != nil && result.IsTargetPartBroken && result.ActualHitPartSlot == core.PartSlotHead {
-		state := component.StateComponent.Get(result.TargetEntry)
-		state.CurrentState = core.StateBroken
+	// 2. ダメージ適用とパーツ破壊の状態遷移（連続攻撃などではヒットごとに適用します）
+	for i := range result.Hits {
+		s.applyHitDamage(result.ActingEntry, &result.Hits[i])
 	}
+	applyPrimaryHit(result) // パーツ破壊の結果を代表のフィールドにも反映
 
-	// 4. 行動後のクリーンアップ
+	// 3. 行動後のクリーンアップ
 	if result.ActingEntry != nil && result.ActingEntry.HasComponent(component.ActiveEffectsComponent) {
 		activeEffects := component.ActiveEffectsComponent.Get(result.ActingEntry)
 		effectsToRemove := []interface{}{} // interface{}のスライスに変更
@@ -107,3 +81,51 @@ func (s *PostActionEffectSystem) Process(result *component.ActionResult) {
 		}
 	}
 }
+
+// applyHitDamage は1ヒット分のダメージ（貫通したダメージを含む）を適用します。
+func (s *PostActionEffectSystem) applyHitDamage(actingEntry *donburi.Entry, hit *component.HitRecord) {
+	hit.IsTargetPartBroken = s.damagePart(actingEntry, hit.TargetEntry, hit.TargetPartInstance, hit.ActualHitPartSlot, hit.DamageToApply)
+	hit.IsPiercePartBroken = s.damagePart(actingEntry, hit.TargetEntry, hit.PiercePartInstance, hit.PiercePartSlot, hit.PierceDamage)
+}
+
+// damagePart はパーツにダメージを適用し、パーツの破壊と頭部破壊による機能停止を処理します。
+// このダメージでパーツが破壊された場合は true を返します。
+func (s *PostActionEffectSystem) damagePart(actingEntry, targetEntry *donburi.Entry, partInst *core.PartInstanceData, slot core.PartSlotKey, damage int) bool {
+	if partInst == nil || damage <= 0 || partInst.IsBroken {
+		return false
+	}
+
+	// ダメージを適用
+	partInst.CurrentArmor -= damage
+	if partInst.CurrentArmor > 0 {
+		return false
+	}
+	partInst.CurrentArmor = 0
+	partInst.IsBroken = true
+
+	// パーツ破壊時のログメッセージ
+	settings := component.SettingsComponent.Get(targetEntry)
+	partDef, defFound := s.gameDataManager.GetPartDefinition(partInst.DefinitionID)
+	partNameForLog := "(不明パーツ)"
+	if defFound {
+		partNameForLog = partDef.PartName
+	}
+	log.Print(s.gameDataManager.Messages.FormatMessage("log_part_broken_notification", map[string]interface{}{
+		"ordered_args": []interface{}{settings.Name, partNameForLog, partInst.DefinitionID},
+	}))
+
+	// パーツ破壊時にバフを解除する
+	s.partInfoProvider.RemoveBuffsFromSource(targetEntry, partInst)
+
+	// 勝利条件（パーツ破壊数）のために攻撃側チームの破壊数を記録する
+	if actingEntry != nil {
+		actingTeam := component.SettingsComponent.Get(actingEntry).Team
+		entity.GetVictoryStateComponent(s.world).PartBreakCounts[actingTeam]++
+	}
+
+	// 頭部パーツ破壊による機能停止
+	if slot == core.PartSlotHead {
+		component.StateComponent.Get(targetEntry).CurrentState = core.StateBroken
+	}
+	return true
+}
@@ -74,23 +74,7 @@ func (s *StatusEffectSystem) Update() {
 			case *core.DamageOverTimeEffectData:
 				// 継続ダメージの処理
 				if DurationDamageOverTimeEffect(effect) > 0 { // Duration()が0より大きい場合のみダメージを与える
-					// ダメージ計算ロジックを呼び出す
-					// ApplyDamageはBattleDamageCalculatorのメソッドではないため、直接呼び出す
-					// ここでは簡略化のため、直接ダメージを適用するロジックを記述
-					// 実際のゲームでは、BattleDamageCalculatorの適切なメソッドを呼び出すか、
-					// ダメージ適用ロジックをStatusEffectSystemに持たせるべきです。
-					targetParts := component.PartsComponent.Get(entry)
-					if targetParts != nil && len(targetParts.Map) > 0 {
-						// 適当なパーツにダメージを適用する例
-						for _, partInst := range targetParts.Map {
-							partInst.CurrentArmor -= effect.DamagePerTurn
-							if partInst.CurrentArmor < 0 {
-								partInst.CurrentArmor = 0
-							}
-							log.Printf("%s のパーツに継続ダメージ %d を与えた。残りアーマー: %d", component.SettingsComponent.Get(entry).Name, effect.DamagePerTurn, partInst.CurrentArmor)
-							break // 最初のパーツにダメージを与えたら終了
-						}
-					}
+					s.applyDamageOverTime(entry, effect)
 					log.Printf("%s は継続ダメージ %d を受けた。", component.SettingsComponent.Get(entry).Name, effect.DamagePerTurn)
 				}
 			case *core.ChargeStopEffectData:
@@ -133,6 +117,48 @@ func (s *StatusEffectSystem) Update() {
 	})
 }
 
+// applyDamageOverTime は、破壊されていないパーツから戦闘の乱数で1つを選び、継続ダメージを与えます。
+// 装甲が0になったパーツは破壊され、頭部が破壊された場合は機能停止します。
+func (s *StatusEffectSystem) applyDamageOverTime(entry *donburi.Entry, effect *core.DamageOverTimeEffectData) {
+	targetParts := component.PartsComponent.Get(entry)
+	if targetParts == nil {
+		return
+	}
+	var slots []core.PartSlotKey
+	for _, slot := range []core.PartSlotKey{core.PartSlotHead, core.PartSlotRightArm, core.PartSlotLeftArm, core.PartSlotLegs} {
+		if partInst := targetParts.Map[slot]; partInst != nil && !partInst.IsBroken {
+			slots = append(slots, slot)
+		}
+	}
+	if len(slots) == 0 {
+		return
+	}
+
+	dc := s.battleDamageCalculator
+	slot := slots[dc.rand.Intn(len(slots))]
+	partInst := targetParts.Map[slot]
+	partInst.CurrentArmor -= effect.DamagePerTurn
+	settings := component.SettingsComponent.Get(entry)
+	log.Printf("%s のパーツに継続ダメージ %d を与えた。残りアーマー: %d", settings.Name, effect.DamagePerTurn, max(partInst.CurrentArmor, 0))
+	if partInst.CurrentArmor > 0 {
+		return
+	}
+
+	partInst.CurrentArmor = 0
+	partInst.IsBroken = true
+	partNameForLog := "(不明パーツ)"
+	if partDef, defFound := dc.gameDataManager.GetPartDefinition(partInst.DefinitionID); defFound {
+		partNameForLog = partDef.PartName
+	}
+	log.Print(dc.gameDataManager.Messages.FormatMessage("log_part_broken_notification", map[string]interface{}{
+		"ordered_args": []interface{}{settings.Name, partNameForLog, partInst.DefinitionID},
+	}))
+	dc.partInfoProvider.RemoveBuffsFromSource(entry, partInst)
+	if slot == core.PartSlotHead {
+		component.StateComponent.Get(entry).CurrentState = core.StateBroken
+	}
+}
+
 // removeEffect はスライスから指定された効果を削除するヘルパー関数です。
 func removeEffect(slice []*core.ActiveStatusEffectData, element *core.ActiveStatusEffectData) []*core.ActiveStatusEffectData {
 	for i, v := range slice {
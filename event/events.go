@@ -96,13 +96,43 @@ type PlayerActionIntentEvent struct {
 
 func (e PlayerActionIntentEvent) isGameEvent() {}
 
-// ActionCanceledGameEvent は、プレイヤーが行動選択をキャンセルしたことを示すイベントです。
+// ActionCanceledGameEvent は、プレイヤーがチャージ中の行動をキャンセルしたことを示すイベントです。
 type ActionCanceledGameEvent struct {
 	ActingEntityID donburi.Entity
 }
 
 func (e ActionCanceledGameEvent) isGameEvent() {}
 
+// ActionRetargetedGameEvent は、プレイヤーがチャージ中の行動のターゲットを変更したことを示すイベントです。
+type ActionRetargetedGameEvent struct {
+	ActingEntityID donburi.Entity
+	TargetEntityID donburi.Entity
+	TargetPartSlot core.PartSlotKey
+}
+
+func (e ActionRetargetedGameEvent) isGameEvent() {}
+
+// ChargeMenuRequestedGameEvent は、プレイヤーがチャージ中の機体の情報パネルをクリックしたことを示すイベントです。
+type ChargeMenuRequestedGameEvent struct {
+	ActingEntityID donburi.Entity
+}
+
+func (e ChargeMenuRequestedGameEvent) isGameEvent() {}
+
+// ShowChargeMenuGameEvent は、チャージ中の行動のメニューを表示する必要があることを示すイベントです。
+// ShowActionModalGameEvent と同様に、ViewModelの構築に必要な情報を渡します。
+type ShowChargeMenuGameEvent struct {
+	ActingEntry     *donburi.Entry
+	RetargetTargets []core.ActionTarget // ターゲット変更の候補。変更できない行動の場合は空
+}
+
+func (e ShowChargeMenuGameEvent) isGameEvent() {}
+
+// ChargeMenuClosedGameEvent は、チャージ中の行動のメニューが何もせずに閉じられたことを示すイベントです。
+type ChargeMenuClosedGameEvent struct{}
+
+func (e ChargeMenuClosedGameEvent) isGameEvent() {}
+
 // PlayerActionProcessedGameEvent は、プレイヤーの行動が処理されたことを示すイベントです。
 type PlayerActionProcessedGameEvent struct {
 	ActingEntityID donburi.Entity
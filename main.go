@@ -1,9 +1,13 @@
 package main
 
 import (
+	"flag"
 	"log"
+	"net"
+	"os"
 
 	"medarot-ebiten/data"
+	"medarot-ebiten/ecs/system"
 	"medarot-ebiten/scene"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -11,6 +15,14 @@ import (
 
 func main() {
 	// ... (ログ出力部分は変更なし) ...
+	tournament := flag.Bool("tournament", false, "AIの性格のトーナメントをウィンドウなしで実行し、結果をCSVに出力して終了します")
+	tournamentSeed := flag.Int64("seed", 0, "トーナメント・エージェント環境の乱数のシード（0の場合は game_settings.json の値）")
+	tournamentGames := flag.Int("games", 0, "トーナメントの1組あたりの試合数（0の場合は game_settings.json の値）")
+	tournamentOut := flag.String("out", "", "トーナメントの結果の出力先ディレクトリ（空の場合は game_settings.json の値）")
+	agent := flag.Bool("agent", false, "外部のボットが行動を選ぶエージェント環境をウィンドウなしで実行します（既定は標準入出力でJSONをやり取り）")
+	agentAddr := flag.String("agent-addr", "", "エージェント環境でTCP接続を待ち受けるアドレス（例: 127.0.0.1:7777。空の場合は game_settings.json の値）")
+	agentEpisodes := flag.Int("episodes", 0, "エージェント環境のエピソード数（0の場合は game_settings.json の値）")
+	flag.Parse()
 
 	// 1. すべての初期データを一括で読み込む
 	initialData := data.LoadInitialGameData()
@@ -18,6 +30,15 @@ func main() {
 		log.Fatal("ゲームデータの初期化に失敗しました。")
 	}
 
+	// 性格の定義から各メダルのAIの戦略を組み立てます。定義の誤りはここで検出します。
+	if err := system.InitPersonalityRegistry(initialData.GameDataManager); err != nil {
+		log.Fatalf("性格の定義が不正です: %v", err)
+	}
+	// ビヘイビアツリーは行動から性格を参照するため、性格の後に組み立てます。
+	if err := system.InitBehaviorTreeRegistry(initialData.GameDataManager); err != nil {
+		log.Fatalf("ビヘイビアツリーの定義が不正です: %v", err)
+	}
+
 	// 2. 共有リソースを作成
 	// 【変更点】`initialData`からローダーを取り出し、`NewSharedResources`に渡します。
 	sharedResources := data.NewSharedResources(
@@ -30,6 +51,15 @@ func main() {
 		initialData.Loader, // 追加
 	)
 
+	if *tournament {
+		runTournament(sharedResources, *tournamentSeed, *tournamentGames, *tournamentOut)
+		return
+	}
+	if *agent {
+		runAgentEnvironment(sharedResources, *tournamentSeed, *agentEpisodes, *agentAddr)
+		return
+	}
+
 	// 3. シーンマネージャを作成
 	manager := scene.NewSceneManager(sharedResources)
 
@@ -42,3 +72,67 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// runTournament はAIトーナメントを実行し、結果をCSVに保存します。引数が0や空の場合は設定の値を使います。
+func runTournament(res *data.SharedResources, seed int64, games int, outputDir string) {
+	if seed == 0 {
+		seed = res.Config.Tournament.Seed
+	}
+	if games > 0 {
+		res.Config.Tournament.GamesPerPairing = games
+	}
+	if outputDir == "" {
+		outputDir = res.Config.Tournament.OutputDir
+	}
+
+	result, err := system.RunAITournament(res, seed)
+	if err != nil {
+		log.Fatalf("トーナメントを実行できません: %v", err)
+	}
+	for i, rating := range result.Ratings {
+		log.Printf("%2d. %s %.1f (%d勝 %d分 %d敗)", i+1, rating.Entrant, rating.Rating, rating.Wins, rating.Draws, rating.Losses)
+	}
+	if err := data.SaveTournamentResults(outputDir, result); err != nil {
+		log.Fatalf("トーナメントの結果を保存できません: %v", err)
+	}
+	log.Printf("トーナメントの結果を %s に保存しました（シード: %d）。", outputDir, seed)
+}
+
+// runAgentEnvironment はエージェント環境を実行します。引数が0や空の場合は設定の値を使います。
+// 待ち受けるアドレスがなければ標準入出力で、あればそのアドレスで最初に受け付けたTCP接続でボットと通信します。
+// 標準出力はボットとの通信に使うため、ログは標準エラー出力に書き出します。
+func runAgentEnvironment(res *data.SharedResources, seed int64, episodes int, address string) {
+	if seed == 0 {
+		seed = res.Config.Agent.Seed
+	}
+	if episodes > 0 {
+		res.Config.Agent.Episodes = episodes
+	}
+	if address == "" {
+		address = res.Config.Agent.Address
+	}
+	log.SetOutput(os.Stderr)
+
+	if address == "" {
+		if err := system.RunAgentEnvironment(res, seed, os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("エージェント環境を実行できません: %v", err)
+		}
+		return
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		log.Fatalf("エージェント環境のアドレス %s で待ち受けられません: %v", address, err)
+	}
+	defer listener.Close()
+	log.Printf("エージェント環境: %s でボットの接続を待っています（シード: %d）。", listener.Addr(), seed)
+	conn, err := listener.Accept()
+	if err != nil {
+		log.Fatalf("ボットの接続を受け付けられません: %v", err)
+	}
+	defer conn.Close()
+	if err := system.RunAgentEnvironment(res, seed, conn, conn); err != nil {
+		log.Fatalf("エージェント環境を実行できません: %v", err)
+	}
+	log.Printf("エージェント環境: すべてのエピソードが終了しました。")
+}
@@ -10,6 +10,7 @@ import (
 	"medarot-ebiten/core"
 	"medarot-ebiten/data"
 	"medarot-ebiten/ecs/component"
+	"medarot-ebiten/ecs/entity"
 	"medarot-ebiten/ecs/system"
 
 	"github.com/ebitenui/ebitenui"
@@ -61,6 +62,8 @@ type BalanceTestScene struct {
 	rArmPartsList []*core.PartDefinition
 	lArmPartsList []*core.PartDefinition
 	legsPartsList []*core.PartDefinition
+	stageList     []*core.StageDefinition
+	stageIndex    int
 
 	// UI Widgets for results
 	expectedDamageText *widget.Text
@@ -68,6 +71,7 @@ type BalanceTestScene struct {
 	defenseChanceText  *widget.Text
 	criticalChanceText *widget.Text
 	simulationLogText  *widget.Text
+	stageButton        *widget.Button
 }
 
 // mustGetPartDefinition is a helper, panics if part not found.
@@ -99,6 +103,9 @@ func NewBalanceTestScene(res *data.SharedResources, manager *SceneManager) (*Bal
 	// パーツリストの準備
 	bs.setupPartLists()
 
+	// ステージの初期化（地形補正の確認用）
+	bs.setupStage()
+
 	// 攻撃側と防御側のユニットを初期化
 	bs.attacker = bs.createTestUnit(core.Team1, "Attacker")
 	bs.defender = bs.createTestUnit(core.Team2, "Defender")
@@ -169,6 +176,8 @@ func (bs *BalanceTestScene) createCenterPanel() *widget.Container {
 		widget.ContainerOpts.BackgroundImage(image.NewNineSliceColor(color.NRGBA{20, 20, 30, 200})),
 	)
 
+	bs.stageButton = bs.createStageSelectionRow(panel)
+
 	panel.AddChild(widget.NewText(widget.TextOpts.Text("Calculation Results", bs.resources.Font, color.White)))
 
 	bs.expectedDamageText = widget.NewText(widget.TextOpts.Text("Expected Damage: ", bs.resources.Font, color.White))
@@ -199,6 +208,38 @@ func (bs *BalanceTestScene) createCenterPanel() *widget.Container {
 	return panel
 }
 
+// createStageSelectionRow はステージを切り替える行を作成し、ステージ名を表示するボタンを返します。
+func (bs *BalanceTestScene) createStageSelectionRow(parent *widget.Container) *widget.Button {
+	rowContainer := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(3),
+			widget.GridLayoutOpts.Stretch([]bool{false, true, false}, []bool{true}),
+			widget.GridLayoutOpts.Spacing(10, 0),
+		)),
+	)
+	parent.AddChild(rowContainer)
+
+	rowContainer.AddChild(widget.NewButton(
+		widget.ButtonOpts.Text("<", bs.resources.Font, &widget.ButtonTextColor{Idle: color.White}),
+		widget.ButtonOpts.Image(bs.resources.ButtonImage),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { bs.changeStage(-1) }),
+	))
+
+	nameButton := widget.NewButton(
+		widget.ButtonOpts.Text(bs.currentStageLabel(), bs.resources.Font, &widget.ButtonTextColor{Idle: color.White}),
+		widget.ButtonOpts.Image(bs.resources.ButtonImage),
+	)
+	rowContainer.AddChild(nameButton)
+
+	rowContainer.AddChild(widget.NewButton(
+		widget.ButtonOpts.Text(">", bs.resources.Font, &widget.ButtonTextColor{Idle: color.White}),
+		widget.ButtonOpts.Image(bs.resources.ButtonImage),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { bs.changeStage(1) }),
+	))
+
+	return nameButton
+}
+
 func (bs *BalanceTestScene) createPartSelectionRow(parent *widget.Container, unit *balanceTestUnit, partType string) *widget.Button {
 	rowContainer := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewGridLayout(
@@ -272,6 +313,36 @@ func (bs *BalanceTestScene) setupPartLists() {
 	}
 }
 
+// setupStage はステージ一覧を準備し、既定のステージをワールドに設定します。
+func (bs *BalanceTestScene) setupStage() {
+	bs.stageList = bs.resources.GameDataManager.GetAllStageDefinitions()
+	for i, stage := range bs.stageList {
+		if stage.ID == bs.resources.GameData.StageID {
+			bs.stageIndex = i
+		}
+	}
+	if len(bs.stageList) > 0 {
+		entity.SetStage(bs.world, *bs.stageList[bs.stageIndex])
+	}
+}
+
+func (bs *BalanceTestScene) changeStage(direction int) {
+	if len(bs.stageList) == 0 {
+		return
+	}
+	bs.stageIndex = (bs.stageIndex + direction + len(bs.stageList)) % len(bs.stageList)
+	entity.SetStage(bs.world, *bs.stageList[bs.stageIndex])
+	bs.stageButton.Text().Label = bs.currentStageLabel()
+	bs.recalculate()
+}
+
+func (bs *BalanceTestScene) currentStageLabel() string {
+	if len(bs.stageList) == 0 {
+		return "Stage: -"
+	}
+	return fmt.Sprintf("Stage: %s", bs.stageList[bs.stageIndex].Name)
+}
+
 func (bs *BalanceTestScene) createTestUnit(team core.TeamID, name string) *balanceTestUnit {
 	entry := bs.world.Entry(bs.world.Create(
 		component.SettingsComponent,
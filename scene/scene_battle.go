@@ -13,6 +13,7 @@ import (
 	"medarot-ebiten/ui"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/yohamta/donburi"
 	"github.com/yohamta/donburi/filter"
 	"github.com/yohamta/donburi/query"
@@ -27,9 +28,15 @@ type BattleScene struct {
 	tickCount       int
 	debugMode       bool
 	playerTeam      core.TeamID
+	setup           *core.BattleSetup
 	winner          core.TeamID
+	isGameOver      bool
 	battleUIManager system.UIUpdater
 
+	// オートバトル
+	autoBattle bool
+	speedIndex int // Config.AutoBattle.SpeedMultipliers の何番目の速度か
+
 	// 状態管理
 	battleStates map[core.GameState]system.BattleState
 
@@ -45,9 +52,11 @@ type BattleScene struct {
 	targetSelector         *system.TargetSelector
 	damageCalculator       *system.DamageCalculator
 	hitCalculator          *system.HitCalculator
+	victoryRule            system.VictoryRule
 }
 
-func NewBattleScene(res *data.SharedResources, manager *SceneManager) *BattleScene {
+// NewBattleScene は、setup で宣言されたチーム編成で戦闘シーンを生成します。
+func NewBattleScene(res *data.SharedResources, manager *SceneManager, setup *core.BattleSetup) *BattleScene {
 	world := donburi.NewWorld()
 
 	bs := &BattleScene{
@@ -55,7 +64,8 @@ func NewBattleScene(res *data.SharedResources, manager *SceneManager) *BattleSce
 		manager:         manager,
 		world:           world,
 		debugMode:       true,
-		playerTeam:      core.Team1,
+		playerTeam:      setup.PlayerTeam,
+		setup:           setup,
 		winner:          core.TeamNone,
 		gameDataManager: res.GameDataManager,
 		// SharedResourcesから正しい型のrandを取得します。
@@ -63,7 +73,7 @@ func NewBattleScene(res *data.SharedResources, manager *SceneManager) *BattleSce
 	}
 
 	// ワールドの初期化
-	entity.InitializeBattleWorld(bs.world, bs.resources, bs.playerTeam)
+	entity.InitializeBattleWorld(bs.world, bs.resources, bs.setup)
 
 	// UI専用の状態コンポーネントをワールドに登録
 	uiStateEntry := bs.world.Entry(bs.world.Create(ui.BattleUIStateComponent, component.WorldStateTag))
@@ -76,13 +86,14 @@ func NewBattleScene(res *data.SharedResources, manager *SceneManager) *BattleSce
 	bs.damageCalculator = system.NewDamageCalculator(bs.world, &bs.resources.Config, bs.partInfoProvider, bs.gameDataManager, bs.rand, logger)
 	bs.hitCalculator = system.NewHitCalculator(bs.world, &bs.resources.Config, bs.partInfoProvider, bs.rand, logger)
 	bs.targetSelector = system.NewTargetSelector(bs.world, &bs.resources.Config, bs.partInfoProvider)
-	bs.chargeInitiationSystem = system.NewChargeInitiationSystem(bs.world, bs.partInfoProvider)
+	bs.chargeInitiationSystem = system.NewChargeInitiationSystem(bs.world, &bs.resources.Config, bs.partInfoProvider)
 	bs.statusEffectSystem = system.NewStatusEffectSystem(bs.world, bs.damageCalculator)
 	bs.postActionEffectSystem = system.NewPostActionEffectSystem(bs.world, bs.statusEffectSystem, bs.gameDataManager, bs.partInfoProvider)
+	bs.victoryRule = system.NewVictoryRule(&bs.resources.Config, bs.gameDataManager)
 
 	// UIとViewModelFactoryの初期化
 	// ViewModelFactoryは、UIが必要とする情報（パーツ情報など）を提供するためのインターフェース(PartInfoProvider)に依存します。
-	viewModelFactory := ui.NewViewModelFactory(bs.partInfoProvider, bs.gameDataManager, bs.rand)
+	viewModelFactory := ui.NewViewModelFactory(&bs.resources.Config, bs.partInfoProvider, bs.gameDataManager, bs.rand)
 	bs.battleUIManager = ui.NewBattleUIManager(&bs.resources.Config, bs.resources, viewModelFactory)
 
 	// 戦闘の進行を管理するステートマシンの初期化
@@ -94,14 +105,68 @@ func NewBattleScene(res *data.SharedResources, manager *SceneManager) *BattleSce
 		core.StatePostAction:         &system.PostActionState{},
 		core.StateMessage:            &system.MessageState{},
 		core.StateGameOver:           &system.GameOverState{},
+		core.StateChargeMenu:         &system.ChargeMenuState{},
 	}
 
 	// 初期状態を設定
 	bs.SetState(core.StateGaugeProgress)
 
+	if setup.AutoBattle {
+		bs.setAutoBattle(true)
+	}
+
 	return bs
 }
 
+// setAutoBattle はオートバトルを切り替え、プレイヤーの機体のAIと戦闘の速度に反映します。
+func (bs *BattleScene) setAutoBattle(enabled bool) {
+	bs.autoBattle = enabled
+	system.SetAutoBattleSystem(bs.world, enabled)
+	if enabled {
+		log.Println("オートバトルを開始します。")
+	} else {
+		log.Println("オートバトルを終了します。各機体は次の行動選択からプレイヤーの操作に戻ります。")
+	}
+	bs.applyBattleSpeed()
+}
+
+// cycleBattleSpeed はオートバトル中の戦闘の速度を次の倍率に切り替えます。
+func (bs *BattleScene) cycleBattleSpeed() {
+	if len(bs.resources.Config.AutoBattle.SpeedMultipliers) == 0 {
+		return
+	}
+	bs.speedIndex = (bs.speedIndex + 1) % len(bs.resources.Config.AutoBattle.SpeedMultipliers)
+	bs.applyBattleSpeed()
+}
+
+// battleSpeed は現在の戦闘の速度の倍率を返します。オートバトル中以外は常に等倍です。
+func (bs *BattleScene) battleSpeed() int {
+	multipliers := bs.resources.Config.AutoBattle.SpeedMultipliers
+	if !bs.autoBattle || bs.speedIndex >= len(multipliers) || multipliers[bs.speedIndex] < 1 {
+		return 1
+	}
+	return multipliers[bs.speedIndex]
+}
+
+// applyBattleSpeed は、1秒あたりの更新回数を速度の倍率に合わせて変更し、UIに状態を伝えます。
+// 更新回数そのものを増やすため、ゲージ・アニメーション・メッセージの自動送りがすべて同じ倍率で速くなり、
+// 1回のクリックが複数回の更新で処理されることもありません。
+func (bs *BattleScene) applyBattleSpeed() {
+	speed := bs.battleSpeed()
+	ebiten.SetTPS(ebiten.DefaultTPS * speed)
+	bs.battleUIManager.SetAutoBattle(bs.autoBattle, speed)
+}
+
+// handleAutoBattleInput は、オートバトルの切り替え（Aキー）と速度の切り替え（Sキー）を処理します。
+func (bs *BattleScene) handleAutoBattleInput() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		bs.setAutoBattle(!bs.autoBattle)
+	}
+	if bs.autoBattle && inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		bs.cycleBattleSpeed()
+	}
+}
+
 func (bs *BattleScene) SetState(newState core.GameState) {
 	gameStateEntry, ok := query.NewQuery(filter.Contains(component.GameStateComponent)).First(bs.world)
 	if !ok {
@@ -113,6 +178,10 @@ func (bs *BattleScene) SetState(newState core.GameState) {
 func (bs *BattleScene) Update() error {
 	bs.tickCount++
 
+	if !bs.isGameOver {
+		bs.handleAutoBattleInput()
+	}
+
 	// 1. UIを更新し、UIから発行されたゲームイベントを収集
 	uiEvents := bs.battleUIManager.Update(bs.tickCount, bs.world)
 
@@ -135,6 +204,7 @@ func (bs *BattleScene) Update() error {
 		TargetSelector:         bs.targetSelector,
 		DamageCalculator:       bs.damageCalculator,
 		HitCalculator:          bs.hitCalculator,
+		VictoryRule:            bs.victoryRule,
 	}
 
 	var stateEvents []event.GameEvent
@@ -210,6 +280,28 @@ func (bs *BattleScene) processGameEvents(gameEvents []event.GameEvent) []event.G
 			} else {
 				stateChangeEvents = append(stateChangeEvents, event.StateChangeRequestedGameEvent{NextState: core.StateGaugeProgress})
 			}
+		case event.ChargeMenuRequestedGameEvent:
+			// チャージ中の機体のメニューを開き、ゲージの進行を止める
+			if cms, ok := bs.battleStates[core.StateChargeMenu].(*system.ChargeMenuState); ok {
+				cms.Open(e.ActingEntityID)
+			}
+			stateChangeEvents = append(stateChangeEvents, event.StateChangeRequestedGameEvent{NextState: core.StateChargeMenu})
+		case event.ActionCanceledGameEvent:
+			// プレイヤーがチャージ中の行動をキャンセルした
+			if actingEntry := bs.world.Entry(e.ActingEntityID); actingEntry != nil && actingEntry.Valid() {
+				bs.chargeInitiationSystem.CancelCharge(actingEntry)
+			}
+			stateChangeEvents = append(stateChangeEvents, event.StateChangeRequestedGameEvent{NextState: core.StateGaugeProgress})
+		case event.ActionRetargetedGameEvent:
+			// プレイヤーがチャージ中の行動のターゲットを変更した
+			actingEntry := bs.world.Entry(e.ActingEntityID)
+			targetEntry := bs.world.Entry(e.TargetEntityID)
+			if actingEntry != nil && actingEntry.Valid() {
+				bs.chargeInitiationSystem.RetargetCharge(actingEntry, targetEntry, e.TargetPartSlot)
+			}
+			stateChangeEvents = append(stateChangeEvents, event.StateChangeRequestedGameEvent{NextState: core.StateGaugeProgress})
+		case event.ChargeMenuClosedGameEvent:
+			stateChangeEvents = append(stateChangeEvents, event.StateChangeRequestedGameEvent{NextState: core.StateGaugeProgress})
 		case event.ActionAnimationStartedGameEvent:
 			// アニメーションを開始し、状態を遷移
 			bs.battleUIManager.SetAnimation(&e.AnimationData)
@@ -220,7 +312,7 @@ func (bs *BattleScene) processGameEvents(gameEvents []event.GameEvent) []event.G
 			stateChangeEvents = append(stateChangeEvents, event.StateChangeRequestedGameEvent{NextState: core.StatePostAction})
 		case event.MessageDisplayFinishedGameEvent:
 			// メッセージ表示が完了。ゲームオーバーでなければゲージ進行へ
-			if bs.winner != core.TeamNone {
+			if bs.isGameOver {
 				stateChangeEvents = append(stateChangeEvents, event.StateChangeRequestedGameEvent{NextState: core.StateGameOver})
 			} else {
 				stateChangeEvents = append(stateChangeEvents, event.StateChangeRequestedGameEvent{NextState: core.StateGaugeProgress})
@@ -228,9 +320,11 @@ func (bs *BattleScene) processGameEvents(gameEvents []event.GameEvent) []event.G
 		case event.GameOverGameEvent:
 			// ゲームオーバーフラグを立て、メッセージ表示状態へ
 			bs.winner = e.Winner
+			bs.isGameOver = true
 			stateChangeEvents = append(stateChangeEvents, event.StateChangeRequestedGameEvent{NextState: core.StateMessage})
 		case event.GoToTitleSceneGameEvent:
-			// タイトルシーンへ遷移
+			// 戦闘の速度を元に戻してタイトルシーンへ遷移
+			ebiten.SetTPS(ebiten.DefaultTPS)
 			bs.manager.GoToTitleScene()
 		case event.StateChangeRequestedGameEvent:
 			// 他のシステムから直接発行された状態遷移要求
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image/color"
 	"log"
+	"math"
 	"sort"
 	"strings"
 
@@ -29,6 +30,13 @@ type CustomizeScene struct {
 	lArmNameButton          *widget.Button
 	legsNameButton          *widget.Button
 	medarotSelectionButtons []*widget.Button
+	rosterContainer         *widget.Container // チームごとの機体選択ボタンと増減ボタンを並べるコンテナ
+	stageNameButton         *widget.Button
+	difficultyNameButton    *widget.Button
+	autoBattleButton        *widget.Button // 次の戦闘をオートバトルで開始するかの切り替え
+	autoPersonalityButton   *widget.Button // 選択中の機体がオートバトルで使う性格の切り替え
+	tacticButton            *widget.Button // 選択中の機体のチームのAIの戦術の切り替え
+	loadMeterText           *widget.Text   // 選択中の機体の総重量と脚部積載量の表示
 
 	playerMedarots            []*core.MedarotData
 	currentTargetMedarotIndex int
@@ -52,15 +60,7 @@ func NewCustomizeScene(res *data.SharedResources, manager *SceneManager) *Custom
 		manager:   manager,
 	}
 
-	for i := range res.GameData.Medarots {
-		cs.playerMedarots = append(cs.playerMedarots, &res.GameData.Medarots[i])
-	}
-	sort.Slice(cs.playerMedarots, func(i, j int) bool {
-		if cs.playerMedarots[i].Team != cs.playerMedarots[j].Team {
-			return cs.playerMedarots[i].Team < cs.playerMedarots[j].Team
-		}
-		return cs.playerMedarots[i].DrawIndex < cs.playerMedarots[j].DrawIndex
-	})
+	cs.rebuildMedarotList()
 
 	if len(cs.playerMedarots) == 0 {
 		rootContainer := widget.NewContainer()
@@ -136,37 +136,30 @@ func (cs *CustomizeScene) createLayout() *widget.Container {
 	)
 	rightPanel.AddChild(cs.statusText)
 
-	medarotSelectionContainer := widget.NewContainer(
+	cs.loadMeterText = widget.NewText(
+		widget.TextOpts.Text("", cs.resources.GameDataManager.Font, color.White),
+	)
+	rightPanel.AddChild(cs.loadMeterText)
+
+	cs.rosterContainer = widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewRowLayout(
-			widget.RowLayoutOpts.Spacing(10),
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(5),
 		)),
 	)
-	leftPanel.AddChild(medarotSelectionContainer)
-
-	buttonImage := &widget.ButtonImage{
-		Idle:    image.NewNineSliceColor(cs.resources.Config.UI.Colors.Gray),
-		Hover:   image.NewNineSliceColor(color.NRGBA{180, 180, 180, 255}),
-		Pressed: image.NewNineSliceColor(color.NRGBA{100, 100, 100, 255}),
-	}
-	for i := 0; i < len(cs.playerMedarots); i++ {
-		idx := i
-		button := widget.NewButton(
-			widget.ButtonOpts.Image(buttonImage),
-			widget.ButtonOpts.Text(cs.playerMedarots[idx].Name, cs.resources.GameDataManager.Font, &widget.ButtonTextColor{Idle: color.White}),
-			widget.ButtonOpts.TextPadding(widget.NewInsetsSimple(5)),
-			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
-				cs.selectMedarot(idx)
-			}),
-		)
-		cs.medarotSelectionButtons = append(cs.medarotSelectionButtons, button)
-		medarotSelectionContainer.AddChild(button)
-	}
+	leftPanel.AddChild(cs.rosterContainer)
+	cs.rebuildRosterButtons()
 
 	cs.medalNameButton = cs.createPartSelectionRow(leftPanel, core.CustomizeCategoryMedal)
 	cs.headNameButton = cs.createPartSelectionRow(leftPanel, core.CustomizeCategoryHead)
 	cs.rArmNameButton = cs.createPartSelectionRow(leftPanel, core.CustomizeCategoryRArm)
 	cs.lArmNameButton = cs.createPartSelectionRow(leftPanel, core.CustomizeCategoryLArm)
 	cs.legsNameButton = cs.createPartSelectionRow(leftPanel, core.CustomizeCategoryLegs)
+	cs.stageNameButton = cs.createStageSelectionRow(leftPanel)
+	cs.difficultyNameButton = cs.createDifficultySelectionRow(leftPanel)
+	cs.autoBattleButton = cs.createCycleSelectionRow(leftPanel, cs.currentAutoBattleLabel(), cs.toggleAutoBattle, func() {})
+	cs.autoPersonalityButton = cs.createCycleSelectionRow(leftPanel, "", cs.changeAutoPersonality, func() {})
+	cs.tacticButton = cs.createCycleSelectionRow(leftPanel, "", cs.changeTeamTactic, func() {})
 
 	saveButton := widget.NewButton(
 		widget.ButtonOpts.Image(&widget.ButtonImage{
@@ -194,6 +187,268 @@ func (cs *CustomizeScene) createLayout() *widget.Container {
 	return rootContainer
 }
 
+// rebuildMedarotList は GameData の機体一覧からチーム順・表示順に並べた編集対象リストを作り直します。
+// 機体の追加・削除で GameData.Medarots が再確保されるため、そのたびに呼び出す必要があります。
+func (cs *CustomizeScene) rebuildMedarotList() {
+	cs.playerMedarots = cs.playerMedarots[:0]
+	for i := range cs.resources.GameData.Medarots {
+		cs.playerMedarots = append(cs.playerMedarots, &cs.resources.GameData.Medarots[i])
+	}
+	sort.Slice(cs.playerMedarots, func(i, j int) bool {
+		if cs.playerMedarots[i].Team != cs.playerMedarots[j].Team {
+			return cs.playerMedarots[i].Team < cs.playerMedarots[j].Team
+		}
+		return cs.playerMedarots[i].DrawIndex < cs.playerMedarots[j].DrawIndex
+	})
+}
+
+// rebuildRosterButtons はチームごとに機体選択ボタンと機体数の増減ボタンを並べ直します。
+func (cs *CustomizeScene) rebuildRosterButtons() {
+	cs.rosterContainer.RemoveChildren()
+	cs.medarotSelectionButtons = nil
+
+	buttonImage := &widget.ButtonImage{
+		Idle:    image.NewNineSliceColor(cs.resources.Config.UI.Colors.Gray),
+		Hover:   image.NewNineSliceColor(color.NRGBA{180, 180, 180, 255}),
+		Pressed: image.NewNineSliceColor(color.NRGBA{100, 100, 100, 255}),
+	}
+	textColor := &widget.ButtonTextColor{Idle: color.White}
+	font := cs.resources.GameDataManager.Font
+
+	// チーム数の増減ボタン（3〜4チーム戦の編成用）
+	teamCountRow := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Spacing(10),
+		)),
+	)
+	cs.rosterContainer.AddChild(teamCountRow)
+	teamCountRow.AddChild(widget.NewButton(
+		widget.ButtonOpts.Image(buttonImage),
+		widget.ButtonOpts.Text("Remove Team", font, textColor),
+		widget.ButtonOpts.TextPadding(widget.NewInsetsSimple(5)),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { cs.removeLastTeam() }),
+	))
+	teamCountRow.AddChild(widget.NewButton(
+		widget.ButtonOpts.Image(buttonImage),
+		widget.ButtonOpts.Text("Add Team", font, textColor),
+		widget.ButtonOpts.TextPadding(widget.NewInsetsSimple(5)),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { cs.addTeam() }),
+	))
+
+	var teamRow *widget.Container
+	currentTeam := core.TeamNone
+	for i := 0; i < len(cs.playerMedarots); i++ {
+		idx := i
+		medarot := cs.playerMedarots[idx]
+		if teamRow == nil || medarot.Team != currentTeam {
+			currentTeam = medarot.Team
+			teamRow = cs.createTeamRosterRow(currentTeam, buttonImage, textColor)
+		}
+		button := widget.NewButton(
+			widget.ButtonOpts.Image(buttonImage),
+			widget.ButtonOpts.Text(medarot.Name, font, textColor),
+			widget.ButtonOpts.TextPadding(widget.NewInsetsSimple(5)),
+			widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+				cs.selectMedarot(idx)
+			}),
+		)
+		cs.medarotSelectionButtons = append(cs.medarotSelectionButtons, button)
+		teamRow.AddChild(button)
+	}
+}
+
+// createTeamRosterRow は1チーム分の行を作成し、チーム名と機体数の増減ボタンを配置します。
+func (cs *CustomizeScene) createTeamRosterRow(team core.TeamID, buttonImage *widget.ButtonImage, textColor *widget.ButtonTextColor) *widget.Container {
+	font := cs.resources.GameDataManager.Font
+	row := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Spacing(10),
+		)),
+	)
+	cs.rosterContainer.AddChild(row)
+
+	row.AddChild(widget.NewText(
+		widget.TextOpts.Text(fmt.Sprintf("Team %d (%d)", int(team)+1, cs.countTeamMembers(team)), font, color.White),
+		widget.TextOpts.Position(widget.TextPositionStart, widget.TextPositionCenter),
+	))
+	row.AddChild(widget.NewButton(
+		widget.ButtonOpts.Image(buttonImage),
+		widget.ButtonOpts.Text("-", font, textColor),
+		widget.ButtonOpts.TextPadding(widget.NewInsetsSimple(5)),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { cs.removeMedarotFromTeam(team) }),
+	))
+	row.AddChild(widget.NewButton(
+		widget.ButtonOpts.Image(buttonImage),
+		widget.ButtonOpts.Text("+", font, textColor),
+		widget.ButtonOpts.TextPadding(widget.NewInsetsSimple(5)),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { cs.addMedarotToTeam(team) }),
+	))
+	return row
+}
+
+// teamIDs は編集中の編成に含まれるチームIDを昇順で返します。
+func (cs *CustomizeScene) teamIDs() []core.TeamID {
+	var teams []core.TeamID
+	for _, medarot := range cs.playerMedarots {
+		if len(teams) == 0 || teams[len(teams)-1] != medarot.Team {
+			teams = append(teams, medarot.Team)
+		}
+	}
+	return teams
+}
+
+// addTeam は新しいチームを追加します。最初の機体は最後のチームのリーダーの構成を複製したリーダー機です。
+func (cs *CustomizeScene) addTeam() {
+	teams := cs.teamIDs()
+	if len(teams) == 0 || len(teams) >= core.MaxTeams {
+		log.Printf("チーム数は最大%dです。", core.MaxTeams)
+		return
+	}
+
+	lastTeam := teams[len(teams)-1]
+	var template *core.MedarotData
+	for _, medarot := range cs.playerMedarots {
+		if medarot.Team == lastTeam && (template == nil || medarot.IsLeader) {
+			template = medarot
+		}
+	}
+
+	newTeam := lastTeam + 1
+	newMedarot := *template
+	newMedarot.ID = cs.nextMedarotID(fmt.Sprintf("T%d-00", int(newTeam)+1))
+	newMedarot.Name = fmt.Sprintf("%s-T%d", template.Name, int(newTeam)+1)
+	newMedarot.Team = newTeam
+	newMedarot.IsLeader = true
+	newMedarot.DrawIndex = 0
+	cs.resources.GameData.Medarots = append(cs.resources.GameData.Medarots, newMedarot)
+
+	cs.refreshRoster(newMedarot.ID)
+}
+
+// removeLastTeam は最後のチームを機体ごと削除します。2チーム未満にはできません。
+func (cs *CustomizeScene) removeLastTeam() {
+	teams := cs.teamIDs()
+	if len(teams) <= 2 {
+		log.Println("2チーム未満にはできません。")
+		return
+	}
+
+	lastTeam := teams[len(teams)-1]
+	remaining := make([]core.MedarotData, 0, len(cs.resources.GameData.Medarots))
+	for _, medarot := range cs.resources.GameData.Medarots {
+		if medarot.Team != lastTeam {
+			remaining = append(remaining, medarot)
+		}
+	}
+	cs.resources.GameData.Medarots = remaining
+
+	cs.refreshRoster("")
+}
+
+// countTeamMembers は指定チームの機体数を返します。
+func (cs *CustomizeScene) countTeamMembers(team core.TeamID) int {
+	count := 0
+	for _, medarot := range cs.playerMedarots {
+		if medarot.Team == team {
+			count++
+		}
+	}
+	return count
+}
+
+// addMedarotToTeam はチームの末尾の機体の構成を複製して、新しい機体をチームに追加します。
+func (cs *CustomizeScene) addMedarotToTeam(team core.TeamID) {
+	if cs.countTeamMembers(team) >= core.MaxPlayersPerTeam {
+		log.Printf("チーム%dは既に最大機体数(%d)です。", int(team)+1, core.MaxPlayersPerTeam)
+		return
+	}
+
+	var last *core.MedarotData
+	for _, medarot := range cs.playerMedarots {
+		if medarot.Team == team {
+			last = medarot
+		}
+	}
+	if last == nil {
+		return
+	}
+
+	newMedarot := *last
+	newMedarot.ID = cs.nextMedarotID(last.ID)
+	newMedarot.Name = fmt.Sprintf("%s-%d", last.Name, last.DrawIndex+2)
+	newMedarot.IsLeader = false
+	newMedarot.DrawIndex = last.DrawIndex + 1
+	cs.resources.GameData.Medarots = append(cs.resources.GameData.Medarots, newMedarot)
+
+	cs.refreshRoster(newMedarot.ID)
+}
+
+// removeMedarotFromTeam はチームの最後尾にいるリーダー以外の機体を削除します。
+func (cs *CustomizeScene) removeMedarotFromTeam(team core.TeamID) {
+	if cs.countTeamMembers(team) <= core.MinPlayersPerTeam {
+		log.Printf("チーム%dはこれ以上機体を減らせません。", int(team)+1)
+		return
+	}
+
+	removeID := ""
+	for _, medarot := range cs.playerMedarots {
+		if medarot.Team == team && !medarot.IsLeader {
+			removeID = medarot.ID
+		}
+	}
+	if removeID == "" {
+		return
+	}
+
+	remaining := make([]core.MedarotData, 0, len(cs.resources.GameData.Medarots)-1)
+	for _, medarot := range cs.resources.GameData.Medarots {
+		if medarot.ID != removeID {
+			remaining = append(remaining, medarot)
+		}
+	}
+	cs.resources.GameData.Medarots = remaining
+
+	cs.refreshRoster("")
+}
+
+// refreshRoster は機体の増減後に表示順を詰め直し、UIを再構築します。
+// selectID が空でなければ、その機体を選択状態にします。
+func (cs *CustomizeScene) refreshRoster(selectID string) {
+	cs.rebuildMedarotList()
+	drawIndices := make(map[core.TeamID]int)
+	for _, medarot := range cs.playerMedarots {
+		medarot.DrawIndex = drawIndices[medarot.Team]
+		drawIndices[medarot.Team]++
+	}
+
+	cs.currentTargetMedarotIndex = 0
+	for i, medarot := range cs.playerMedarots {
+		if medarot.ID == selectID {
+			cs.currentTargetMedarotIndex = i
+		}
+	}
+	cs.rebuildRosterButtons()
+	cs.refreshUIForSelectedMedarot()
+}
+
+// nextMedarotID は baseID と同じ接頭辞（例: "P-"）を持ち、既存の機体と重複しないIDを生成します。
+func (cs *CustomizeScene) nextMedarotID(baseID string) string {
+	prefix := baseID
+	if i := strings.LastIndex(baseID, "-"); i >= 0 {
+		prefix = baseID[:i]
+	}
+	used := make(map[string]bool)
+	for _, medarot := range cs.resources.GameData.Medarots {
+		used[medarot.ID] = true
+	}
+	for n := 1; ; n++ {
+		id := fmt.Sprintf("%s-%02d", prefix, n)
+		if !used[id] {
+			return id
+		}
+	}
+}
+
 func (cs *CustomizeScene) selectMedarot(index int) {
 	if cs.currentTargetMedarotIndex == index {
 		return
@@ -216,6 +471,8 @@ func (cs *CustomizeScene) refreshUIForSelectedMedarot() {
 	cs.rArmNameButton.Text().Label = cs.getCurrentName(core.CustomizeCategoryRArm)
 	cs.lArmNameButton.Text().Label = cs.getCurrentName(core.CustomizeCategoryLArm)
 	cs.legsNameButton.Text().Label = cs.getCurrentName(core.CustomizeCategoryLegs)
+	cs.autoPersonalityButton.Text().Label = cs.currentAutoPersonalityLabel()
+	cs.tacticButton.Text().Label = cs.currentTeamTacticLabel()
 
 	cs.updateStatus(target.MedalID)
 	cs.updateMedarotSelectionButtons()
@@ -292,6 +549,202 @@ func (cs *CustomizeScene) createPartSelectionRow(parent *widget.Container, label
 	return nameButton
 }
 
+// createStageSelectionRow は次の戦闘で使用するステージを切り替える行を作成します。
+func (cs *CustomizeScene) createStageSelectionRow(parent *widget.Container) *widget.Button {
+	return cs.createCycleSelectionRow(parent, cs.currentStageLabel(), cs.changeStage, cs.updateStageStatus)
+}
+
+// createDifficultySelectionRow は次の戦闘のAIの難易度を切り替える行を作成します。
+func (cs *CustomizeScene) createDifficultySelectionRow(parent *widget.Container) *widget.Button {
+	return cs.createCycleSelectionRow(parent, cs.currentDifficultyLabel(), cs.changeDifficulty, func() {})
+}
+
+// createCycleSelectionRow は、左右のボタンで選択肢を順に切り替える行を作成し、選択中の項目を表示するボタンを返します。
+func (cs *CustomizeScene) createCycleSelectionRow(parent *widget.Container, label string, change func(direction int), onClick func()) *widget.Button {
+	rowContainer := widget.NewContainer(
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+			Stretch: true,
+		})),
+		widget.ContainerOpts.Layout(widget.NewGridLayout(
+			widget.GridLayoutOpts.Columns(3),
+			widget.GridLayoutOpts.Stretch([]bool{false, true, false}, []bool{true}),
+			widget.GridLayoutOpts.Spacing(10, 0),
+		)),
+	)
+	parent.AddChild(rowContainer)
+
+	buttonImage := &widget.ButtonImage{
+		Idle:    image.NewNineSliceColor(cs.resources.Config.UI.Colors.Gray),
+		Hover:   image.NewNineSliceColor(color.NRGBA{180, 180, 180, 255}),
+		Pressed: image.NewNineSliceColor(color.NRGBA{100, 100, 100, 255}),
+	}
+	textColor := &widget.ButtonTextColor{Idle: color.White}
+
+	rowContainer.AddChild(widget.NewButton(
+		widget.ButtonOpts.Image(buttonImage),
+		widget.ButtonOpts.Text("◀", cs.resources.GameDataManager.Font, textColor),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { change(-1) }),
+	))
+	nameButton := widget.NewButton(
+		widget.ButtonOpts.Image(buttonImage),
+		widget.ButtonOpts.Text(label, cs.resources.GameDataManager.Font, textColor),
+		widget.ButtonOpts.TextPadding(widget.NewInsetsSimple(5)),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { onClick() }),
+	)
+	rowContainer.AddChild(nameButton)
+	rowContainer.AddChild(widget.NewButton(
+		widget.ButtonOpts.Image(buttonImage),
+		widget.ButtonOpts.Text("▶", cs.resources.GameDataManager.Font, textColor),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) { change(1) }),
+	))
+	return nameButton
+}
+
+// changeStage は次の戦闘で使用するステージを順に切り替えます。
+func (cs *CustomizeScene) changeStage(direction int) {
+	stages := cs.resources.GameDataManager.GetAllStageDefinitions()
+	if len(stages) == 0 {
+		return
+	}
+	index := 0
+	for i, stage := range stages {
+		if stage.ID == cs.resources.GameData.StageID {
+			index = i
+		}
+	}
+	index = (index + direction + len(stages)) % len(stages)
+	cs.resources.GameData.StageID = stages[index].ID
+	cs.stageNameButton.Text().Label = cs.currentStageLabel()
+	cs.updateStageStatus()
+}
+
+func (cs *CustomizeScene) currentStageLabel() string {
+	if stage, found := cs.resources.GameDataManager.GetStageDefinition(cs.resources.GameData.StageID); found {
+		return fmt.Sprintf("Stage: %s", stage.Name)
+	}
+	return "Stage: -"
+}
+
+// changeDifficulty は次の戦闘のAIの難易度を順に切り替えます。
+func (cs *CustomizeScene) changeDifficulty(direction int) {
+	difficulties := core.AIDifficulties
+	current := cs.resources.GameData.AIDifficulty
+	if current == "" {
+		current = core.AIDifficultyNormal
+	}
+	index := 0
+	for i, difficulty := range difficulties {
+		if difficulty == current {
+			index = i
+		}
+	}
+	index = (index + direction + len(difficulties)) % len(difficulties)
+	cs.resources.GameData.AIDifficulty = difficulties[index]
+	cs.difficultyNameButton.Text().Label = cs.currentDifficultyLabel()
+}
+
+// currentDifficultyLabel は次の戦闘のAIの難易度を表示するボタンのラベルを返します。難易度が未指定の場合は normal と表示します。
+func (cs *CustomizeScene) currentDifficultyLabel() string {
+	if cs.resources.GameData.AIDifficulty == "" {
+		return fmt.Sprintf("AI: %s", core.AIDifficultyNormal)
+	}
+	return fmt.Sprintf("AI: %s", cs.resources.GameData.AIDifficulty)
+}
+
+// toggleAutoBattle は次の戦闘をオートバトルで開始するかを切り替えます。
+func (cs *CustomizeScene) toggleAutoBattle(direction int) {
+	cs.resources.GameData.AutoBattle = !cs.resources.GameData.AutoBattle
+	cs.autoBattleButton.Text().Label = cs.currentAutoBattleLabel()
+}
+
+// currentAutoBattleLabel は次の戦闘をオートバトルで開始するかを表示するボタンのラベルを返します。
+func (cs *CustomizeScene) currentAutoBattleLabel() string {
+	if cs.resources.GameData.AutoBattle {
+		return "Auto: on"
+	}
+	return "Auto: off"
+}
+
+// changeAutoPersonality は選択中の機体がオートバトルで使う性格を順に切り替えます。
+// 先頭の選択肢（空）はメダルの性格を使います。
+func (cs *CustomizeScene) changeAutoPersonality(direction int) {
+	target := cs.playerMedarots[cs.currentTargetMedarotIndex]
+	choices := []string{""}
+	for _, def := range cs.resources.GameDataManager.GetAllPersonalityDefinitions() {
+		choices = append(choices, def.ID)
+	}
+	index := 0
+	for i, choice := range choices {
+		if choice == target.AutoBattlePersonality {
+			index = i
+		}
+	}
+	index = (index + direction + len(choices)) % len(choices)
+	target.AutoBattlePersonality = choices[index]
+	cs.autoPersonalityButton.Text().Label = cs.currentAutoPersonalityLabel()
+	cs.tacticButton.Text().Label = cs.currentTeamTacticLabel()
+}
+
+// currentAutoPersonalityLabel は選択中の機体がオートバトルで使う性格を表示するボタンのラベルを返します。
+func (cs *CustomizeScene) currentAutoPersonalityLabel() string {
+	target := cs.playerMedarots[cs.currentTargetMedarotIndex]
+	if target.AutoBattlePersonality == "" {
+		return "Auto AI: Medal"
+	}
+	return fmt.Sprintf("Auto AI: %s", target.AutoBattlePersonality)
+}
+
+// changeTeamTactic は選択中の機体のチームのAIの戦術を順に切り替えます。
+func (cs *CustomizeScene) changeTeamTactic(direction int) {
+	team := cs.playerMedarots[cs.currentTargetMedarotIndex].Team
+	tactics := core.TeamTactics
+	current := cs.currentTeamTactic(team)
+	index := 0
+	for i, tactic := range tactics {
+		if tactic == current {
+			index = i
+		}
+	}
+	index = (index + direction + len(tactics)) % len(tactics)
+	cs.resources.GameData.TeamTactics[team] = tactics[index]
+	cs.tacticButton.Text().Label = cs.currentTeamTacticLabel()
+}
+
+// currentTeamTactic はチームの戦術を返します。指定されていない場合は設定の既定の戦術を返します。
+func (cs *CustomizeScene) currentTeamTactic(team core.TeamID) core.TeamTactic {
+	if tactic, ok := cs.resources.GameData.TeamTactics[team]; ok && tactic != "" {
+		return tactic
+	}
+	return cs.resources.Config.TeamBrain.DefaultTactic
+}
+
+// currentTeamTacticLabel は選択中の機体のチームのAIの戦術を表示するボタンのラベルを返します。
+func (cs *CustomizeScene) currentTeamTacticLabel() string {
+	team := cs.playerMedarots[cs.currentTargetMedarotIndex].Team
+	return fmt.Sprintf("Team %d Tactic: %s", int(team)+1, cs.currentTeamTactic(team))
+}
+
+// updateStageStatus は選択中のステージの補正内容をステータス欄に表示します。
+func (cs *CustomizeScene) updateStageStatus() {
+	stage, found := cs.resources.GameDataManager.GetStageDefinition(cs.resources.GameData.StageID)
+	if !found {
+		cs.statusText.Label = ""
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Stage: %s\n\n", stage.Name))
+	sb.WriteString(fmt.Sprintf("Mobility: x%.2f\n", stage.MobilityMultiplier))
+	sb.WriteString(fmt.Sprintf("Propulsion: x%.2f\n", stage.PropulsionMultiplier))
+	sb.WriteString(fmt.Sprintf("Evasion: x%.2f\n", stage.EvasionMultiplier))
+	for legType, modifier := range stage.LegTypeModifiers {
+		sb.WriteString(fmt.Sprintf("%s: Mob x%.2f / Prop x%.2f\n", legType, modifier.MobilityMultiplier, modifier.PropulsionMultiplier))
+	}
+	for weaponType, modifier := range stage.WeaponModifiers {
+		sb.WriteString(fmt.Sprintf("%s: Pow x%.2f / Acc x%.2f\n", weaponType, modifier.PowerMultiplier, modifier.AccuracyMultiplier))
+	}
+	cs.statusText.Label = sb.String()
+}
+
 func (cs *CustomizeScene) changeSelection(label core.CustomizeCategory, direction int) {
 	target := cs.playerMedarots[cs.currentTargetMedarotIndex]
 	var listSize int
@@ -346,11 +799,17 @@ func (cs *CustomizeScene) updateStatus(id string) {
 		sb.WriteString(fmt.Sprintf("Accuracy: %d\n", partDef.Accuracy))
 		sb.WriteString(fmt.Sprintf("Charge: %d\n", partDef.Charge))
 		sb.WriteString(fmt.Sprintf("Cooldown: %d\n", partDef.Cooldown))
+		sb.WriteString(fmt.Sprintf("Weight: %d\n", partDef.Weight))
+		if partDef.SetID != "" {
+			sb.WriteString(fmt.Sprintf("Set: %s\n", partDef.SetID))
+		}
 		if partDef.Type == core.PartTypeLegs {
-			sb.WriteString(fmt.Sprintf("\nPropulsion: %d\n", partDef.Propulsion))
+			sb.WriteString(fmt.Sprintf("\nLegType: %s\n", partDef.LegType))
+			sb.WriteString(fmt.Sprintf("Propulsion: %d\n", partDef.Propulsion))
 			sb.WriteString(fmt.Sprintf("Mobility: %d\n", partDef.Mobility))
 			sb.WriteString(fmt.Sprintf("Stability: %d\n", partDef.Stability)) // Added Stability
 			sb.WriteString(fmt.Sprintf("Defense: %d\n", partDef.Defense))     // Added Defense for legs
+			sb.WriteString(fmt.Sprintf("LoadCapacity: %d\n", partDef.LoadCapacity))
 		}
 	} else if medal, found := cs.resources.GameDataManager.GetMedalDefinition(id); found { // Use GameDataManager
 		sb.WriteString(fmt.Sprintf("Name: %s\n", medal.Name))
@@ -359,7 +818,68 @@ func (cs *CustomizeScene) updateStatus(id string) {
 	} else {
 		sb.WriteString("No data available.")
 	}
+	sb.WriteString(cs.setBonusStatus())
 	cs.statusText.Label = sb.String()
+	cs.updateLoadMeter()
+}
+
+// updateLoadMeter は選択中の機体の総重量と脚部積載量をメーターで表示します。
+// 積載量を超えるとペナルティ付きの過積載、上限が有効で上限を超えると出撃できない構成として表示します。
+func (cs *CustomizeScene) updateLoadMeter() {
+	if len(cs.playerMedarots) == 0 {
+		cs.loadMeterText.Label = ""
+		return
+	}
+	target := cs.playerMedarots[cs.currentTargetMedarotIndex]
+	loadConfig := cs.resources.Config.Load
+	weight, capacity := cs.resources.GameDataManager.CalculateLoad(target)
+	ratio := data.LoadRatio(weight, capacity)
+
+	const meterLength = 10
+	filled := int(math.Round(math.Min(ratio, 1.0) * meterLength))
+	meter := strings.Repeat("#", filled) + strings.Repeat("-", meterLength-filled)
+
+	label := fmt.Sprintf("\nLoad: %d/%d (%.0f%%) [%s]", weight, capacity, ratio*100, meter)
+	textColor := cs.resources.Config.UI.Colors.White
+	switch {
+	case loadConfig.HardCapEnabled && ratio > loadConfig.HardCapRatio:
+		label += " ILLEGAL"
+		textColor = cs.resources.Config.UI.Colors.Red
+	case ratio > 1.0:
+		label += " OVERLOAD"
+		textColor = cs.resources.Config.UI.Colors.Yellow
+	}
+	cs.loadMeterText.Label = label
+	cs.loadMeterText.Color = textColor
+}
+
+// setBonusStatus は選択中の機体のパーツセットの揃い具合を表示用の文字列で返します。
+func (cs *CustomizeScene) setBonusStatus() string {
+	if len(cs.playerMedarots) == 0 {
+		return ""
+	}
+	target := cs.playerMedarots[cs.currentTargetMedarotIndex]
+	gdm := cs.resources.GameDataManager
+
+	if setBonus, completed := gdm.FindCompletedSetBonus(target); completed {
+		return fmt.Sprintf("\n*** SET COMPLETE: %s ***\n%s\n", setBonus.Name, setBonus.Description)
+	}
+
+	// 揃っていない場合は、最も多く装備しているセットの進捗を表示します。
+	counts := make(map[string]int)
+	bestSetID := ""
+	for _, partID := range []string{target.HeadID, target.RightArmID, target.LeftArmID, target.LegsID} {
+		if partDef, found := gdm.GetPartDefinition(partID); found && partDef.SetID != "" {
+			counts[partDef.SetID]++
+			if counts[partDef.SetID] > counts[bestSetID] || (counts[partDef.SetID] == counts[bestSetID] && partDef.SetID < bestSetID) {
+				bestSetID = partDef.SetID
+			}
+		}
+	}
+	if setBonus, found := gdm.GetSetBonusDefinition(bestSetID); found {
+		return fmt.Sprintf("\nSet: %s (%d/4)\n", setBonus.Name, counts[bestSetID])
+	}
+	return "\nSet: -\n"
 }
 
 func (cs *CustomizeScene) Update() error {
@@ -3,6 +3,7 @@ package scene
 import (
 	"log"
 
+	"medarot-ebiten/core"
 	"medarot-ebiten/data"
 
 	"github.com/noppikinatta/bamenn"
@@ -40,8 +41,8 @@ func (m *SceneManager) newTitleScene() (Scene, error) {
 	return NewTitleScene(m.resources, m), nil
 }
 
-func (m *SceneManager) newBattleScene() (Scene, error) {
-	return NewBattleScene(m.resources, m), nil
+func (m *SceneManager) newBattleScene(setup *core.BattleSetup) (Scene, error) {
+	return NewBattleScene(m.resources, m, setup), nil
 }
 
 func (m *SceneManager) newCustomizeScene() (Scene, error) {
@@ -67,8 +68,41 @@ func (m *SceneManager) GoToTitleScene() {
 	m.Sequence.Switch(scene)
 }
 
+// GoToBattleScene は、ロード済みのメダロット構成（medarots.csv）のチーム編成で戦闘を開始します。
 func (m *SceneManager) GoToBattleScene() {
-	scene, err := m.newBattleScene()
+	setup, err := data.NewBattleSetupFromGameData(m.resources.GameData, core.Team1)
+	if err != nil {
+		log.Printf("チーム編成が不正なため戦闘を開始できません: %v", err)
+		return
+	}
+	m.GoToBattleSceneWithSetup(setup)
+}
+
+// GoToFreeForAllBattleScene は、ロード済みのメダロット構成から参加機体を選び、バトルロイヤルを開始します。
+func (m *SceneManager) GoToFreeForAllBattleScene() {
+	setup, err := data.NewBattleSetupFromGameData(m.resources.GameData, core.Team1)
+	if err == nil {
+		setup, err = data.NewFreeForAllSetup(setup)
+	}
+	if err != nil {
+		log.Printf("バトルロイヤルの編成に失敗したため戦闘を開始できません: %v", err)
+		return
+	}
+	m.GoToBattleSceneWithSetup(setup)
+}
+
+// GoToBattleSceneWithSetup は、指定されたチーム編成で戦闘を開始します。
+// すべての戦闘の入口はここを通るため、編成と積載量の上限の検証もここで行います。
+func (m *SceneManager) GoToBattleSceneWithSetup(setup *core.BattleSetup) {
+	if err := data.ValidateBattleSetup(setup); err != nil {
+		log.Printf("チーム編成が不正なため戦闘を開始できません: %v", err)
+		return
+	}
+	if err := data.ValidateBattleSetupLoads(setup, m.resources.GameDataManager, &m.resources.Config); err != nil {
+		log.Printf("積載量の上限を超える機体がいるため戦闘を開始できません: %v", err)
+		return
+	}
+	scene, err := m.newBattleScene(setup)
 	if err != nil {
 		log.Printf("バトルシーンへの切り替えに失敗しました: %v", err)
 		return
@@ -66,6 +66,17 @@ func NewTitleScene(res *data.SharedResources, manager *SceneManager) *TitleScene
 	)
 	panel.AddChild(battleButton)
 
+	freeForAllButton := widget.NewButton(
+		widget.ButtonOpts.Image(buttonImage),
+		widget.ButtonOpts.Text("Free-for-all", res.Font, buttonTextColor),
+		widget.ButtonOpts.TextPadding(widget.NewInsetsSimple(10)),
+		widget.ButtonOpts.ClickedHandler(func(args *widget.ButtonClickedEventArgs) {
+			// マネージャ経由でシーン遷移を依頼
+			t.manager.GoToFreeForAllBattleScene()
+		}),
+	)
+	panel.AddChild(freeForAllButton)
+
 	customizeButton := widget.NewButton(
 		widget.ButtonOpts.Image(buttonImage),
 		widget.ButtonOpts.Text("Customize", res.Font, buttonTextColor),
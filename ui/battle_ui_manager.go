@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"fmt"
 	"image"
 	"log"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/ebitenui/ebitenui/widget"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/yohamta/donburi"
 	"github.com/yohamta/donburi/filter"
 	"github.com/yohamta/donburi/query"
@@ -34,6 +36,7 @@ type BattleUIManager struct {
 	infoPanelManager *InfoPanelManager
 	animationDrawer  *UIAnimationDrawer
 	actionModal      *ActionModal
+	chargeMenu       *ChargeMenu
 	messageWindow    *MessageWindow // 構造体へのポインタに変更
 
 	// Widgets
@@ -44,6 +47,11 @@ type BattleUIManager struct {
 	messageQueue        []string
 	currentMessageIndex int
 	postMessageCallback func()
+	messageTicks        int // 現在のメッセージを表示しているティック数（オートバトルの自動送りに使用）
+
+	// --- State for Auto Battle ---
+	autoBattle      bool
+	autoBattleSpeed int
 
 	// --- State from UITargetIndicatorManager ---
 	currentTarget donburi.Entity
@@ -74,6 +82,7 @@ func NewBattleUIManager(
 	bum.infoPanelManager = NewInfoPanelManager(config, bum.uiFactory, bum.battlefieldWidget)                        // battlefieldWidgetを渡す
 	bum.animationDrawer = NewUIAnimationDrawer(config, bum.uiFactory.Font, bum.eventChannel, bum.battlefieldWidget) // battlefieldWidgetを渡す
 	bum.actionModal = NewActionModal(bum.uiFactory, bum.eventChannel, bum)
+	bum.chargeMenu = NewChargeMenu(bum.uiFactory, bum.eventChannel, bum)
 	bum.messageWindow = NewMessageWindow(bum.uiFactory)
 
 	// Build UI layout
@@ -129,18 +138,15 @@ func (bum *BattleUIManager) Update(tickCount int, world donburi.World) []event.G
 	bum.animationDrawer.Update(float64(tickCount))
 
 	// --- Message Queue Logic ---
-	if len(bum.messageQueue) > 0 && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		bum.currentMessageIndex++
-		if bum.currentMessageIndex < len(bum.messageQueue) {
-			bum.showCurrentMessage()
-		} else {
-			bum.hideMessageWindow()
-			if bum.postMessageCallback != nil {
-				bum.postMessageCallback()
-				bum.postMessageCallback = nil
-			}
-			bum.messageQueue = make([]string, 0) // メッセージキューをクリア
-		}
+	// オートバトル中は、クリックしなくても一定時間でメッセージを送ります。
+	if len(bum.messageQueue) > 0 {
+		bum.messageTicks++
+	}
+	autoAdvance := bum.autoBattle && bum.messageTicks >= bum.config.AutoBattle.MessageAdvanceTicks
+	if len(bum.messageQueue) > 0 && (inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || autoAdvance) {
+		bum.advanceMessage()
+	} else if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		bum.handleInfoPanelClick(world)
 	}
 
 	// 4. Collect UI-generated events
@@ -168,6 +174,15 @@ func (bum *BattleUIManager) ProcessEvents(world donburi.World, events []event.Ga
 			bum.showActionModal(world, &vm)
 		case event.HideActionModalGameEvent, event.PlayerActionProcessedGameEvent:
 			bum.hideActionModal(world)
+		case event.ShowChargeMenuGameEvent:
+			vm, err := bum.viewModelFactory.BuildChargeMenuViewModel(world, event.ActingEntry, event.RetargetTargets)
+			if err != nil {
+				log.Printf("Error building charge menu view model: %v", err)
+				continue
+			}
+			bum.showChargeMenu(world, &vm)
+		case event.ActionCanceledGameEvent, event.ActionRetargetedGameEvent, event.ChargeMenuClosedGameEvent:
+			bum.hideChargeMenu(world)
 		// 【修正】ClearCurrentTargetGameEvent をここで処理します。
 		// これにより、UIの状態（ターゲット表示）が正しくリセットされます。
 		case event.ClearCurrentTargetGameEvent:
@@ -208,6 +223,61 @@ func (bum *BattleUIManager) hideActionModal(world donburi.World) {
 	}
 }
 
+// handleInfoPanelClick は、ゲージ進行中にプレイヤーのチャージ中の機体の情報パネルがクリックされた場合、
+// その機体の行動メニューを開くイベントを発行します。
+func (bum *BattleUIManager) handleInfoPanelClick(world donburi.World) {
+	gameStateEntry, ok := query.NewQuery(filter.Contains(component.GameStateComponent)).First(world)
+	if !ok || component.GameStateComponent.Get(gameStateEntry).CurrentState != core.StateGaugeProgress {
+		return
+	}
+
+	cursorX, cursorY := ebiten.CursorPosition()
+	entityID, ok := bum.infoPanelManager.PanelAt(cursorX, cursorY)
+	if !ok {
+		return
+	}
+	entry := world.Entry(entityID)
+	if entry == nil || !entry.Valid() || !entry.HasComponent(component.PlayerControlComponent) {
+		return
+	}
+	if component.StateComponent.Get(entry).CurrentState != core.StateCharging {
+		return
+	}
+	bum.eventChannel <- event.ChargeMenuRequestedGameEvent{ActingEntityID: entityID}
+}
+
+// showChargeMenu はチャージ中の行動のメニューを表示します。
+func (bum *BattleUIManager) showChargeMenu(world donburi.World, vm *core.ChargeMenuViewModel) {
+	uiStateEntry, ok := query.NewQuery(filter.Contains(BattleUIStateComponent)).First(world)
+	if !ok {
+		log.Println("BattleUIStateComponent が見つかりません。")
+		return
+	}
+	uiState := BattleUIStateComponent.Get(uiStateEntry)
+
+	if !uiState.IsChargeMenuVisible {
+		uiState.IsChargeMenuVisible = true
+		bum.chargeMenu.Show(vm)
+		bum.commonBottomPanel.SetContent(bum.chargeMenu.Widget())
+	}
+}
+
+// hideChargeMenu はチャージ中の行動のメニューを非表示にします。
+func (bum *BattleUIManager) hideChargeMenu(world donburi.World) {
+	uiStateEntry, ok := query.NewQuery(filter.Contains(BattleUIStateComponent)).First(world)
+	if !ok {
+		log.Println("BattleUIStateComponent が見つかりません。")
+		return
+	}
+	uiState := BattleUIStateComponent.Get(uiStateEntry)
+
+	if uiState.IsChargeMenuVisible {
+		uiState.IsChargeMenuVisible = false
+		bum.chargeMenu.Hide()
+		bum.commonBottomPanel.SetContent(nil)
+	}
+}
+
 // updateUIWithViewModels は、渡されたViewModelに基づいてUI全体を更新します。
 // このメソッドはBattleUIManagerの内部でのみ使用されます。
 func (bum *BattleUIManager) updateUIWithViewModels(infoPanelVMs []core.InfoPanelViewModel, battlefieldVM core.BattlefieldViewModel) {
@@ -265,6 +335,28 @@ func (bum *BattleUIManager) Draw(screen *ebiten.Image, tickCount int, gameDataMa
 	if bum.battlefieldWidget.viewModel != nil {
 		bum.animationDrawer.Draw(screen, float64(tickCount), *bum.battlefieldWidget.viewModel)
 	}
+
+	if bum.autoBattle {
+		bum.drawAutoBattleIndicator(screen)
+	}
+}
+
+// SetAutoBattle は、オートバトルの状態と戦闘の速度の倍率を設定します。
+func (bum *BattleUIManager) SetAutoBattle(enabled bool, speed int) {
+	bum.autoBattle = enabled
+	bum.autoBattleSpeed = speed
+}
+
+// drawAutoBattleIndicator は、オートバトル中であることと現在の速度を画面の右上に表示します。
+func (bum *BattleUIManager) drawAutoBattleIndicator(screen *ebiten.Image) {
+	label := bum.uiFactory.MessageManager.FormatMessage("auto_battle_indicator", map[string]interface{}{
+		"speed": bum.autoBattleSpeed,
+	})
+	drawOpts := &text.DrawOptions{}
+	drawOpts.GeoM.Translate(float64(screen.Bounds().Dx()-10), 10)
+	drawOpts.LayoutOptions = text.LayoutOptions{PrimaryAlign: text.AlignEnd}
+	drawOpts.ColorScale.ScaleWithColor(bum.config.UI.Colors.Yellow)
+	text.Draw(screen, label, bum.uiFactory.Font, drawOpts)
 }
 
 // --- Message Display Methods ---
@@ -276,13 +368,37 @@ func (bum *BattleUIManager) DisplayMessagesForResult(result *component.ActionRes
 	bum.EnqueueMessageQueue(messages, callback)
 }
 
+// DisplayGameEndResult は勝敗判定の理由コードに対応するメッセージを生成し、キューに追加します。
+// メッセージIDは "game_end_<reason>" の形式で messages.json に定義されています。
+func (bum *BattleUIManager) DisplayGameEndResult(result core.GameEndResult, callback func()) {
+	message := bum.uiFactory.MessageManager.FormatMessage("game_end_"+string(result.Reason), result.Params)
+	bum.EnqueueMessageQueue([]string{message}, callback)
+}
+
 func (bum *BattleUIManager) EnqueueMessageQueue(messages []string, callback func()) {
 	bum.messageQueue = messages
 	bum.currentMessageIndex = 0
+	bum.messageTicks = 0
 	bum.postMessageCallback = callback
 	bum.showCurrentMessage()
 }
 
+// advanceMessage は次のメッセージを表示します。最後のメッセージだった場合はウィンドウを閉じ、コールバックを呼び出します。
+func (bum *BattleUIManager) advanceMessage() {
+	bum.messageTicks = 0
+	bum.currentMessageIndex++
+	if bum.currentMessageIndex < len(bum.messageQueue) {
+		bum.showCurrentMessage()
+		return
+	}
+	bum.hideMessageWindow()
+	if bum.postMessageCallback != nil {
+		bum.postMessageCallback()
+		bum.postMessageCallback = nil
+	}
+	bum.messageQueue = make([]string, 0) // メッセージキューをクリア
+}
+
 func (bum *BattleUIManager) IsMessageFinished() bool {
 	return len(bum.messageQueue) == 0 && !bum.messageWindow.IsVisible()
 }
@@ -309,6 +425,21 @@ func (bum *BattleUIManager) buildActionLogMessages(result *component.ActionResul
 	messages := []string{}
 	messageManager := bum.uiFactory.MessageManager
 
+	// チャージ中のパーツが破壊されて行動を取り消した場合は、その旨だけを表示
+	if result.IsCancelled {
+		return append(messages, messageManager.FormatMessage("action_cancelled_part_broken", map[string]interface{}{
+			"attacker_name": result.AttackerName,
+			"part_name":     result.ActionName,
+		}))
+	}
+	if result.ReselectedFrom != "" {
+		messages = append(messages, messageManager.FormatMessage("action_reselected_part_broken", map[string]interface{}{
+			"attacker_name": result.AttackerName,
+			"part_name":     result.ReselectedFrom,
+			"new_part_name": result.ActionName,
+		}))
+	}
+
 	// 攻撃開始メッセージ
 	var actionInitiateMsg string
 	switch result.ActionCategory {
@@ -332,56 +463,147 @@ func (bum *BattleUIManager) buildActionLogMessages(result *component.ActionResul
 	}
 	messages = append(messages, actionInitiateMsg)
 
-	if !result.ActionDidHit {
+	if result.IsRetargeted {
+		messages = append(messages, messageManager.FormatMessage("action_retargeted", map[string]interface{}{
+			"attacker_name": result.AttackerName,
+			"target_name":   result.DefenderName,
+		}))
+	}
+
+	if result.IsOutOfRange {
+		messages = append(messages, messageManager.FormatMessage("attack_out_of_range", map[string]interface{}{
+			"target_name": result.DefenderName,
+		}))
+	} else if len(result.Hits) > 0 {
+		// 味方との連携が成立した場合は、ヒットの結果より先にコンボを知らせる
+		if result.IsCombo {
+			messages = append(messages, messageManager.FormatMessage(result.ComboMessageID, map[string]interface{}{
+				"partner_name":  result.ComboPartnerName,
+				"attacker_name": result.AttackerName,
+				"target_name":   result.DefenderName,
+			}))
+		}
+		// 連続攻撃・拡散・範囲攻撃では、パターン名に続けてヒットごとの結果を順に表示
+		if len(result.Hits) > 1 && result.AttackPattern != core.AttackPatternSingle {
+			messages = append(messages, messageManager.FormatMessage("attack_pattern_"+string(result.AttackPattern), map[string]interface{}{
+				"hit_count": len(result.Hits),
+			}))
+		}
+		for i := range result.Hits {
+			messages = append(messages, bum.buildHitMessages(&result.Hits[i])...)
+		}
+	} else if !result.ActionDidHit {
 		messages = append(messages, messageManager.FormatMessage("attack_miss", map[string]interface{}{
 			"target_name": result.DefenderName,
 		}))
-	} else {
-		// 防御メッセージ
-		if result.ActionIsDefended {
-			// クリティカルヒットが防御された場合の特別なメッセージ
-			if result.IsCritical {
-				messages = append(messages, messageManager.FormatMessage("defense_success_critical", map[string]interface{}{
-					"target_name":       result.DefenderName,
-					"defense_part_name": result.DefendingPartType,
-					"original_damage":   result.OriginalDamage,
-					"actual_damage":     result.DamageDealt,
-				}))
-			} else {
-				// 通常の防御成功メッセージ
-				messages = append(messages, messageManager.FormatMessage("action_defend", map[string]interface{}{
-					"defending_part_type": result.DefendingPartType,
-				}))
-				// 防御成功に続けて、軽減されたダメージ量を表示
-				messages = append(messages, messageManager.FormatMessage("action_damage", map[string]interface{}{
-					"defender_name":    result.DefenderName,
-					"target_part_type": result.DefendingPartType, // ダメージを受けたのは防御パーツ
-					"damage":           result.DamageDealt,
-				}))
-			}
-		} else if result.DamageDealt > 0 {
-			// 防御が発生しなかった場合の通常のダメージメッセージ
+	}
+
+	if result.IsScanApplied {
+		messages = append(messages, messageManager.FormatMessage("scan_applied", map[string]interface{}{
+			"target_name": result.DefenderName,
+		}))
+	}
+
+	if result.ActionTrait == core.TraitRepair {
+		if result.RepairedArmor > 0 {
+			messages = append(messages, messageManager.FormatMessage("repair_applied", map[string]interface{}{
+				"target_name": result.DefenderName,
+				"part_type":   result.TargetPartType,
+				"amount":      result.RepairedArmor,
+			}))
+		} else {
+			messages = append(messages, messageManager.FormatMessage("repair_no_target", nil))
+		}
+	}
+
+	// 支援・妨害などで付与したチームバフ・デバフ
+	for _, applied := range result.AppliedTeamBuffs {
+		messages = append(messages, messageManager.FormatMessage("team_buff_applied", map[string]interface{}{
+			"team_number": int(applied.Team) + 1,
+			"buff_name":   messageManager.FormatMessage("buff_name_"+string(applied.Type), nil),
+			"multiplier":  fmt.Sprintf("%.2f", applied.Multiplier),
+		}))
+	}
+
+	return messages
+}
+
+// buildHitMessages は1ヒット分の結果（回避・防御・ダメージ・パーツ破壊）のメッセージを生成します。
+func (bum *BattleUIManager) buildHitMessages(hit *component.HitRecord) []string {
+	messages := []string{}
+	messageManager := bum.uiFactory.MessageManager
+
+	if !hit.ActionDidHit {
+		return append(messages, messageManager.FormatMessage("attack_miss", map[string]interface{}{
+			"target_name": hit.DefenderName,
+		}))
+	}
+
+	if hit.IsDefenseBlocked {
+		messages = append(messages, messageManager.FormatMessage("scan_defense_blocked", map[string]interface{}{
+			"target_name": hit.DefenderName,
+		}))
+	}
+
+	// 防御メッセージ
+	if hit.ActionIsDefended {
+		// クリティカルヒットが防御された場合の特別なメッセージ
+		if hit.IsCritical {
+			messages = append(messages, messageManager.FormatMessage("defense_success_critical", map[string]interface{}{
+				"target_name":       hit.DefenderName,
+				"defense_part_name": hit.DefendingPartType,
+				"original_damage":   hit.OriginalDamage,
+				"actual_damage":     hit.DamageDealt,
+			}))
+		} else {
+			// 通常の防御成功メッセージ
+			messages = append(messages, messageManager.FormatMessage("action_defend", map[string]interface{}{
+				"defending_part_type": hit.DefendingPartType,
+			}))
+			// 防御成功に続けて、軽減されたダメージ量を表示
 			messages = append(messages, messageManager.FormatMessage("action_damage", map[string]interface{}{
-				"defender_name":    result.DefenderName,
-				"target_part_type": result.TargetPartType,
-				"damage":           result.DamageDealt,
+				"defender_name":    hit.DefenderName,
+				"target_part_type": hit.DefendingPartType, // ダメージを受けたのは防御パーツ
+				"damage":           hit.DamageDealt,
 			}))
 		}
+	} else if hit.DamageDealt > 0 {
+		// 防御が発生しなかった場合の通常のダメージメッセージ
+		messages = append(messages, messageManager.FormatMessage("action_damage", map[string]interface{}{
+			"defender_name":    hit.DefenderName,
+			"target_part_type": hit.TargetPartType,
+			"damage":           hit.DamageDealt,
+		}))
+	}
 
-		// パーツ破壊メッセージ
-		if result.IsTargetPartBroken {
-			// 防御したパーツが破壊された場合
-			if result.ActionIsDefended {
-				messages = append(messages, messageManager.FormatMessage("part_broken_on_defense", map[string]interface{}{
-					"target_name":      result.DefenderName,
-					"target_part_name": result.DefendingPartType, // 防御したパーツ名
-				}))
-			} else {
-				messages = append(messages, messageManager.FormatMessage("part_broken", map[string]interface{}{
-					"target_name":      result.DefenderName,
-					"target_part_name": result.TargetPartType, // 攻撃対象のパーツ名
-				}))
-			}
+	// パーツ破壊メッセージ
+	if hit.IsTargetPartBroken {
+		// 防御したパーツが破壊された場合
+		if hit.ActionIsDefended {
+			messages = append(messages, messageManager.FormatMessage("part_broken_on_defense", map[string]interface{}{
+				"target_name":      hit.DefenderName,
+				"target_part_name": hit.DefendingPartType, // 防御したパーツ名
+			}))
+		} else {
+			messages = append(messages, messageManager.FormatMessage("part_broken", map[string]interface{}{
+				"target_name":      hit.DefenderName,
+				"target_part_name": hit.TargetPartType, // 攻撃対象のパーツ名
+			}))
+		}
+	}
+
+	// 貫通メッセージ
+	if hit.PierceDamage > 0 {
+		messages = append(messages, messageManager.FormatMessage("pierce_damage", map[string]interface{}{
+			"defender_name":    hit.DefenderName,
+			"target_part_type": hit.PiercePartType,
+			"damage":           hit.PierceDamage,
+		}))
+		if hit.IsPiercePartBroken {
+			messages = append(messages, messageManager.FormatMessage("part_broken", map[string]interface{}{
+				"target_name":      hit.DefenderName,
+				"target_part_name": hit.PiercePartType,
+			}))
 		}
 	}
 
@@ -8,6 +8,7 @@ import (
 // It is defined in the ui package to avoid circular dependencies with ecs/component.
 type BattleUIState struct {
 	IsActionModalVisible bool
+	IsChargeMenuVisible  bool
 }
 
 // BattleUIStateComponent is the component type for the BattleUIState.
@@ -12,6 +12,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yohamta/donburi"
 )
 
 // UIAnimationDrawer はUIアニメーションの描画に特化した構造体です。
@@ -50,15 +51,33 @@ func (d *UIAnimationDrawer) SetAnimation(anim *component.ActionAnimationData) {
 	d.currentAnimation = anim
 }
 
+// アニメーションのタイミング（tick）です。
+// 行動者のピングの後、ヒットごとにターゲットのピングとダメージ表示を hitInterval ずつずらして再生します。
+const (
+	firstPingDuration  = 30.0
+	secondPingDuration = 30.0
+	popupDuration      = 60.0
+	hitInterval        = 45.0
+)
+
 // IsAnimationFinished は現在のアニメーションが完了したかどうかを返します。
 func (d *UIAnimationDrawer) IsAnimationFinished(tick float64) bool {
 	if d.currentAnimation == nil {
 		return true
 	}
-	const totalAnimationDuration = 120
+	hitCount := len(animationHits(&d.currentAnimation.Result))
+	totalAnimationDuration := firstPingDuration + float64(hitCount-1)*hitInterval + secondPingDuration + popupDuration
 	return float64(tick-float64(d.currentAnimation.StartTime)) >= totalAnimationDuration
 }
 
+// animationHits は再生するヒットの一覧を返します。ヒットの記録がない行動では、代表のターゲットへの1ヒットとして扱います。
+func animationHits(result *component.ActionResult) []component.HitRecord {
+	if len(result.Hits) > 0 {
+		return result.Hits
+	}
+	return []component.HitRecord{{TargetEntry: result.TargetEntry, OriginalDamage: result.OriginalDamage}}
+}
+
 // ClearAnimation は現在のアニメーションをクリアします。
 func (d *UIAnimationDrawer) ClearAnimation() {
 	d.currentAnimation = nil
@@ -78,82 +97,96 @@ func (d *UIAnimationDrawer) Draw(screen *ebiten.Image, tick float64, battlefield
 
 	progress := tick - float64(anim.StartTime)
 
-	var attackerVM, targetVM *core.IconViewModel
+	// BattlefieldWidgetの描画領域を取得
+	rect := d.battlefieldWidget.Container.GetWidget().Rect
+
+	var attackerVM *core.IconViewModel
 	for _, icon := range battlefieldVM.Icons {
 		if icon.EntryID == anim.Result.ActingEntry.Entity() {
 			attackerVM = icon
 		}
-		if anim.Result.TargetEntry != nil && icon.EntryID == anim.Result.TargetEntry.Entity() {
-			targetVM = icon
-		}
+	}
+	if attackerVM == nil {
+		return
 	}
 
-	if attackerVM != nil && targetVM != nil {
-		// BattlefieldWidgetの描画領域を取得
-		rect := d.battlefieldWidget.Container.GetWidget().Rect
-
+	if progress >= 0 && progress < firstPingDuration {
 		attackerX, attackerY := d.battlefieldWidget.CalculateMedarotScreenPosition(attackerVM, rect)
-		targetX, targetY := d.battlefieldWidget.CalculateMedarotScreenPosition(targetVM, rect)
-
-		const firstPingDuration = 30.0
-		const secondPingDuration = 30.0
-		const delayBetweenPings = 0.0
+		d.drawPingAnimation(screen, attackerX, attackerY, progress/firstPingDuration, true)
+	}
 
-		if progress >= 0 && progress < firstPingDuration {
-			pingProgress := progress / firstPingDuration
-			d.drawPingAnimation(screen, attackerX, attackerY, pingProgress, true)
+	// 同じ機体への複数のヒットは、ダメージ表示を上に積み重ねて重ならないようにします。
+	popupStack := make(map[*donburi.Entry]int)
+	for i, hit := range animationHits(&anim.Result) {
+		if hit.TargetEntry == nil {
+			continue
+		}
+		var targetVM *core.IconViewModel
+		for _, icon := range battlefieldVM.Icons {
+			if icon.EntryID == hit.TargetEntry.Entity() {
+				targetVM = icon
+			}
+		}
+		if targetVM == nil {
+			continue
 		}
+		targetX, targetY := d.battlefieldWidget.CalculateMedarotScreenPosition(targetVM, rect)
 
-		secondPingStart := firstPingDuration + delayBetweenPings
+		secondPingStart := firstPingDuration + float64(i)*hitInterval
 		if progress >= secondPingStart && progress < secondPingStart+secondPingDuration {
 			pingProgress := (progress - secondPingStart) / secondPingDuration
 			d.drawPingAnimation(screen, targetX, targetY, pingProgress, false)
 		}
 
-		const popupDelay = 60
-		const popupDuration = 60
-		const peakTimeRatio = 0.6
-		const peakHeight = 40.0
-		const settleHeight = 30.0
-
-		popupStartProgress := progress - popupDelay
-		var yOffset float32
-		alpha := float32(1.0)
-
-		if popupStartProgress >= 0 {
-			if popupStartProgress < popupDuration {
-				popupProgress := popupStartProgress / popupDuration
-				if popupProgress < peakTimeRatio {
-					phaseProgress := popupProgress / peakTimeRatio
-					yOffset = float32(phaseProgress * peakHeight)
-				} else {
-					phaseProgress := (popupProgress - peakTimeRatio) / (1.0 - peakTimeRatio)
-					yOffset = float32(peakHeight - (phaseProgress * (peakHeight - settleHeight)))
-				}
-			} else {
-				yOffset = settleHeight
-			}
+		stackIndex := popupStack[hit.TargetEntry]
+		popupStack[hit.TargetEntry]++
+		d.drawDamagePopup(screen, targetX, targetY-float32(stackIndex)*18, progress-(secondPingStart+secondPingDuration), hit.OriginalDamage)
+	}
+}
 
-			x := targetX
-			y := targetY - 20 - yOffset
+// drawDamagePopup は、ターゲットの上に浮かび上がるダメージ表示を描画します。popupStartProgress は表示開始からの経過 tick です。
+func (d *UIAnimationDrawer) drawDamagePopup(screen *ebiten.Image, targetX, targetY float32, popupStartProgress float64, damage int) {
+	const peakTimeRatio = 0.6
+	const peakHeight = 40.0
+	const settleHeight = 30.0
 
-			drawOpts := &text.DrawOptions{}
-			drawOpts.GeoM.Scale(1.5, 1.5)
-			drawOpts.GeoM.Translate(float64(x), float64(y))
-			drawOpts.LayoutOptions = text.LayoutOptions{
-				PrimaryAlign:   text.AlignCenter,
-				SecondaryAlign: text.AlignCenter,
-			}
-			r, g, b, a := d.config.UI.Colors.Red.RGBA()
-			cr := float32(r) / 0xffff
-			cg := float32(g) / 0xffff
-			cb := float32(b) / 0xffff
-			ca := float32(a) / 0xffff
-			drawOpts.DrawImageOptions.ColorScale.Scale(cr, cg, cb, ca)
-			drawOpts.DrawImageOptions.ColorScale.ScaleAlpha(alpha)
-			text.Draw(screen, fmt.Sprintf("-%d", anim.Result.OriginalDamage), d.font, drawOpts)
+	if popupStartProgress < 0 {
+		return
+	}
+
+	var yOffset float32
+	alpha := float32(1.0)
+	if popupStartProgress < popupDuration {
+		popupProgress := popupStartProgress / popupDuration
+		if popupProgress < peakTimeRatio {
+			phaseProgress := popupProgress / peakTimeRatio
+			yOffset = float32(phaseProgress * peakHeight)
+		} else {
+			phaseProgress := (popupProgress - peakTimeRatio) / (1.0 - peakTimeRatio)
+			yOffset = float32(peakHeight - (phaseProgress * (peakHeight - settleHeight)))
 		}
+	} else {
+		yOffset = settleHeight
+	}
+
+	x := targetX
+	y := targetY - 20 - yOffset
+
+	drawOpts := &text.DrawOptions{}
+	drawOpts.GeoM.Scale(1.5, 1.5)
+	drawOpts.GeoM.Translate(float64(x), float64(y))
+	drawOpts.LayoutOptions = text.LayoutOptions{
+		PrimaryAlign:   text.AlignCenter,
+		SecondaryAlign: text.AlignCenter,
 	}
+	r, g, b, a := d.config.UI.Colors.Red.RGBA()
+	cr := float32(r) / 0xffff
+	cg := float32(g) / 0xffff
+	cb := float32(b) / 0xffff
+	ca := float32(a) / 0xffff
+	drawOpts.DrawImageOptions.ColorScale.Scale(cr, cg, cb, ca)
+	drawOpts.DrawImageOptions.ColorScale.ScaleAlpha(alpha)
+	text.Draw(screen, fmt.Sprintf("-%d", damage), d.font, drawOpts)
 }
 
 // drawPingAnimation は、指定された中心にレーダーのようなピングアニメーションを描画します。
@@ -23,6 +23,8 @@ type BattlefieldWidget struct {
 	whitePixel   *ebiten.Image
 	viewModel    *core.BattlefieldViewModel
 	bgImage      *ebiten.Image   // 背景画像を直接保持
+	bgTint       color.Color     // ステージによる背景の色補正（nil の場合は補正なし）
+	stageID      string          // 現在の背景に対応するステージID
 	customWidget *widget.Graphic // カスタム描画ウィジェット
 }
 
@@ -64,6 +66,25 @@ func NewBattlefieldWidget(config *data.Config, resources *data.SharedResources)
 	return bf
 }
 
+// applyStageBackground はステージに応じて背景画像と色補正を切り替えます。
+// ステージ固有の背景画像がない場合は既定の背景を使用します。
+func (bf *BattlefieldWidget) applyStageBackground(stageID string) {
+	bf.stageID = stageID
+	bf.bgImage = data.GetImage(bf.resources.Loader, data.ImageBattleBackground).Data
+	bf.bgTint = nil
+
+	stage, found := bf.resources.GameDataManager.GetStageDefinition(stageID)
+	if !found {
+		return
+	}
+	if imageID, ok := bf.resources.GameDataManager.GetStageBackgroundID(stageID); ok {
+		bf.bgImage = data.GetImage(bf.resources.Loader, imageID).Data
+	}
+	if stage.BackgroundTint != "" {
+		bf.bgTint = data.ParseHexColor(stage.BackgroundTint)
+	}
+}
+
 // drawBackgroundImage は背景画像をクリッピングして描画します。
 func (bf *BattlefieldWidget) drawBackgroundImage(screen *ebiten.Image, rect image.Rectangle) {
 	if bf.bgImage == nil {
@@ -102,6 +123,9 @@ func (bf *BattlefieldWidget) drawBackgroundImage(screen *ebiten.Image, rect imag
 	m.Translate(float64(rect.Min.X), float64(rect.Min.Y))
 	op.GeoM = m
 	op.Filter = ebiten.FilterLinear // スケーリング時の品質を向上
+	if bf.bgTint != nil {
+		op.ColorScale.ScaleWithColor(bf.bgTint)
+	}
 
 	screen.DrawImage(bf.bgImage.SubImage(srcRect).(*ebiten.Image), op)
 }
@@ -109,6 +133,9 @@ func (bf *BattlefieldWidget) drawBackgroundImage(screen *ebiten.Image, rect imag
 // SetViewModel はViewModelを設定し、描画更新をトリガーします
 func (bf *BattlefieldWidget) SetViewModel(vm core.BattlefieldViewModel) {
 	bf.viewModel = &vm
+	if vm.StageID != bf.stageID {
+		bf.applyStageBackground(vm.StageID)
+	}
 	// カスタム描画ウィジェットの再描画をトリガー
 	if bf.customWidget != nil {
 		bf.customWidget.GetWidget().Disabled = false // 強制的に再描画をトリガー
@@ -153,28 +180,28 @@ func (bf *BattlefieldWidget) drawBattlefieldLines(screen *ebiten.Image, rect ima
 		bf.config.UI.Battlefield.LineWidth,
 		bf.config.UI.Colors.Gray, false)
 
-	// チーム位置の計算
-	team1HomeX := offsetX + width*bf.config.UI.Battlefield.Team1HomeX
-	team2HomeX := offsetX + width*bf.config.UI.Battlefield.Team2HomeX
 	team1ExecX := offsetX + width*bf.config.UI.Battlefield.Team1ExecutionLineX
 	team2ExecX := offsetX + width*bf.config.UI.Battlefield.Team2ExecutionLineX
 
 	// ホームマーカー
-	// game_settings.json の UI.Battlefield.MedarotVerticalSpacingFactor を使用してY座標を計算します。
-	for i := 0; i < core.PlayersPerTeam; i++ {
-		yPos := offsetY + (height/float32(core.PlayersPerTeam+1))*(float32(i)+1)
-
-		// チーム1のホームマーカー
-		vector.StrokeCircle(screen, team1HomeX, yPos,
-			bf.config.UI.Battlefield.HomeMarkerRadius,
-			bf.config.UI.Battlefield.LineWidth,
-			bf.config.UI.Colors.Gray, true)
-
-		// チーム2のホームマーカー
-		vector.StrokeCircle(screen, team2HomeX, yPos,
-			bf.config.UI.Battlefield.HomeMarkerRadius,
-			bf.config.UI.Battlefield.LineWidth,
-			bf.config.UI.Colors.Gray, true)
+	// 各機体のホーム位置はワールド側で決定されたものをそのまま描画します。
+	if bf.viewModel != nil {
+		for _, iconVM := range bf.viewModel.Icons {
+			homeX, homeY := bf.CalculateHomeScreenPosition(iconVM, rect)
+			vector.StrokeCircle(screen, homeX, homeY,
+				bf.config.UI.Battlefield.HomeMarkerRadius,
+				bf.config.UI.Battlefield.LineWidth,
+				bf.config.UI.Colors.TeamColor(iconVM.Team), true)
+		}
+
+		// 3チーム以上の場合はレーンの境界線を描画
+		laneCount := calculateLaneCount(len(bf.viewModel.TeamSizes))
+		for i := 1; i < laneCount; i++ {
+			laneY := offsetY + height/float32(laneCount)*float32(i)
+			vector.StrokeLine(screen, offsetX, laneY, offsetX+width, laneY,
+				bf.config.UI.Battlefield.LineWidth,
+				bf.config.UI.Colors.Gray, true)
+		}
 	}
 
 	// 実行ライン
@@ -200,7 +227,10 @@ func (bf *BattlefieldWidget) drawIcons(screen *ebiten.Image, rect image.Rectangl
 // drawSingleIcon は単一のアイコンを描画します
 func (bf *BattlefieldWidget) drawSingleIcon(screen *ebiten.Image, iconVM *core.IconViewModel, rect image.Rectangle) {
 	centerX, centerY := bf.CalculateMedarotScreenPosition(iconVM, rect)
-	iconColor := iconVM.Color
+	iconColor := bf.config.UI.Colors.TeamColor(iconVM.Team)
+	if iconVM.State == core.StateBroken {
+		iconColor = bf.config.UI.Colors.Red
+	}
 	radius := bf.config.UI.Battlefield.IconRadius
 
 	// メインアイコン（塗りつぶし円）
@@ -343,34 +373,49 @@ func (bf *BattlefieldWidget) DrawBackground(screen *ebiten.Image) {
 }
 
 // CalculateMedarotScreenPosition はメダロットアイコンの画面上のX, Y座標を計算します。
+// 位置はワールド側で管理されているバトルフィールド上の座標を画面に投影したものです。
 func (bf *BattlefieldWidget) CalculateMedarotScreenPosition(iconVM *core.IconViewModel, rect image.Rectangle) (float32, float32) {
-	width := float32(rect.Dx())
-	height := float32(rect.Dy())
-	offsetX := float32(rect.Min.X)
-	offsetY := float32(rect.Min.Y)
+	return bf.fieldToScreen(iconVM.Position, rect)
+}
 
-	// Y座標はDrawIndexに基づいて計算
-	yPos := (height/float32(core.PlayersPerTeam+1))*(float32(iconVM.DrawIndex)+1) + offsetY
+// CalculateHomeScreenPosition はメダロットのホーム位置の画面上のX, Y座標を計算します。
+func (bf *BattlefieldWidget) CalculateHomeScreenPosition(iconVM *core.IconViewModel, rect image.Rectangle) (float32, float32) {
+	return bf.fieldToScreen(iconVM.Home, rect)
+}
 
-	// X座標はNormalizedProgressとチームに基づいて計算
-	homeX, execX := width*bf.config.UI.Battlefield.Team1HomeX, width*bf.config.UI.Battlefield.Team1ExecutionLineX
-	if iconVM.Team == core.Team2 {
-		homeX, execX = width*bf.config.UI.Battlefield.Team2HomeX, width*bf.config.UI.Battlefield.Team2ExecutionLineX
+// fieldToScreen はバトルフィールド上の座標（フィールド単位）を画面座標に変換します。
+func (bf *BattlefieldWidget) fieldToScreen(pos core.Vector2, rect image.Rectangle) (float32, float32) {
+	fieldWidth := bf.config.Spatial.FieldWidth
+	fieldHeight := bf.config.Spatial.FieldHeight
+	if fieldWidth <= 0 || fieldHeight <= 0 {
+		return float32(rect.Min.X), float32(rect.Min.Y)
 	}
+	x := float32(rect.Min.X) + float32(rect.Dx())*float32(pos.X/fieldWidth)
+	y := float32(rect.Min.Y) + float32(rect.Dy())*float32(pos.Y/fieldHeight)
+	return x, y
+}
 
-	var xPos float32
-	switch iconVM.State {
-	case core.StateCharging:
-		xPos = homeX + (execX-homeX)*float32(iconVM.NormalizedProgress)
-	case core.StateReady:
-		xPos = execX
-	case core.StateCooldown:
-		xPos = execX + (homeX-execX)*(1.0-float32(iconVM.NormalizedProgress))
-	case core.StateIdle, core.StateBroken:
-		xPos = homeX
-	default:
-		xPos = homeX
+// calculateRosterYOffset は、機体数 teamSize のチームで index 番目の機体を配置するY方向のオフセットを返します。
+// バトルフィールドの高さを (teamSize+1) 等分した位置に並べます。
+func calculateRosterYOffset(height float32, index, teamSize int) float32 {
+	if teamSize < 1 {
+		teamSize = 1
+	}
+	return (height / float32(teamSize+1)) * (float32(index) + 1)
+}
+
+// calculateLaneCount は参加チーム数から縦方向のレーン数を求めます。1レーンには左右1チームずつが入ります。
+func calculateLaneCount(teamCount int) int {
+	laneCount := (teamCount + 1) / 2
+	if laneCount < 1 {
+		laneCount = 1
 	}
+	return laneCount
+}
 
-	return xPos + offsetX, yPos
-}
\ No newline at end of file
+// calculateTeamLane は、チームの通し番号からホームの左右とレーンの縦位置・高さを決定します。
+// 偶数番目のチームは左、奇数番目のチームは右に配置し、3チーム以上では上下にレーンを分けます。
+func calculateTeamLane(teamIndex, teamCount int, height float32) (isRightSide bool, laneTop, laneHeight float32) {
+	laneHeight = height / float32(calculateLaneCount(teamCount))
+	return teamIndex%2 == 1, laneHeight * float32(teamIndex/2), laneHeight
+}
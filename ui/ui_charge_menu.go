@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"fmt"
+
+	"medarot-ebiten/core"
+	"medarot-ebiten/event"
+
+	"github.com/ebitenui/ebitenui/widget"
+)
+
+// ChargeMenu はチャージ中の行動をキャンセル・ターゲット変更するためのメニューUIを管理するコンポーネントです。
+type ChargeMenu struct {
+	widget        widget.PreferredSizeLocateableWidget
+	uiFactory     *UIFactory
+	eventChannel  chan event.GameEvent
+	targetManager TargetManager
+}
+
+// NewChargeMenu は新しいChargeMenuのインスタンスを作成します。
+func NewChargeMenu(
+	uiFactory *UIFactory,
+	eventChannel chan event.GameEvent,
+	targetManager TargetManager,
+) *ChargeMenu {
+	return &ChargeMenu{
+		widget:        widget.NewContainer(widget.ContainerOpts.Layout(widget.NewAnchorLayout())),
+		uiFactory:     uiFactory,
+		eventChannel:  eventChannel,
+		targetManager: targetManager,
+	}
+}
+
+// Widget はこのコンポーネントのルートウィジェットを返します。
+func (m *ChargeMenu) Widget() widget.PreferredSizeLocateableWidget {
+	return m.widget
+}
+
+// Show はViewModelに基づいてメニューの内容を構築します。
+func (m *ChargeMenu) Show(vm *core.ChargeMenuViewModel) {
+	m.widget = m.createUI(vm)
+}
+
+// Hide はメニューの内容をクリアします。
+func (m *ChargeMenu) Hide() {
+	m.widget = widget.NewContainer()
+}
+
+// createUI はViewModelから実際のUIウィジェットを構築します。
+func (m *ChargeMenu) createUI(vm *core.ChargeMenuViewModel) widget.PreferredSizeLocateableWidget {
+	c := m.uiFactory.Config
+	messageManager := m.uiFactory.MessageManager
+	buttonTextColor := &widget.ButtonTextColor{
+		Idle:  c.UI.Colors.White,
+		Hover: c.UI.Colors.Black,
+	}
+	actingEntityID := vm.ActingEntityID
+	noHover := func(args *widget.ButtonHoverEventArgs) {}
+
+	outerContainer := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewAnchorLayout()),
+	)
+	contentContainer := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionVertical),
+			widget.RowLayoutOpts.Spacing(c.UI.ActionModal.ButtonSpacing),
+			widget.RowLayoutOpts.Padding(widget.NewInsetsSimple(15)),
+		)),
+		widget.ContainerOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.AnchorLayoutData{
+			HorizontalPosition: widget.AnchorLayoutPositionCenter,
+			VerticalPosition:   widget.AnchorLayoutPositionCenter,
+		})),
+	)
+	outerContainer.AddChild(contentContainer)
+
+	title := widget.NewText(
+		widget.TextOpts.Text(messageManager.FormatMessage("ui_charge_menu_title", map[string]interface{}{
+			"MedarotName": vm.ActingMedarotName,
+			"PartName":    vm.PartName,
+		}), m.uiFactory.Font, c.UI.Colors.White),
+	)
+	contentContainer.AddChild(title)
+
+	commandRow := widget.NewContainer(
+		widget.ContainerOpts.Layout(widget.NewRowLayout(
+			widget.RowLayoutOpts.Direction(widget.DirectionHorizontal),
+			widget.RowLayoutOpts.Spacing(c.UI.ActionModal.ButtonSpacing),
+		)),
+	)
+	contentContainer.AddChild(commandRow)
+
+	cancelText := messageManager.FormatMessage("ui_charge_menu_cancel", map[string]interface{}{
+		"Cost": fmt.Sprintf("%.0f", c.ChargeControl.CancelCooldownRatio*100),
+	})
+	commandRow.AddChild(m.uiFactory.NewCyberpunkButton(cancelText, buttonTextColor,
+		func(args *widget.ButtonClickedEventArgs) {
+			m.eventChannel <- event.ActionCanceledGameEvent{ActingEntityID: actingEntityID}
+		}, noHover, noHover))
+
+	closeText := messageManager.FormatMessage("ui_charge_menu_close", nil)
+	commandRow.AddChild(m.uiFactory.NewCyberpunkButton(closeText, buttonTextColor,
+		func(args *widget.ButtonClickedEventArgs) {
+			m.eventChannel <- event.ChargeMenuClosedGameEvent{}
+		}, noHover, noHover))
+
+	// ターゲット変更の候補（射撃行動のみ）
+	if len(vm.Targets) > 0 {
+		retargetLabel := widget.NewText(
+			widget.TextOpts.Text(messageManager.FormatMessage("ui_charge_menu_retarget", map[string]interface{}{
+				"Cost": fmt.Sprintf("%.0f", c.ChargeControl.RetargetProgressLoss*100),
+			}), m.uiFactory.Font, c.UI.Colors.White),
+		)
+		contentContainer.AddChild(retargetLabel)
+
+		targetRow := widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewRowLayout(
+				widget.RowLayoutOpts.Direction(widget.DirectionHorizontal),
+				widget.RowLayoutOpts.Spacing(c.UI.ActionModal.ButtonSpacing),
+			)),
+		)
+		contentContainer.AddChild(targetRow)
+
+		for _, targetVM := range vm.Targets {
+			capturedTarget := targetVM
+			targetRow.AddChild(m.uiFactory.NewCyberpunkButton(capturedTarget.Name, buttonTextColor,
+				func(args *widget.ButtonClickedEventArgs) {
+					m.eventChannel <- event.ActionRetargetedGameEvent{
+						ActingEntityID: actingEntityID,
+						TargetEntityID: capturedTarget.TargetEntityID,
+						TargetPartSlot: capturedTarget.TargetPartSlot,
+					}
+					m.eventChannel <- event.ClearCurrentTargetGameEvent{}
+				},
+				func(args *widget.ButtonHoverEventArgs) {
+					m.targetManager.SetCurrentTarget(capturedTarget.TargetEntityID)
+				},
+				func(args *widget.ButtonHoverEventArgs) {
+					m.targetManager.ClearCurrentTarget()
+				}))
+		}
+	}
+
+	return outerContainer
+}
@@ -59,10 +59,10 @@ func (ipm *InfoPanelManager) UpdatePanels(infoPanelVMs []core.InfoPanelViewModel
 	}
 	ipm.panels = make(map[donburi.Entity]*infoPanelUI) // マップのキーをdonburi.Entityに変更
 
-	// アイコンのEntryIDをキーとしたY座標のマップを作成
+	// アイコンのEntryIDをキーとしたY座標のマップを作成（移動で揺れないようにホーム位置を基準にします）
 	iconYMap := make(map[donburi.Entity]float32)
 	for _, iconVM := range iconVMs {
-		_, y := ipm.battlefieldWidget.CalculateMedarotScreenPosition(iconVM, battlefieldRect)
+		_, y := ipm.battlefieldWidget.CalculateHomeScreenPosition(iconVM, battlefieldRect)
 		iconYMap[iconVM.EntryID] = y
 	}
 
@@ -76,7 +76,13 @@ func (ipm *InfoPanelManager) UpdatePanels(infoPanelVMs []core.InfoPanelViewModel
 	})
 
 	for _, vm := range infoPanelVMs {
-		panelUI := createSingleMedarotInfoPanel(ipm.config, ipm.uiFactory, vm)
+		panelUI := createSingleMedarotInfoPanel(ipm.config, ipm.uiFactory, vm, false)
+		// チームの機体数から1機あたりの縦の間隔を求め、収まらない場合はコンパクト表示に切り替える
+		_, _, laneHeight := calculateTeamLane(vm.TeamIndex, vm.TeamCount, float32(battlefieldRect.Dy()))
+		rosterSpacing := calculateRosterYOffset(laneHeight, 0, vm.TeamSize)
+		if _, h := panelUI.rootPanel.RootContainer.PreferredSize(); float32(h) > rosterSpacing {
+			panelUI = createSingleMedarotInfoPanel(ipm.config, ipm.uiFactory, vm, true)
+		}
 		ipm.panels[vm.EntityID] = panelUI // EntityIDをキーとして使用
 
 		// アイコンのY座標を取得し、情報パネルのY座標として使用
@@ -100,7 +106,7 @@ func (ipm *InfoPanelManager) UpdatePanels(infoPanelVMs []core.InfoPanelViewModel
 		// PreferredSizeを使用して、レンダリング前に正しいサイズを取得
 		panelWidth, panelHeight := panelUI.rootPanel.RootContainer.PreferredSize()
 
-		if vm.Team == core.Team1 {
+		if vm.TeamIndex%2 == 0 { // レーンの左側に配置されるチーム
 			panelX = int(bfOffsetX - float32(panelWidth) - float32(ipm.config.UI.InfoPanel.Padding)) // バトルフィールドの左側に配置
 		} else {
 			panelX = int(bfOffsetX + bfWidth + float32(ipm.config.UI.InfoPanel.Padding)) // バトルフィールドの右側に配置
@@ -122,8 +128,25 @@ func (ipm *InfoPanelManager) UpdatePanels(infoPanelVMs []core.InfoPanelViewModel
 	}
 }
 
-func createSingleMedarotInfoPanel(config *data.Config, uiFactory *UIFactory, vm core.InfoPanelViewModel) *infoPanelUI {
+// PanelAt は画面座標 (x, y) にある情報パネルの機体を返します。
+func (ipm *InfoPanelManager) PanelAt(x, y int) (donburi.Entity, bool) {
+	point := image.Pt(x, y)
+	for entityID, panel := range ipm.panels {
+		if point.In(panel.rootPanel.RootContainer.GetWidget().Rect) {
+			return entityID, true
+		}
+	}
+	return 0, false
+}
+
+// createSingleMedarotInfoPanel は1機分の情報パネルを生成します。
+// compact が true の場合、パーツを2列に並べてHPの数値表示を省略し、パネルの高さを抑えます。
+func createSingleMedarotInfoPanel(config *data.Config, uiFactory *UIFactory, vm core.InfoPanelViewModel, compact bool) *infoPanelUI {
 	c := config.UI
+	hpGaugeWidth := int(c.InfoPanel.PartHPGaugeWidth)
+	if compact {
+		hpGaugeWidth /= 2
+	}
 
 	// ヘッダー部分を作成
 	headerContainer := widget.NewContainer(
@@ -181,7 +204,7 @@ func createSingleMedarotInfoPanel(config *data.Config, uiFactory *UIFactory, vm
 				widget.WidgetOpts.LayoutData(widget.RowLayoutData{
 					Stretch: true, // 横方向にストレッチ
 				}),
-				widget.WidgetOpts.MinSize(int(c.InfoPanel.PartHPGaugeWidth), int(c.InfoPanel.PartHPGaugeHeight)), // 最小高さを設定
+				widget.WidgetOpts.MinSize(hpGaugeWidth, int(c.InfoPanel.PartHPGaugeHeight)), // 最小高さを設定
 			),
 			widget.ProgressBarOpts.Images(
 				&widget.ProgressBarImage{
@@ -196,14 +219,17 @@ func createSingleMedarotInfoPanel(config *data.Config, uiFactory *UIFactory, vm
 		)
 		partRowContainer.AddChild(hpBar)
 
-		// HPテキスト
-		hpText := widget.NewText(
-			widget.TextOpts.Text("0/0", uiFactory.Font, c.Colors.White),
-			widget.TextOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{
-				Stretch: false,
-			})),
-		)
-		partRowContainer.AddChild(hpText)
+		// HPテキスト（コンパクト表示では省略）
+		var hpText *widget.Text
+		if !compact {
+			hpText = widget.NewText(
+				widget.TextOpts.Text("0/0", uiFactory.Font, c.Colors.White),
+				widget.TextOpts.WidgetOpts(widget.WidgetOpts.LayoutData(widget.RowLayoutData{
+					Stretch: false,
+				})),
+			)
+			partRowContainer.AddChild(hpText)
+		}
 
 		partSlots[slotKey] = &infoPanelPartUI{
 			partNameText: partTypeText, // partTypeTextを再利用して部位名を表示
@@ -214,13 +240,27 @@ func createSingleMedarotInfoPanel(config *data.Config, uiFactory *UIFactory, vm
 		}
 	}
 
+	if compact {
+		// パーツ行を2列のグリッドにまとめる
+		partGrid := widget.NewContainer(
+			widget.ContainerOpts.Layout(widget.NewGridLayout(
+				widget.GridLayoutOpts.Columns(2),
+				widget.GridLayoutOpts.Spacing(5, 2),
+			)),
+		)
+		for _, partWidget := range partWidgets {
+			partGrid.AddChild(partWidget)
+		}
+		partWidgets = []widget.PreferredSizeLocateableWidget{partGrid}
+	}
+
 	// NewPanel を使用して全体のパネルを作成
 	panel := NewPanel(&PanelOptions{ // panelContainer を panel に変更
 		PanelWidth:      int(c.InfoPanel.BlockWidth),
 		Padding:         widget.NewInsetsSimple(5),
 		Spacing:         2,
 		BackgroundColor: color.NRGBA{50, 50, 70, 200}, // 背景色を設定
-		BorderColor:     c.Colors.Gray,                // 枠線の色
+		BorderColor:     c.Colors.TeamColor(vm.Team),  // 枠線の色（チーム色）
 		BorderThickness: 5,                            // 枠線の太さ
 	}, uiFactory.imageGenerator, uiFactory.Font, append([]widget.PreferredSizeLocateableWidget{headerContainer}, partWidgets...)...)
 
@@ -240,6 +280,14 @@ func updateSingleInfoPanel(ui *infoPanelUI, vm core.InfoPanelViewModel, config *
 	c := config.UI
 
 	ui.stateText.Label = vm.StateStr
+	ui.stateText.Color = c.Colors.Yellow
+	if vm.IsScanned {
+		// スキャン済みの敵は、チャージ中のパーツを状態の横に表示します。
+		if vm.ChargingPartName != "" {
+			ui.stateText.Label = fmt.Sprintf("%s:%s", vm.StateStr, vm.ChargingPartName)
+		}
+		ui.stateText.Color = c.Colors.Blue
+	}
 
 	if vm.IsLeader {
 		ui.nameText.Color = c.Colors.Leader
@@ -251,13 +299,34 @@ func updateSingleInfoPanel(ui *infoPanelUI, vm core.InfoPanelViewModel, config *
 		partVM, ok := vm.Parts[slotKey]
 		if !ok {
 			partUI.partNameText.Label = "---"
-			partUI.hpText.Label = "0/0"
+			if partUI.hpText != nil {
+				partUI.hpText.Label = "0/0"
+			}
 			partUI.hpBar.SetCurrent(0)
 			partUI.displayedHP = 0
 			partUI.targetHP = 0
 			continue
 		}
 
+		// 情報制限モードでは、スキャンするまで装甲値を伏せ、破壊されたかどうかだけを表示します。
+		if vm.ArmorHidden {
+			textColor := c.Colors.White
+			partUI.hpBar.SetCurrent(100)
+			if partVM.IsBroken {
+				textColor = c.Colors.Broken
+				partUI.hpBar.SetCurrent(0)
+			}
+			partUI.partNameText.Label = string(partVM.PartType)
+			partUI.partNameText.Color = textColor
+			if partUI.hpText != nil {
+				partUI.hpText.Label = "?? / ??"
+				partUI.hpText.Color = textColor
+			}
+			partUI.displayedHP = float64(partVM.CurrentArmor)
+			partUI.targetHP = partUI.displayedHP
+			continue
+		}
+
 		// 目標HPを設定
 		partUI.targetHP = float64(partVM.CurrentArmor)
 
@@ -294,8 +363,10 @@ func updateSingleInfoPanel(ui *infoPanelUI, vm core.InfoPanelViewModel, config *
 			}
 		}
 
-		partUI.hpText.Label = fmt.Sprintf("%d / %d", displayedArmor, maxArmor)
-		partUI.hpText.Color = textColor
+		if partUI.hpText != nil {
+			partUI.hpText.Label = fmt.Sprintf("%d / %d", displayedArmor, maxArmor)
+			partUI.hpText.Color = textColor
+		}
 		partUI.partNameText.Color = textColor
 		partUI.hpBar.SetCurrent(int(hpPercentage * 100))
 	}
@@ -2,8 +2,8 @@ package ui
 
 import (
 	"fmt"
-	"image/color"
 	"math/rand"
+	"sort"
 
 	"medarot-ebiten/core"
 	"medarot-ebiten/data"
@@ -23,14 +23,16 @@ type ViewModelPartInfoProvider interface {
 
 // ViewModelFactory はViewModelの生成に特化します。
 type ViewModelFactory struct {
+	config           *data.Config
 	partInfoProvider ViewModelPartInfoProvider
 	gameDataManager  *data.GameDataManager
 	rand             *rand.Rand
 }
 
 // NewViewModelFactory は新しいViewModelFactoryのインスタンスを作成します。
-func NewViewModelFactory(partInfoProvider ViewModelPartInfoProvider, gameDataManager *data.GameDataManager, rand *rand.Rand) *ViewModelFactory {
+func NewViewModelFactory(config *data.Config, partInfoProvider ViewModelPartInfoProvider, gameDataManager *data.GameDataManager, rand *rand.Rand) *ViewModelFactory {
 	return &ViewModelFactory{
+		config:           config,
 		partInfoProvider: partInfoProvider,
 		gameDataManager:  gameDataManager,
 		rand:             rand,
@@ -66,6 +68,21 @@ func (f *ViewModelFactory) BuildInfoPanelViewModel(entry *donburi.Entry) (core.I
 	}
 
 	stateStr := GetStateDisplayName(state.CurrentState)
+	teamSizes := countTeamSizes(entry.World)
+	teamIndices := buildTeamIndices(teamSizes)
+
+	// スキャン済みの敵は、チャージ中のパーツと装甲値をプレイヤーに明かします。
+	playerTeam := findPlayerTeam(entry.World)
+	isEnemy := playerTeam != core.TeamNone && settings.Team != playerTeam
+	isScanned := isEnemy && entry.HasComponent(component.ScanMarkComponent) && component.ScanMarkComponent.Get(entry).ScannedBy == playerTeam
+	chargingPartName := ""
+	if isScanned && state.CurrentState == core.StateCharging && partsComp != nil {
+		if partInst := partsComp.Map[component.ActionIntentComponent.Get(entry).SelectedPartKey]; partInst != nil {
+			if partDef, found := f.gameDataManager.GetPartDefinition(partInst.DefinitionID); found {
+				chargingPartName = partDef.PartName
+			}
+		}
+	}
 
 	return core.InfoPanelViewModel{
 		ID:        settings.Name,
@@ -73,40 +90,46 @@ func (f *ViewModelFactory) BuildInfoPanelViewModel(entry *donburi.Entry) (core.I
 		Name:      settings.Name,
 		Team:      settings.Team,
 		DrawIndex: settings.DrawIndex,
+		TeamSize:  teamSizes[settings.Team],
+		TeamIndex: teamIndices[settings.Team],
+		TeamCount: len(teamIndices),
 		StateStr:  stateStr,
 		IsLeader:  settings.IsLeader,
 		Parts:     partViewModels,
+
+		IsScanned:        isScanned,
+		ChargingPartName: chargingPartName,
+		ArmorHidden:      f.config.Scan.FogOfInformation && isEnemy && !isScanned,
 	}, nil
 }
 
 // BuildBattlefieldViewModel は、ワールドの状態からBattlefieldViewModelを構築します。
 func (f *ViewModelFactory) BuildBattlefieldViewModel(world donburi.World) (core.BattlefieldViewModel, error) {
 	vm := core.BattlefieldViewModel{
-		Icons: []*core.IconViewModel{},
+		Icons:     []*core.IconViewModel{},
+		TeamSizes: countTeamSizes(world),
 		DebugMode: func() bool {
 			_, ok := query.NewQuery(filter.Contains(component.DebugModeComponent)).First(world)
 			return ok
 		}(),
 	}
 
+	if stageEntry, ok := query.NewQuery(filter.Contains(component.StageComponent)).First(world); ok {
+		vm.StageID = component.StageComponent.Get(stageEntry).ID
+	}
+
+	// レーン配置に使うチームの通し番号。アイコンの色はチームと状態からUIコンポーネント側で決定します。
+	teamIndices := buildTeamIndices(vm.TeamSizes)
+
 	query.NewQuery(filter.Contains(component.SettingsComponent)).Each(world, func(entry *donburi.Entry) {
 		settings := component.SettingsComponent.Get(entry)
 		state := component.StateComponent.Get(entry)
 		gauge := component.GaugeComponent.Get(entry)
 		progress := f.partInfoProvider.GetNormalizedActionProgress(entry)
 
-		var iconColor color.Color
-		// 色の決定はUIコンポーネントで行うため、ここでは仮の色を設定
-		// または、ViewModelに色情報を含めないように変更
-		// 今回はViewModelに色情報を含めるため、configから色を取得するロジックを削除
-		// configはBuildBattlefieldViewModelの引数から削除されたため、ここではアクセスできない
-		// 一旦、デフォルトの色を設定
-		if state.CurrentState == core.StateBroken {
-			iconColor = color.RGBA{255, 0, 0, 255} // 赤
-		} else if settings.Team == core.Team1 {
-			iconColor = color.RGBA{0, 0, 255, 255} // 青
-		} else {
-			iconColor = color.RGBA{0, 255, 0, 255} // 緑
+		var position core.Position
+		if entry.HasComponent(component.PositionComponent) {
+			position = *component.PositionComponent.Get(entry)
 		}
 
 		var debugText string
@@ -114,16 +137,22 @@ func (f *ViewModelFactory) BuildBattlefieldViewModel(world donburi.World) (core.
 			stateStr := GetStateDisplayName(state.CurrentState)
 			debugText = fmt.Sprintf(`State: %s
 Gauge: %.1f
-Prog: %.1f / %.1f`,
-				stateStr, gauge.CurrentGauge, gauge.TotalDuration, gauge.ProgressCounter)
+Prog: %.1f / %.1f
+Pos: (%.1f, %.1f) %s`,
+				stateStr, gauge.CurrentGauge, gauge.TotalDuration, gauge.ProgressCounter,
+				position.Current.X, position.Current.Y, position.LegType)
 		}
 
 		vm.Icons = append(vm.Icons, &core.IconViewModel{
 			EntryID:            entry.Entity(),
 			Team:               settings.Team,
 			DrawIndex:          settings.DrawIndex,
+			TeamSize:           vm.TeamSizes[settings.Team],
+			TeamIndex:          teamIndices[settings.Team],
+			TeamCount:          len(teamIndices),
 			NormalizedProgress: float64(progress),
-			Color:              iconColor, // 仮の色
+			Position:           position.Current,
+			Home:               position.Home,
 			IsLeader:           settings.IsLeader,
 			State:              state.CurrentState,
 			GaugeProgress:      gauge.CurrentGauge / 100.0,
@@ -174,11 +203,79 @@ func (f *ViewModelFactory) BuildActionModalViewModel(actingEntry *donburi.Entry,
 	}, nil
 }
 
+// BuildChargeMenuViewModel は、チャージ中の行動のキャンセル・ターゲット変更メニューに必要なViewModelを構築します。
+func (f *ViewModelFactory) BuildChargeMenuViewModel(world donburi.World, actingEntry *donburi.Entry, retargetTargets []core.ActionTarget) (core.ChargeMenuViewModel, error) {
+	partsComp := component.PartsComponent.Get(actingEntry)
+	if partsComp == nil {
+		return core.ChargeMenuViewModel{}, fmt.Errorf("actingEntry に PartsComponent がありません。")
+	}
+
+	partName := ""
+	intent := component.ActionIntentComponent.Get(actingEntry)
+	if partInst, ok := partsComp.Map[intent.SelectedPartKey]; ok && partInst != nil {
+		if partDef, found := f.gameDataManager.GetPartDefinition(partInst.DefinitionID); found {
+			partName = partDef.PartName
+		}
+	}
+
+	targets := make([]core.ChargeMenuTargetViewModel, 0, len(retargetTargets))
+	for _, target := range retargetTargets {
+		targetEntry := world.Entry(target.TargetEntityID)
+		if targetEntry == nil || !targetEntry.Valid() {
+			continue
+		}
+		targets = append(targets, core.ChargeMenuTargetViewModel{
+			Name:           component.SettingsComponent.Get(targetEntry).Name,
+			TargetEntityID: target.TargetEntityID,
+			TargetPartSlot: target.Slot,
+		})
+	}
+
+	return core.ChargeMenuViewModel{
+		ActingMedarotName: component.SettingsComponent.Get(actingEntry).Name,
+		ActingEntityID:    actingEntry.Entity(),
+		PartName:          partName,
+		Targets:           targets,
+	}, nil
+}
+
 // GetAvailableAttackParts は、指定されたエンティティが利用可能な攻撃パーツのリストを返します。
 func (f *ViewModelFactory) GetAvailableAttackParts(entry *donburi.Entry) []core.AvailablePart {
 	return f.partInfoProvider.GetAvailableAttackParts(entry)
 }
 
+// findPlayerTeam はプレイヤーが操作する機体のチームを返します。プレイヤーの機体がいない場合は TeamNone を返します。
+func findPlayerTeam(world donburi.World) core.TeamID {
+	if entry, ok := query.NewQuery(filter.Contains(component.PlayerControlComponent, component.SettingsComponent)).First(world); ok {
+		return component.SettingsComponent.Get(entry).Team
+	}
+	return core.TeamNone
+}
+
+// countTeamSizes は、ワールド内の機体数をチームごとに数えます。
+// 機能停止した機体も配置枠を占めるため数に含めます。
+func countTeamSizes(world donburi.World) map[core.TeamID]int {
+	teamSizes := make(map[core.TeamID]int)
+	query.NewQuery(filter.Contains(component.SettingsComponent)).Each(world, func(entry *donburi.Entry) {
+		teamSizes[component.SettingsComponent.Get(entry).Team]++
+	})
+	return teamSizes
+}
+
+// buildTeamIndices は、参加しているチームにチームID順の通し番号を割り当てます。
+func buildTeamIndices(teamSizes map[core.TeamID]int) map[core.TeamID]int {
+	teams := make([]core.TeamID, 0, len(teamSizes))
+	for team := range teamSizes {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool { return teams[i] < teams[j] })
+	indices := make(map[core.TeamID]int, len(teams))
+	for i, team := range teams {
+		indices[team] = i
+	}
+	return indices
+}
+
 // GetStateDisplayName は StateType に対応する日本語の表示名を返します。
 func GetStateDisplayName(state core.StateType) string {
 	switch state {